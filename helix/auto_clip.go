@@ -0,0 +1,554 @@
+package helix
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Default AutoClipper settings
+const (
+	defaultAutoClipWindow         = 30 * time.Second
+	defaultAutoClipChatRate       = 60
+	defaultAutoClipEmoteSpam      = 20
+	defaultAutoClipRaidViewers    = 10
+	defaultAutoClipCheerBits      = 1000
+	defaultAutoClipHypeTrainLevel = 2
+	defaultAutoClipCooldown       = time.Minute
+	defaultAutoClipReasonCooldown = 5 * time.Minute
+	defaultAutoClipPollInterval   = 5 * time.Second
+	defaultAutoClipPollTimeout    = time.Minute
+)
+
+// ErrAutoClipCooldown is returned by AutoClipper.Trigger while a cooldown is
+// running.
+var ErrAutoClipCooldown = errors.New("auto clip cooldown active")
+
+// AutoClipReason is why an AutoClipper created a clip.
+type AutoClipReason string
+
+// AutoClipper trigger reasons
+const (
+	AutoClipReasonChatRate  AutoClipReason = "chat_rate"  // Chat messages in the window at or above the threshold
+	AutoClipReasonEmoteSpam AutoClipReason = "emote_spam" // Messages using one emote in the window at or above the threshold
+	AutoClipReasonRaid      AutoClipReason = "raid"       // Incoming raid at or above the viewer threshold
+	AutoClipReasonHypeTrain AutoClipReason = "hype_train" // Hype train reached a new level at or above the threshold
+	AutoClipReasonCheer     AutoClipReason = "cheer"      // Single cheer at or above the bits threshold
+	AutoClipReasonManual    AutoClipReason = "manual"     // Trigger was called
+)
+
+// AutoClip is a clip created by an AutoClipper.
+type AutoClip struct {
+	Reason      AutoClipReason
+	Detail      string // What tripped the trigger, e.g. "75 messages in 30s"
+	TriggeredAt time.Time
+	EditURL     string
+	Clip        *Clip // The clip once Get Clips returns it
+}
+
+// AutoClipper creates clips when a channel gets hyped: a burst of chat
+// messages, the same emote spammed by many messages, a large raid or cheer,
+// or a hype train leveling up. Clips are created with CreateClip, then
+// Get Clips is polled until the clip is available; the clip is then passed to
+// the clip handler along with the trigger reason and optionally posted to
+// chat.
+//
+// A global cooldown separates any two clips, and a longer per-reason cooldown
+// stops one kind of trigger from clipping repeatedly.
+//
+// Events come from an EventSubWebSocket via Start, or can be fed directly
+// with HandleEvent, HandleChatMessage, HandleUserNotice, HandleRaid,
+// HandleCheer and HandleHypeTrain. Chat messages carry cheers, so feed cheers
+// from either chat or channel.cheer, not both.
+type AutoClipper struct {
+	client        *Client
+	broadcasterID string
+	userID        string
+
+	// Configuration
+	window         time.Duration
+	chatRate       int
+	emoteSpam      int
+	raidViewers    int
+	cheerBits      int
+	hypeTrainLevel int
+	cooldown       time.Duration
+	reasonCooldown time.Duration
+	hasDelay       bool
+	pollInterval   time.Duration
+	pollTimeout    time.Duration
+	chatFormat     func(*AutoClip) string
+	onClip         func(*AutoClip)
+	onError        func(error)
+	now            func() time.Time
+
+	mu          sync.Mutex
+	messages    []autoClipMessage
+	lastClip    time.Time
+	lastReason  map[AutoClipReason]time.Time
+	trainID     string
+	trainLevel  int
+	wg          sync.WaitGroup
+	pollCtx     context.Context
+	cancelPolls context.CancelFunc
+}
+
+// autoClipMessage is a chat message counted in the sliding window.
+type autoClipMessage struct {
+	at     time.Time
+	emotes []string // Distinct emote IDs in the message
+}
+
+// AutoClipperOption configures an AutoClipper.
+type AutoClipperOption func(*AutoClipper)
+
+// WithAutoClipWindow sets the sliding window for chat rate and emote spam (default 30s).
+func WithAutoClipWindow(d time.Duration) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.window = d
+	}
+}
+
+// WithAutoClipChatRate sets how many chat messages in the window trigger a
+// clip (default 60, 0 disables).
+func WithAutoClipChatRate(messages int) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.chatRate = messages
+	}
+}
+
+// WithAutoClipEmoteSpam sets how many messages using the same emote in the
+// window trigger a clip (default 20, 0 disables).
+func WithAutoClipEmoteSpam(messages int) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.emoteSpam = messages
+	}
+}
+
+// WithAutoClipRaidViewers sets the raid size that triggers a clip (default 10, 0 disables).
+func WithAutoClipRaidViewers(viewers int) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.raidViewers = viewers
+	}
+}
+
+// WithAutoClipCheerBits sets the cheer size that triggers a clip (default 1000, 0 disables).
+func WithAutoClipCheerBits(bits int) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.cheerBits = bits
+	}
+}
+
+// WithAutoClipHypeTrainLevel sets the lowest hype train level whose start
+// triggers a clip (default 2, 0 disables). Each later level clips again.
+func WithAutoClipHypeTrainLevel(level int) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.hypeTrainLevel = level
+	}
+}
+
+// WithAutoClipCooldown sets the minimum time between any two clips (default 1m)
+// and between two clips for the same reason (default 5m).
+func WithAutoClipCooldown(global, perReason time.Duration) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.cooldown = global
+		a.reasonCooldown = perReason
+	}
+}
+
+// WithAutoClipDelay sets whether clips are created with HasDelay, which
+// accounts for the stream delay viewers see (default true).
+func WithAutoClipDelay(hasDelay bool) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.hasDelay = hasDelay
+	}
+}
+
+// WithAutoClipPolling sets how often and for how long Get Clips is polled for
+// a new clip (default every 5s for 1m).
+func WithAutoClipPolling(interval, timeout time.Duration) AutoClipperOption {
+	return func(a *AutoClipper) {
+		if interval > 0 {
+			a.pollInterval = interval
+		}
+		if timeout > 0 {
+			a.pollTimeout = timeout
+		}
+	}
+}
+
+// WithAutoClipChatPost posts each clip to chat as the clipper's user, which
+// needs the user:write:chat scope. A nil format posts "Clipped: <url>".
+func WithAutoClipChatPost(format func(*AutoClip) string) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.chatFormat = format
+		if a.chatFormat == nil {
+			a.chatFormat = func(c *AutoClip) string { return "Clipped: " + c.Clip.URL }
+		}
+	}
+}
+
+// WithAutoClipHandler sets a function called with each clip once it is available.
+func WithAutoClipHandler(fn func(*AutoClip)) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.onClip = fn
+	}
+}
+
+// WithAutoClipErrorHandler sets a function called with errors from events
+// received via Start and from waiting for clips.
+func WithAutoClipErrorHandler(fn func(error)) AutoClipperOption {
+	return func(a *AutoClipper) {
+		a.onError = fn
+	}
+}
+
+// NewAutoClipper creates an AutoClipper for a channel. userID is the token's
+// user, who creates the clips (clips:edit scope), reads chat via Start, and
+// posts clips to chat.
+func NewAutoClipper(client *Client, broadcasterID, userID string, opts ...AutoClipperOption) *AutoClipper {
+	ctx, cancel := context.WithCancel(context.Background())
+	a := &AutoClipper{
+		client:         client,
+		broadcasterID:  broadcasterID,
+		userID:         userID,
+		window:         defaultAutoClipWindow,
+		chatRate:       defaultAutoClipChatRate,
+		emoteSpam:      defaultAutoClipEmoteSpam,
+		raidViewers:    defaultAutoClipRaidViewers,
+		cheerBits:      defaultAutoClipCheerBits,
+		hypeTrainLevel: defaultAutoClipHypeTrainLevel,
+		cooldown:       defaultAutoClipCooldown,
+		reasonCooldown: defaultAutoClipReasonCooldown,
+		hasDelay:       true,
+		pollInterval:   defaultAutoClipPollInterval,
+		pollTimeout:    defaultAutoClipPollTimeout,
+		now:            time.Now,
+		lastReason:     make(map[AutoClipReason]time.Time),
+		pollCtx:        ctx,
+		cancelPolls:    cancel,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Start subscribes to channel.chat.message, channel.raid and, when the hype
+// train trigger is enabled, channel.hype_train.progress on a connected
+// EventSubWebSocket. The chat subscription needs the user:read:chat scope;
+// hype train events need the broadcaster's channel:read:hype_train scope.
+func (a *AutoClipper) Start(ctx context.Context, ws *EventSubWebSocket) error {
+	conditions := map[string]map[string]string{
+		EventSubTypeChannelChatMessage: BroadcasterUserCondition(a.broadcasterID, a.userID),
+		EventSubTypeChannelRaid:        FromToBroadcasterCondition("", a.broadcasterID),
+	}
+	if a.hypeTrainLevel > 0 {
+		conditions[EventSubTypeChannelHypeTrainProgress] = BroadcasterCondition(a.broadcasterID)
+	}
+	for _, eventType := range slices.Sorted(maps.Keys(conditions)) {
+		if err := ws.Subscribe(ctx, eventType, GetEventSubVersion(eventType), conditions[eventType], a.eventHandler(eventType)); err != nil {
+			return fmt.Errorf("subscribing to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// eventHandler returns the notification handler for an event type.
+func (a *AutoClipper) eventHandler(eventType string) func(json.RawMessage) {
+	return func(data json.RawMessage) {
+		if err := a.HandleEvent(context.Background(), eventType, data); err != nil {
+			a.handleError(err)
+		}
+	}
+}
+
+// HandleEvent processes a raw channel.chat.message, channel.raid,
+// channel.cheer, channel.hype_train.begin or channel.hype_train.progress
+// event. Other event types are ignored.
+func (a *AutoClipper) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	switch eventType {
+	case EventSubTypeChannelChatMessage:
+		event, err := ParseWSEvent[ChannelChatMessageEvent](data)
+		if err != nil {
+			return err
+		}
+		return a.HandleChatMessage(ctx, chatMessageFromEvent(event, string(data), a.now()))
+	case EventSubTypeChannelRaid:
+		event, err := ParseWSEvent[ChannelRaidEvent](data)
+		if err != nil {
+			return err
+		}
+		return a.HandleRaid(ctx, event)
+	case EventSubTypeChannelCheer:
+		event, err := ParseWSEvent[ChannelCheerEvent](data)
+		if err != nil {
+			return err
+		}
+		return a.HandleCheer(ctx, event)
+	case EventSubTypeChannelHypeTrainBegin, EventSubTypeChannelHypeTrainProgress:
+		event, err := ParseWSEvent[ChannelHypeTrainProgressEvent](data)
+		if err != nil {
+			return err
+		}
+		return a.HandleHypeTrain(ctx, event)
+	}
+	return nil
+}
+
+// HandleChatMessage processes a chat message from an IRC or EventSub chat
+// client, counting it toward the chat rate and emote spam triggers and
+// checking it for a large cheer. Messages from other channels are ignored.
+func (a *AutoClipper) HandleChatMessage(ctx context.Context, msg *ChatMessage) error {
+	if msg.RoomID != "" && msg.RoomID != a.broadcasterID {
+		return nil
+	}
+	if a.cheerBits > 0 && msg.Bits >= a.cheerBits {
+		if err := a.ignoreCooldown(a.trigger(ctx, AutoClipReasonCheer, fmt.Sprintf("%d bits from %s", msg.Bits, msg.User))); err != nil {
+			return err
+		}
+	}
+
+	now := a.now()
+	m := autoClipMessage{at: now}
+	for _, e := range msg.Emotes {
+		id := cmp.Or(e.ID, e.Name)
+		if !slices.Contains(m.emotes, id) {
+			m.emotes = append(m.emotes, id)
+		}
+	}
+
+	a.mu.Lock()
+	a.messages = append(a.messages, m)
+	cutoff := now.Add(-a.window)
+	i := 0
+	for i < len(a.messages) && !a.messages[i].at.After(cutoff) {
+		i++
+	}
+	a.messages = a.messages[i:]
+	count := len(a.messages)
+	emote, emoteCount := "", 0
+	if a.emoteSpam > 0 {
+		counts := make(map[string]int)
+		for _, m := range a.messages {
+			for _, id := range m.emotes {
+				counts[id]++
+			}
+		}
+		for _, id := range m.emotes { // Only the emotes of this message can have crossed the threshold
+			if counts[id] > emoteCount {
+				emote, emoteCount = id, counts[id]
+			}
+		}
+	}
+	a.mu.Unlock()
+
+	var err error
+	switch {
+	case a.emoteSpam > 0 && emoteCount >= a.emoteSpam:
+		err = a.trigger(ctx, AutoClipReasonEmoteSpam, fmt.Sprintf("emote %s in %d messages in %s", emote, emoteCount, a.window))
+	case a.chatRate > 0 && count >= a.chatRate:
+		err = a.trigger(ctx, AutoClipReasonChatRate, fmt.Sprintf("%d messages in %s", count, a.window))
+	}
+	return a.ignoreCooldown(err)
+}
+
+// HandleUserNotice processes an IRC USERNOTICE, triggering on raids. The
+// notice is assumed to be for the clipper's channel.
+func (a *AutoClipper) HandleUserNotice(ctx context.Context, n *UserNotice) error {
+	raid, ok := n.Data.(*RaidNotice)
+	if !ok {
+		return nil
+	}
+	return a.HandleRaid(ctx, &ChannelRaidEvent{
+		FromBroadcasterUserID:    n.UserID,
+		FromBroadcasterUserLogin: raid.Login,
+		FromBroadcasterUserName:  raid.DisplayName,
+		ToBroadcasterUserID:      a.broadcasterID,
+		Viewers:                  raid.ViewerCount,
+	})
+}
+
+// HandleRaid processes an incoming raid. Raids to other channels are ignored.
+func (a *AutoClipper) HandleRaid(ctx context.Context, event *ChannelRaidEvent) error {
+	if event.ToBroadcasterUserID != "" && event.ToBroadcasterUserID != a.broadcasterID {
+		return nil
+	}
+	if a.raidViewers <= 0 || event.Viewers < a.raidViewers {
+		return nil
+	}
+	return a.ignoreCooldown(a.trigger(ctx, AutoClipReasonRaid, fmt.Sprintf("raid from %s with %d viewers", event.FromBroadcasterUserLogin, event.Viewers)))
+}
+
+// HandleCheer processes a channel.cheer event.
+func (a *AutoClipper) HandleCheer(ctx context.Context, event *ChannelCheerEvent) error {
+	if event.BroadcasterUserID != "" && event.BroadcasterUserID != a.broadcasterID {
+		return nil
+	}
+	if a.cheerBits <= 0 || event.Bits < a.cheerBits {
+		return nil
+	}
+	from := event.UserLogin
+	if event.IsAnonymous {
+		from = "anonymous"
+	}
+	return a.ignoreCooldown(a.trigger(ctx, AutoClipReasonCheer, fmt.Sprintf("%d bits from %s", event.Bits, from)))
+}
+
+// HandleHypeTrain processes a hype train begin or progress event, triggering
+// when the train reaches a new level at or above the threshold.
+func (a *AutoClipper) HandleHypeTrain(ctx context.Context, event *ChannelHypeTrainProgressEvent) error {
+	if event.BroadcasterUserID != "" && event.BroadcasterUserID != a.broadcasterID {
+		return nil
+	}
+	a.mu.Lock()
+	if event.ID != a.trainID {
+		a.trainID, a.trainLevel = event.ID, 0
+	}
+	levelUp := event.Level > a.trainLevel
+	a.trainLevel = max(a.trainLevel, event.Level)
+	a.mu.Unlock()
+
+	if a.hypeTrainLevel <= 0 || !levelUp || event.Level < a.hypeTrainLevel {
+		return nil
+	}
+	return a.ignoreCooldown(a.trigger(ctx, AutoClipReasonHypeTrain, fmt.Sprintf("hype train level %d", event.Level)))
+}
+
+// Trigger creates a clip now, subject to the global cooldown. It returns
+// ErrAutoClipCooldown if the cooldown is running.
+func (a *AutoClipper) Trigger(ctx context.Context, detail string) error {
+	return a.trigger(ctx, AutoClipReasonManual, detail)
+}
+
+// Close stops waiting for pending clips and returns once the waits have
+// ended. Clips that weren't available yet are not reported.
+func (a *AutoClipper) Close() {
+	a.mu.Lock()
+	a.cancelPolls()
+	a.mu.Unlock()
+	a.wg.Wait()
+}
+
+// trigger creates a clip unless a cooldown is running, then waits for it in
+// the background.
+func (a *AutoClipper) trigger(ctx context.Context, reason AutoClipReason, detail string) error {
+	now := a.now()
+	a.mu.Lock()
+	if a.pollCtx.Err() != nil {
+		a.mu.Unlock()
+		return errors.New("auto clipper closed")
+	}
+	if !a.lastClip.IsZero() && now.Sub(a.lastClip) < a.cooldown {
+		a.mu.Unlock()
+		return ErrAutoClipCooldown
+	}
+	if last, ok := a.lastReason[reason]; ok && reason != AutoClipReasonManual && now.Sub(last) < a.reasonCooldown {
+		a.mu.Unlock()
+		return ErrAutoClipCooldown
+	}
+	// Reserve the cooldowns now, so concurrent triggers don't clip twice
+	prevClip := a.lastClip
+	prevReason, hadReason := a.lastReason[reason]
+	a.lastClip = now
+	a.lastReason[reason] = now
+	a.wg.Add(1)
+	a.mu.Unlock()
+
+	created, err := a.client.CreateClip(ctx, &CreateClipParams{BroadcasterID: a.broadcasterID, HasDelay: a.hasDelay})
+	if err == nil && created == nil {
+		err = errors.New("no clip returned")
+	}
+	if err != nil {
+		// No clip was made (offline, rate limited), so give the reservation back
+		a.mu.Lock()
+		if a.lastClip.Equal(now) {
+			a.lastClip = prevClip
+		}
+		if last, ok := a.lastReason[reason]; ok && last.Equal(now) {
+			if hadReason {
+				a.lastReason[reason] = prevReason
+			} else {
+				delete(a.lastReason, reason)
+			}
+		}
+		a.mu.Unlock()
+		a.wg.Done()
+		return fmt.Errorf("creating clip (%s): %w", reason, err)
+	}
+
+	clip := &AutoClip{Reason: reason, Detail: detail, TriggeredAt: now, EditURL: created.EditURL}
+	go func() {
+		defer a.wg.Done()
+		if err := a.await(clip, created.ID); err != nil {
+			a.handleError(err)
+		}
+	}()
+	return nil
+}
+
+// await polls Get Clips until the clip exists, then reports it.
+func (a *AutoClipper) await(clip *AutoClip, id string) error {
+	ctx, cancel := context.WithTimeout(a.pollCtx, a.pollTimeout)
+	defer cancel()
+	ticker := time.NewTicker(a.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if a.pollCtx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("clip %s not available after %s", id, a.pollTimeout)
+		case <-ticker.C:
+		}
+		resp, err := a.client.GetClips(ctx, &GetClipsParams{IDs: []string{id}})
+		if err != nil {
+			if ctx.Err() == nil {
+				a.handleError(fmt.Errorf("getting clip %s: %w", id, err))
+			}
+			continue
+		}
+		if len(resp.Data) > 0 {
+			clip.Clip = &resp.Data[0]
+			break
+		}
+	}
+
+	var err error
+	if a.chatFormat != nil {
+		_, err = a.client.SendChatMessage(a.pollCtx, &SendChatMessageParams{
+			BroadcasterID: a.broadcasterID,
+			SenderID:      a.userID,
+			Message:       a.chatFormat(clip),
+		})
+		if err != nil {
+			err = fmt.Errorf("posting clip %s to chat: %w", id, err)
+		}
+	}
+	if a.onClip != nil {
+		a.onClip(clip)
+	}
+	return err
+}
+
+// ignoreCooldown drops ErrAutoClipCooldown, which isn't an error for event
+// triggers.
+func (a *AutoClipper) ignoreCooldown(err error) error {
+	if errors.Is(err, ErrAutoClipCooldown) {
+		return nil
+	}
+	return err
+}
+
+func (a *AutoClipper) handleError(err error) {
+	if a.onError != nil {
+		a.onError(err)
+	}
+}
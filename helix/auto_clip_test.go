@@ -0,0 +1,193 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAutoClipper(t *testing.T) {
+	var mu sync.Mutex
+	var created, posted []string
+	polls := make(map[string]int)
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/clips" && r.Method == http.MethodPost:
+			if q.Get("broadcaster_id") != "1234" || q.Get("has_delay") != "true" {
+				t.Errorf("create clip query = %v", q)
+			}
+			id := fmt.Sprintf("Clip%d", len(created)+1)
+			created = append(created, id)
+			_ = json.NewEncoder(w).Encode(Response[CreateClipResponse]{Data: []CreateClipResponse{{ID: id, EditURL: "https://clips.twitch.tv/" + id + "/edit"}}})
+		case r.URL.Path == "/clips":
+			// Clips show up on the second poll
+			id := q.Get("id")
+			polls[id]++
+			var data []Clip
+			if polls[id] > 1 {
+				data = []Clip{{ID: id, URL: "https://clips.twitch.tv/" + id}}
+			}
+			_ = json.NewEncoder(w).Encode(Response[Clip]{Data: data})
+		case r.URL.Path == "/chat/messages":
+			var params SendChatMessageParams
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &params)
+			posted = append(posted, params.SenderID+": "+params.Message)
+			_ = json.NewEncoder(w).Encode(Response[SendChatMessageResponse]{Data: []SendChatMessageResponse{{IsSent: true}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	clips := make(chan *AutoClip, 10)
+	clipper := NewAutoClipper(client, "1234", "5678",
+		WithAutoClipChatRate(5),
+		WithAutoClipEmoteSpam(3),
+		WithAutoClipPolling(time.Millisecond, time.Second),
+		WithAutoClipChatPost(nil),
+		WithAutoClipHandler(func(c *AutoClip) { clips <- c }),
+		WithAutoClipErrorHandler(func(err error) { t.Error(err) }),
+	)
+	defer clipper.Close()
+	now := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+	clipper.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	next := func(reason AutoClipReason) *AutoClip {
+		t.Helper()
+		select {
+		case c := <-clips:
+			if c.Reason != reason || c.Clip == nil {
+				t.Errorf("clip = %+v, want reason %s", c, reason)
+			}
+			return c
+		case <-time.After(2 * time.Second):
+			t.Fatalf("no %s clip", reason)
+			return nil
+		}
+	}
+
+	// Three messages with the same emote, one from another channel
+	kappa := []IRCEmote{{ID: "25", Name: "Kappa", Count: 2}}
+	for i, room := range []string{"1234", "999", "1234", "1234"} {
+		if err := clipper.HandleChatMessage(ctx, &ChatMessage{RoomID: room, User: fmt.Sprintf("u%d", i), Emotes: kappa}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := next(AutoClipReasonEmoteSpam)
+	if c.Detail != "emote 25 in 3 messages in 30s" || c.EditURL != "https://clips.twitch.tv/Clip1/edit" {
+		t.Errorf("clip = %+v", c)
+	}
+
+	// The chat rate threshold is reached during the global cooldown
+	for range 2 {
+		if err := clipper.HandleChatMessage(ctx, &ChatMessage{RoomID: "1234"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(2 * time.Minute)
+	raid := &UserNotice{Type: UserNoticeTypeRaid, UserID: "42", Data: &RaidNotice{Login: "raider", ViewerCount: 50}}
+	if err := clipper.HandleUserNotice(ctx, raid); err != nil {
+		t.Fatal(err)
+	}
+	if c := next(AutoClipReasonRaid); c.Detail != "raid from raider with 50 viewers" {
+		t.Errorf("raid clip = %+v", c)
+	}
+
+	now = now.Add(2 * time.Minute)
+	for _, level := range []int{1, 2, 2} {
+		data := fmt.Sprintf(`{"id":"train1","broadcaster_user_id":"1234","level":%d}`, level)
+		if err := clipper.HandleEvent(ctx, EventSubTypeChannelHypeTrainProgress, json.RawMessage(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next(AutoClipReasonHypeTrain)
+
+	// Raids are still within their per-reason cooldown
+	now = now.Add(2 * time.Minute)
+	if err := clipper.HandleUserNotice(ctx, raid); err != nil {
+		t.Fatal(err)
+	}
+	if err := clipper.HandleCheer(ctx, &ChannelCheerEvent{EventSubBroadcaster: EventSubBroadcaster{BroadcasterUserID: "1234"}, Bits: 500}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clipper.Trigger(ctx, "button"); err != nil {
+		t.Fatal(err)
+	}
+	next(AutoClipReasonManual)
+	if err := clipper.Trigger(ctx, "button"); err != ErrAutoClipCooldown {
+		t.Errorf("Trigger() during cooldown = %v", err)
+	}
+
+	clipper.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(created, ",") != "Clip1,Clip2,Clip3,Clip4" {
+		t.Errorf("created = %v", created)
+	}
+	if len(posted) != 4 || posted[0] != "5678: Clipped: https://clips.twitch.tv/Clip1" {
+		t.Errorf("posted = %v", posted)
+	}
+}
+
+func TestAutoClipper_FailedCreateKeepsCooldownFree(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/clips" && r.Method == http.MethodPost:
+			// The first attempt fails as if the channel were offline
+			if attempts++; attempts == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Response[CreateClipResponse]{Data: []CreateClipResponse{{ID: "Clip1"}}})
+		case r.URL.Path == "/clips":
+			_ = json.NewEncoder(w).Encode(Response[Clip]{Data: []Clip{{ID: r.URL.Query().Get("id")}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	clips := make(chan *AutoClip, 1)
+	clipper := NewAutoClipper(client, "1234", "5678",
+		WithAutoClipPolling(time.Millisecond, time.Second),
+		WithAutoClipHandler(func(c *AutoClip) { clips <- c }),
+		WithAutoClipErrorHandler(func(err error) { t.Error(err) }),
+	)
+	defer clipper.Close()
+	now := time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)
+	clipper.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	raid := &UserNotice{Type: UserNoticeTypeRaid, UserID: "42", Data: &RaidNotice{Login: "raider", ViewerCount: 50}}
+	if err := clipper.HandleUserNotice(ctx, raid); err == nil {
+		t.Fatal("expected create clip error")
+	}
+	// Neither the global nor the raid cooldown started
+	if err := clipper.HandleUserNotice(ctx, raid); err != nil {
+		t.Fatalf("HandleUserNotice() after failure = %v", err)
+	}
+	select {
+	case c := <-clips:
+		if c.Reason != AutoClipReasonRaid {
+			t.Errorf("clip = %+v", c)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no clip after retry")
+	}
+}
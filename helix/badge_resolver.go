@@ -0,0 +1,309 @@
+package helix
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ResolvedBadge is a chat badge with its display metadata.
+type ResolvedBadge struct {
+	SetID       string // Badge set, e.g. "subscriber"
+	Version     string // Badge version that was displayed by the chatter
+	Info        string // Extra badge info (e.g. exact subscriber months)
+	Months      int    // Subscriber/founder months from Info, 0 if unknown
+	Title       string
+	Description string
+	ImageURL1x  string
+	ImageURL2x  string
+	ImageURL4x  string
+	ClickAction string
+	ClickURL    string
+	Channel     bool // true if resolved from the channel's custom badges
+}
+
+// badgeSets maps set ID -> version ID -> version.
+type badgeSets map[string]map[string]BadgeVersion
+
+// BadgeResolver maps the set/version pairs carried by chat messages to
+// badge titles, descriptions and images.
+//
+// Global and per-channel badge sets are fetched on first use and cached.
+// Channel badges (custom subscriber and bits badges) take precedence, with
+// the global set as fallback. A BadgeResolver is safe for concurrent use.
+type BadgeResolver struct {
+	client  *Client
+	ttl     time.Duration
+	global  renderCacheEntry[badgeSets]
+	channel map[string]renderCacheEntry[badgeSets]
+	mu      sync.RWMutex
+}
+
+// BadgeResolverOption configures a BadgeResolver.
+type BadgeResolverOption func(*BadgeResolver)
+
+// WithBadgeCacheTTL sets how long fetched badge sets are reused (default 1 hour).
+// A TTL of 0 keeps them until Refresh is called.
+func WithBadgeCacheTTL(ttl time.Duration) BadgeResolverOption {
+	return func(r *BadgeResolver) {
+		r.ttl = ttl
+	}
+}
+
+// NewBadgeResolver creates a new badge resolver.
+func NewBadgeResolver(client *Client, opts ...BadgeResolverOption) *BadgeResolver {
+	r := &BadgeResolver{
+		client:  client,
+		ttl:     time.Hour,
+		channel: make(map[string]renderCacheEntry[badgeSets]),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve returns the badge for a set and version in a channel, or nil if
+// neither the channel nor the global badges contain it. info is the badge's
+// extra info (the IRC badge-info value or ChatEventBadge.Info).
+//
+// Subscriber and bits badges fall back to the highest version the chatter
+// qualifies for when the exact version has no image, using the months in
+// info for subscriber badges.
+func (r *BadgeResolver) Resolve(ctx context.Context, broadcasterID, setID, version, info string) (*ResolvedBadge, error) {
+	global, err := r.globalSets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var channel badgeSets
+	if broadcasterID != "" {
+		if channel, err = r.channelSets(ctx, broadcasterID); err != nil {
+			return nil, err
+		}
+	}
+
+	months := 0
+	if setID == BadgeSubscriber || setID == BadgeFounder {
+		months, _ = strconv.Atoi(info)
+	}
+
+	fromChannel := true
+	v, ok := lookupBadgeVersion(channel[setID], setID, version, months)
+	if !ok {
+		fromChannel = false
+		if v, ok = lookupBadgeVersion(global[setID], setID, version, months); !ok {
+			return nil, nil
+		}
+	}
+
+	return &ResolvedBadge{
+		SetID:       setID,
+		Version:     version,
+		Info:        info,
+		Months:      months,
+		Title:       v.Title,
+		Description: v.Description,
+		ImageURL1x:  v.ImageURL1x,
+		ImageURL2x:  v.ImageURL2x,
+		ImageURL4x:  v.ImageURL4x,
+		ClickAction: v.ClickAction,
+		ClickURL:    v.ClickURL,
+		Channel:     fromChannel,
+	}, nil
+}
+
+// ResolveMessage resolves the badges on an IRC chat message, in the order
+// the chatter displays them. Unknown badges are skipped.
+func (r *BadgeResolver) ResolveMessage(ctx context.Context, msg *ChatMessage) ([]ResolvedBadge, error) {
+	if msg == nil {
+		return nil, nil
+	}
+
+	var resolved []ResolvedBadge
+	for _, setID := range ircBadgeOrder(msg) {
+		b, err := r.Resolve(ctx, msg.RoomID, setID, msg.Badges[setID], msg.BadgeInfo[setID])
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			resolved = append(resolved, *b)
+		}
+	}
+	return resolved, nil
+}
+
+// ResolveEventBadges resolves EventSub chat badges for a broadcaster.
+// Unknown badges are skipped.
+func (r *BadgeResolver) ResolveEventBadges(ctx context.Context, broadcasterID string, badges []ChatEventBadge) ([]ResolvedBadge, error) {
+	var resolved []ResolvedBadge
+	for _, badge := range badges {
+		b, err := r.Resolve(ctx, broadcasterID, badge.SetID, badge.ID, badge.Info)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			resolved = append(resolved, *b)
+		}
+	}
+	return resolved, nil
+}
+
+// Refresh refetches the global badges and, if broadcasterID is not empty,
+// the channel's badges.
+func (r *BadgeResolver) Refresh(ctx context.Context, broadcasterID string) error {
+	if err := r.fetchGlobal(ctx); err != nil {
+		return err
+	}
+	if broadcasterID == "" {
+		return nil
+	}
+	_, err := r.fetchChannel(ctx, broadcasterID)
+	return err
+}
+
+// Invalidate drops the cached badges for a channel, or every cached badge
+// set when broadcasterID is empty.
+func (r *BadgeResolver) Invalidate(broadcasterID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if broadcasterID == "" {
+		r.global = renderCacheEntry[badgeSets]{}
+		r.channel = make(map[string]renderCacheEntry[badgeSets])
+		return
+	}
+	delete(r.channel, broadcasterID)
+}
+
+func (r *BadgeResolver) fresh(fetchedAt time.Time) bool {
+	return !fetchedAt.IsZero() && (r.ttl <= 0 || time.Since(fetchedAt) < r.ttl)
+}
+
+func (r *BadgeResolver) globalSets(ctx context.Context) (badgeSets, error) {
+	r.mu.RLock()
+	entry := r.global
+	r.mu.RUnlock()
+	if r.fresh(entry.fetchedAt) {
+		return entry.value, nil
+	}
+	if err := r.fetchGlobal(ctx); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.global.value, nil
+}
+
+func (r *BadgeResolver) channelSets(ctx context.Context, broadcasterID string) (badgeSets, error) {
+	r.mu.RLock()
+	entry, ok := r.channel[broadcasterID]
+	r.mu.RUnlock()
+	if ok && r.fresh(entry.fetchedAt) {
+		return entry.value, nil
+	}
+	return r.fetchChannel(ctx, broadcasterID)
+}
+
+func (r *BadgeResolver) fetchGlobal(ctx context.Context) error {
+	resp, err := r.client.GetGlobalChatBadges(ctx)
+	if err != nil {
+		return err
+	}
+	sets := indexBadgeSets(resp.Data)
+
+	r.mu.Lock()
+	r.global = renderCacheEntry[badgeSets]{value: sets, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *BadgeResolver) fetchChannel(ctx context.Context, broadcasterID string) (badgeSets, error) {
+	resp, err := r.client.GetChannelChatBadges(ctx, broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	sets := indexBadgeSets(resp.Data)
+
+	r.mu.Lock()
+	r.channel[broadcasterID] = renderCacheEntry[badgeSets]{value: sets, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return sets, nil
+}
+
+func indexBadgeSets(badges []ChatBadge) badgeSets {
+	sets := make(badgeSets, len(badges))
+	for _, b := range badges {
+		versions := make(map[string]BadgeVersion, len(b.Versions))
+		for _, v := range b.Versions {
+			versions[v.ID] = v
+		}
+		sets[b.SetID] = versions
+	}
+	return sets
+}
+
+// lookupBadgeVersion finds a badge version, falling back to the highest
+// numeric version the chatter qualifies for on subscriber and bits badges.
+//
+// Subscriber versions encode the tier in the thousands (e.g. 2012 is a
+// 12-month Tier 2 badge), so the fallback stays within the same tier.
+func lookupBadgeVersion(versions map[string]BadgeVersion, setID, version string, months int) (BadgeVersion, bool) {
+	if v, ok := versions[version]; ok {
+		return v, true
+	}
+
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return BadgeVersion{}, false
+	}
+
+	tiered := false
+	limit := n
+	switch setID {
+	case BadgeSubscriber, BadgeFounder:
+		tiered = true
+		limit = max(n%1000, months)
+	case BadgeBits, BadgeSubGifter:
+	default:
+		return BadgeVersion{}, false
+	}
+
+	best, found := -1, BadgeVersion{}
+	for id, v := range versions {
+		value, err := strconv.Atoi(id)
+		if err != nil {
+			continue
+		}
+		if tiered {
+			if value/1000 != n/1000 {
+				continue
+			}
+			value %= 1000
+		}
+		if value <= limit && value > best {
+			best, found = value, v
+		}
+	}
+	return found, best >= 0
+}
+
+// ircBadgeOrder returns the message's badge set IDs in display order. Messages
+// built without BadgeOrder, or whose badges were changed after parsing, fall
+// back to sorted set IDs.
+func ircBadgeOrder(msg *ChatMessage) []string {
+	unknown := func(setID string) bool { _, ok := msg.Badges[setID]; return !ok }
+	if len(msg.BadgeOrder) == len(msg.Badges) && !slices.ContainsFunc(msg.BadgeOrder, unknown) {
+		return msg.BadgeOrder
+	}
+
+	order := make([]string, 0, len(msg.Badges))
+	for setID := range msg.Badges {
+		order = append(order, setID)
+	}
+	sort.Strings(order)
+	return order
+}
@@ -0,0 +1,189 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func badgeFixtureHandler(globalCalls, channelCalls *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var data []ChatBadge
+		switch r.URL.Path {
+		case "/chat/badges/global":
+			atomic.AddInt32(globalCalls, 1)
+			data = []ChatBadge{
+				{SetID: "moderator", Versions: []BadgeVersion{{ID: "1", Title: "Moderator", ImageURL1x: "global-mod-1x"}}},
+				{SetID: "subscriber", Versions: []BadgeVersion{{ID: "0", Title: "Subscriber", ImageURL1x: "global-sub-1x"}}},
+				{SetID: "bits", Versions: []BadgeVersion{
+					{ID: "1", Title: "cheer 1", ImageURL1x: "global-bits-1"},
+					{ID: "100", Title: "cheer 100", ImageURL1x: "global-bits-100"},
+					{ID: "1000", Title: "cheer 1000", ImageURL1x: "global-bits-1000"},
+				}},
+			}
+		case "/chat/badges":
+			atomic.AddInt32(channelCalls, 1)
+			if r.URL.Query().Get("broadcaster_id") != "1234" {
+				break
+			}
+			data = []ChatBadge{
+				{SetID: "subscriber", Versions: []BadgeVersion{
+					{ID: "0", Title: "Subscriber", ImageURL1x: "chan-sub-0"},
+					{ID: "3", Title: "3-Month Subscriber", ImageURL1x: "chan-sub-3"},
+					{ID: "12", Title: "1-Year Subscriber", ImageURL1x: "chan-sub-12"},
+					{ID: "2000", Title: "Tier 2 Subscriber", ImageURL1x: "chan-sub-2000"},
+					{ID: "2006", Title: "Tier 2, 6-Month Subscriber", ImageURL1x: "chan-sub-2006"},
+				}},
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+}
+
+func TestBadgeResolver_Resolve(t *testing.T) {
+	var globalCalls, channelCalls int32
+	client, server := newTestClient(badgeFixtureHandler(&globalCalls, &channelCalls))
+	defer server.Close()
+
+	r := NewBadgeResolver(client)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		channel   string
+		setID     string
+		version   string
+		info      string
+		wantImage string
+		wantChan  bool
+		wantNil   bool
+	}{
+		{name: "channel exact", channel: "1234", setID: "subscriber", version: "3", info: "4", wantImage: "chan-sub-3", wantChan: true},
+		{name: "subscriber months from info", channel: "1234", setID: "subscriber", version: "6", info: "14", wantImage: "chan-sub-12", wantChan: true},
+		{name: "tier 2 stays in tier", channel: "1234", setID: "subscriber", version: "2009", info: "9", wantImage: "chan-sub-2006", wantChan: true},
+		{name: "global fallback", channel: "1234", setID: "moderator", version: "1", wantImage: "global-mod-1x"},
+		{name: "global subscriber for other channel", channel: "9999", setID: "subscriber", version: "3", info: "3", wantImage: "global-sub-1x"},
+		{name: "bits tier fallback", channel: "1234", setID: "bits", version: "500", wantImage: "global-bits-100"},
+		{name: "unknown badge", channel: "1234", setID: "made-up", version: "1", wantNil: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := r.Resolve(ctx, tt.channel, tt.setID, tt.version, tt.info)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if tt.wantNil {
+				if b != nil {
+					t.Errorf("Resolve() = %+v, want nil", b)
+				}
+				return
+			}
+			if b == nil {
+				t.Fatal("Resolve() = nil")
+			}
+			if b.ImageURL1x != tt.wantImage || b.Channel != tt.wantChan {
+				t.Errorf("Resolve() image = %s channel = %v, want %s %v", b.ImageURL1x, b.Channel, tt.wantImage, tt.wantChan)
+			}
+		})
+	}
+
+	if globalCalls != 1 {
+		t.Errorf("global badges fetched %d times, want 1", globalCalls)
+	}
+	if channelCalls != 2 {
+		t.Errorf("channel badges fetched %d times, want 2", channelCalls)
+	}
+
+	if err := r.Refresh(ctx, "1234"); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if globalCalls != 2 || channelCalls != 3 {
+		t.Errorf("after Refresh: global = %d, channel = %d", globalCalls, channelCalls)
+	}
+
+	r.Invalidate("")
+	if _, err := r.Resolve(ctx, "1234", "moderator", "1", ""); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if globalCalls != 3 || channelCalls != 4 {
+		t.Errorf("after Invalidate: global = %d, channel = %d", globalCalls, channelCalls)
+	}
+}
+
+func TestBadgeResolver_ResolveMessage(t *testing.T) {
+	var globalCalls, channelCalls int32
+	client, server := newTestClient(badgeFixtureHandler(&globalCalls, &channelCalls))
+	defer server.Close()
+
+	msg := parseChatMessage(parseIRCMessage("@badge-info=subscriber/14;badges=moderator/1,subscriber/12,bits/100;room-id=1234 :u!u@u.tmi.twitch.tv PRIVMSG #chan :hi"))
+
+	badges, err := NewBadgeResolver(client).ResolveMessage(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("ResolveMessage() error = %v", err)
+	}
+	if len(badges) != 3 {
+		t.Fatalf("got %d badges, want 3", len(badges))
+	}
+	want := []string{"moderator", "subscriber", "bits"}
+	for i, b := range badges {
+		if b.SetID != want[i] {
+			t.Errorf("badge %d = %s, want %s", i, b.SetID, want[i])
+		}
+	}
+	if badges[1].Months != 14 || badges[1].Title != "1-Year Subscriber" {
+		t.Errorf("subscriber badge = %+v", badges[1])
+	}
+
+	// Messages from EventSub keep the event's badge order; their Raw is JSON
+	event := &ChannelChatMessageEvent{Badges: []ChatEventBadge{{SetID: "subscriber", ID: "12", Info: "14"}, {SetID: "moderator", ID: "1"}}}
+	event.BroadcasterUserID = "1234"
+	msg = chatMessageFromEvent(event, `{"badges":[]}`, time.Now())
+	badges, err = NewBadgeResolver(client).ResolveMessage(context.Background(), msg)
+	if err != nil || len(badges) != 2 || badges[0].SetID != "subscriber" || badges[1].SetID != "moderator" {
+		t.Errorf("event badges = %+v, err = %v", badges, err)
+	}
+}
+
+func TestBadgeResolver_ResolveEventBadges(t *testing.T) {
+	var globalCalls, channelCalls int32
+	client, server := newTestClient(badgeFixtureHandler(&globalCalls, &channelCalls))
+	defer server.Close()
+
+	badges, err := NewBadgeResolver(client).ResolveEventBadges(context.Background(), "1234", []ChatEventBadge{
+		{SetID: "subscriber", ID: "12", Info: "16"},
+		{SetID: "unknown", ID: "1"},
+		{SetID: "bits", ID: "1000"},
+	})
+	if err != nil {
+		t.Fatalf("ResolveEventBadges() error = %v", err)
+	}
+	if len(badges) != 2 {
+		t.Fatalf("got %d badges, want 2", len(badges))
+	}
+	if badges[0].ImageURL1x != "chan-sub-12" || badges[0].Months != 16 {
+		t.Errorf("subscriber badge = %+v", badges[0])
+	}
+	if badges[1].ImageURL1x != "global-bits-1000" {
+		t.Errorf("bits badge = %+v", badges[1])
+	}
+}
+
+func TestBadgeResolver_Error(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error":"Unauthorized","status":401,"message":"invalid token"}`))
+	})
+	defer server.Close()
+
+	if _, err := NewBadgeResolver(client).Resolve(context.Background(), "1234", "vip", "1", ""); err == nil {
+		t.Error("Resolve() expected error")
+	}
+}
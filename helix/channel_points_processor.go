@@ -0,0 +1,369 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Redemption statuses
+const (
+	RedemptionStatusUnfulfilled = "UNFULFILLED"
+	RedemptionStatusFulfilled   = "FULFILLED"
+	RedemptionStatusCanceled    = "CANCELED"
+)
+
+// Default RedemptionProcessor settings
+const (
+	defaultRedemptionTimeout  = 30 * time.Second
+	defaultRedemptionDedupTTL = 48 * time.Hour // Redemptions can't be resolved after this anyway
+)
+
+// ErrLeaveRedemptionUnfulfilled can be returned by a RedemptionHandler to
+// leave the redemption in the reward's queue for manual handling instead of
+// fulfilling or refunding it.
+var ErrLeaveRedemptionUnfulfilled = errors.New("leave redemption unfulfilled")
+
+// RedemptionHandler performs the action for a redemption. Returning nil marks
+// the redemption FULFILLED; returning an error (or not returning before the
+// processor's timeout) marks it CANCELED, refunding the viewer's points.
+//
+// The redemption is resolved as soon as the timeout expires, so handlers must
+// stop when ctx is done. A handler that keeps going after that performs its
+// action for a redemption that has already been refunded.
+type RedemptionHandler func(ctx context.Context, r *CustomRewardRedemption) error
+
+// RedemptionResult reports how a redemption was resolved.
+type RedemptionResult struct {
+	Redemption *CustomRewardRedemption
+	Status     string // FULFILLED, CANCELED, or UNFULFILLED when left in the queue
+	Err        error  // Handler error (context.DeadlineExceeded on timeout)
+	UpdateErr  error  // Error from Update Redemption Status
+}
+
+// rewardHandler is a registered handler with its concurrency limit.
+type rewardHandler struct {
+	fn  RedemptionHandler
+	sem chan struct{}
+}
+
+// RedemptionProcessor dispatches channel points redemptions to handlers
+// registered per reward, then marks each redemption FULFILLED, or CANCELED
+// (refunded) when its handler fails or times out.
+//
+// Redemptions arrive from channel.channel_points_custom_reward_redemption.add
+// events (Subscribe or HandleEvent) and from CatchUp, which picks up
+// redemptions left UNFULFILLED while the processor was down. Each redemption
+// ID is dispatched and resolved at most once, whichever source reports it.
+// When Update Redemption Status fails, the status is kept and the update is
+// retried the next time either source reports the redemption, without
+// running its handler again.
+//
+// Twitch only lets the client that created a reward update its redemptions,
+// so the rewards must have been created with the same client ID.
+type RedemptionProcessor struct {
+	client        *Client
+	broadcasterID string
+	timeout       time.Duration
+	onResult      func(RedemptionResult)
+	onError       func(error)
+
+	mu        sync.Mutex
+	handlers  map[string]*rewardHandler
+	seen      map[string]time.Time // redemption ID -> accepted at
+	pending   map[string]string    // redemption ID -> status whose update failed
+	lastPrune time.Time
+	wg        sync.WaitGroup
+}
+
+// RedemptionProcessorOption configures a RedemptionProcessor.
+type RedemptionProcessorOption func(*RedemptionProcessor)
+
+// WithRedemptionTimeout sets how long a handler may run before the
+// redemption is refunded (default 30s).
+func WithRedemptionTimeout(d time.Duration) RedemptionProcessorOption {
+	return func(p *RedemptionProcessor) {
+		p.timeout = d
+	}
+}
+
+// WithRedemptionResultHandler sets the handler called after each redemption
+// is resolved.
+func WithRedemptionResultHandler(fn func(RedemptionResult)) RedemptionProcessorOption {
+	return func(p *RedemptionProcessor) {
+		p.onResult = fn
+	}
+}
+
+// WithRedemptionErrorHandler sets the handler for errors from events received
+// through Subscribe.
+func WithRedemptionErrorHandler(fn func(error)) RedemptionProcessorOption {
+	return func(p *RedemptionProcessor) {
+		p.onError = fn
+	}
+}
+
+// NewRedemptionProcessor creates a redemption processor for a channel. The
+// broadcaster's token needs the channel:manage:redemptions scope.
+func NewRedemptionProcessor(client *Client, broadcasterID string, opts ...RedemptionProcessorOption) *RedemptionProcessor {
+	p := &RedemptionProcessor{
+		client:        client,
+		broadcasterID: broadcasterID,
+		timeout:       defaultRedemptionTimeout,
+		handlers:      make(map[string]*rewardHandler),
+		seen:          make(map[string]time.Time),
+		pending:       make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Handle registers the handler for a reward, running at most maxConcurrent
+// redemptions of it at once (1 if maxConcurrent < 1, which processes them in
+// order). Redemptions of rewards without a handler are ignored.
+func (p *RedemptionProcessor) Handle(rewardID string, maxConcurrent int, handler RedemptionHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[rewardID] = &rewardHandler{fn: handler, sem: make(chan struct{}, max(maxConcurrent, 1))}
+}
+
+// Subscribe subscribes to channel.channel_points_custom_reward_redemption.add
+// for the channel on a connected EventSubWebSocket.
+func (p *RedemptionProcessor) Subscribe(ctx context.Context, ws *EventSubWebSocket) error {
+	eventType := EventSubTypeChannelPointsRedemptionAdd
+	return ws.Subscribe(ctx, eventType, GetEventSubVersion(eventType), BroadcasterCondition(p.broadcasterID), func(data json.RawMessage) {
+		if err := p.HandleEvent(context.Background(), eventType, data); err != nil && p.onError != nil {
+			p.onError(err)
+		}
+	})
+}
+
+// HandleEvent processes a raw channel.channel_points_custom_reward_redemption.add
+// event. Other event types are ignored.
+func (p *RedemptionProcessor) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	if eventType != EventSubTypeChannelPointsRedemptionAdd {
+		return nil
+	}
+	event, err := ParseWSEvent[ChannelPointsRedemptionAddEvent](data)
+	if err != nil {
+		return err
+	}
+	p.Submit(ctx, RedemptionFromEvent(event))
+	return nil
+}
+
+// RedemptionFromEvent converts a redemption add event into a CustomRewardRedemption.
+func RedemptionFromEvent(event *ChannelPointsRedemptionAddEvent) *CustomRewardRedemption {
+	r := &CustomRewardRedemption{
+		BroadcasterID:    event.BroadcasterUserID,
+		BroadcasterLogin: event.BroadcasterUserLogin,
+		BroadcasterName:  event.BroadcasterUserName,
+		ID:               event.ID,
+		UserID:           event.UserID,
+		UserLogin:        event.UserLogin,
+		UserName:         event.UserName,
+		UserInput:        event.UserInput,
+		Status:           normalizeRedemptionStatus(event.Status),
+		RedeemedAt:       event.RedeemedAt,
+	}
+	r.Reward.ID = event.Reward.ID
+	r.Reward.Title = event.Reward.Title
+	r.Reward.Prompt = event.Reward.Prompt
+	r.Reward.Cost = event.Reward.Cost
+	return r
+}
+
+// normalizeRedemptionStatus converts EventSub's lowercase statuses to the
+// uppercase values used by the Helix endpoints.
+func normalizeRedemptionStatus(status string) string {
+	switch status {
+	case "unfulfilled":
+		return RedemptionStatusUnfulfilled
+	case "fulfilled":
+		return RedemptionStatusFulfilled
+	case "canceled":
+		return RedemptionStatusCanceled
+	}
+	return status
+}
+
+// Submit dispatches a redemption to its reward's handler in the background.
+// It returns false if the redemption was not accepted: it is for another
+// channel, its reward has no handler, it is already resolved, or it was
+// submitted before. A redemption whose status update failed is accepted
+// again, but only the update is retried.
+//
+// ctx only bounds the wait for a free handler slot. Once started, the handler
+// runs under the processor's timeout even if ctx is canceled, so canceling a
+// CatchUp doesn't refund redemptions that are being processed.
+func (p *RedemptionProcessor) Submit(ctx context.Context, r *CustomRewardRedemption) bool {
+	if r.BroadcasterID != "" && r.BroadcasterID != p.broadcasterID {
+		return false
+	}
+	if r.Status != "" && r.Status != RedemptionStatusUnfulfilled {
+		return false
+	}
+
+	now := time.Now()
+	p.mu.Lock()
+	h, ok := p.handlers[r.Reward.ID]
+	if !ok {
+		p.mu.Unlock()
+		return false
+	}
+	if now.Sub(p.lastPrune) > time.Hour {
+		for id, at := range p.seen {
+			if now.Sub(at) > defaultRedemptionDedupTTL {
+				delete(p.seen, id)
+				delete(p.pending, id)
+			}
+		}
+		p.lastPrune = now
+	}
+	if _, dup := p.seen[r.ID]; dup {
+		status, ok := p.pending[r.ID]
+		if !ok {
+			p.mu.Unlock()
+			return false
+		}
+		delete(p.pending, r.ID)
+		p.wg.Add(1)
+		p.mu.Unlock()
+		go func() {
+			defer p.wg.Done()
+			p.resolve(context.WithoutCancel(ctx), RedemptionResult{Redemption: r, Status: status})
+		}()
+		return true
+	}
+	p.seen[r.ID] = now
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	go func() {
+		defer p.wg.Done()
+		select {
+		case h.sem <- struct{}{}:
+		case <-ctx.Done():
+			// Never started: forget it so CatchUp can pick it up later
+			p.mu.Lock()
+			delete(p.seen, r.ID)
+			p.mu.Unlock()
+			return
+		}
+		defer func() { <-h.sem }()
+		p.process(context.WithoutCancel(ctx), h, r)
+	}()
+	return true
+}
+
+// process runs the handler for a redemption and resolves it. ctx carries
+// values but is never canceled.
+func (p *RedemptionProcessor) process(ctx context.Context, h *rewardHandler, r *CustomRewardRedemption) {
+	handlerCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("redemption handler panicked: %v", rec)
+			}
+		}()
+		done <- h.fn(handlerCtx, r)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-handlerCtx.Done():
+		err = handlerCtx.Err()
+	}
+
+	result := RedemptionResult{Redemption: r, Err: err}
+	switch {
+	case errors.Is(err, ErrLeaveRedemptionUnfulfilled):
+		result.Status = RedemptionStatusUnfulfilled
+	case err != nil:
+		result.Status = RedemptionStatusCanceled
+	default:
+		result.Status = RedemptionStatusFulfilled
+	}
+
+	p.resolve(ctx, result)
+}
+
+// resolve sets the redemption's status, unless it is left UNFULFILLED, and
+// reports the result. A failed update is kept pending for Submit to retry.
+func (p *RedemptionProcessor) resolve(ctx context.Context, result RedemptionResult) {
+	r := result.Redemption
+	if result.Status != RedemptionStatusUnfulfilled {
+		updateCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+		_, result.UpdateErr = p.client.UpdateRedemptionStatus(updateCtx, &UpdateRedemptionStatusParams{
+			BroadcasterID: p.broadcasterID,
+			RewardID:      r.Reward.ID,
+			IDs:           []string{r.ID},
+			Status:        result.Status,
+		})
+		if result.UpdateErr != nil {
+			// Still UNFULFILLED on Twitch: retry the update, not the handler
+			p.mu.Lock()
+			p.pending[r.ID] = result.Status
+			p.mu.Unlock()
+		}
+	}
+
+	if p.onResult != nil {
+		p.onResult(result)
+	}
+}
+
+// CatchUp fetches the UNFULFILLED redemptions of every registered reward,
+// oldest first, and submits them. Call it on startup, and after reconnecting,
+// to process redemptions made while the processor was not running. It
+// returns the number of redemptions submitted.
+func (p *RedemptionProcessor) CatchUp(ctx context.Context) (int, error) {
+	p.mu.Lock()
+	rewardIDs := make([]string, 0, len(p.handlers))
+	for id := range p.handlers {
+		rewardIDs = append(rewardIDs, id)
+	}
+	p.mu.Unlock()
+
+	submitted := 0
+	for _, rewardID := range rewardIDs {
+		cursor := ""
+		for {
+			resp, err := p.client.GetCustomRewardRedemption(ctx, &GetCustomRewardRedemptionParams{
+				BroadcasterID:    p.broadcasterID,
+				RewardID:         rewardID,
+				Status:           RedemptionStatusUnfulfilled,
+				Sort:             "OLDEST",
+				PaginationParams: &PaginationParams{First: 50, After: cursor},
+			})
+			if err != nil {
+				return submitted, fmt.Errorf("getting redemptions for reward %s: %w", rewardID, err)
+			}
+			for i := range resp.Data {
+				if p.Submit(ctx, &resp.Data[i]) {
+					submitted++
+				}
+			}
+			if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+				break
+			}
+			cursor = resp.Pagination.Cursor
+		}
+	}
+	return submitted, nil
+}
+
+// Wait blocks until every submitted redemption has been resolved.
+func (p *RedemptionProcessor) Wait() {
+	p.wg.Wait()
+}
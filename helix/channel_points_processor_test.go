@@ -0,0 +1,225 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func redemptionServer(t *testing.T, updates *sync.Map) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/channel_points/custom_rewards/redemptions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			if q.Get("status") != "UNFULFILLED" || q.Get("sort") != "OLDEST" {
+				t.Errorf("query = %v", q)
+			}
+			resp := Response[CustomRewardRedemption]{}
+			if q.Get("after") == "" {
+				resp.Data = []CustomRewardRedemption{{BroadcasterID: "1234", ID: "old1", Status: "UNFULFILLED"}}
+				resp.Pagination = &Pagination{Cursor: "next"}
+			} else {
+				resp.Data = []CustomRewardRedemption{{BroadcasterID: "1234", ID: "old2", Status: "UNFULFILLED"}}
+			}
+			for i := range resp.Data {
+				resp.Data[i].Reward.ID = q.Get("reward_id")
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case http.MethodPatch:
+			var body struct {
+				Status string `json:"status"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if _, dup := updates.LoadOrStore(q.Get("id"), body.Status); dup {
+				t.Errorf("redemption %s resolved twice", q.Get("id"))
+			}
+			_ = json.NewEncoder(w).Encode(Response[CustomRewardRedemption]{})
+		}
+	}
+}
+
+func redemptionEvent(id, rewardID string) json.RawMessage {
+	return json.RawMessage(`{"id":"` + id + `","broadcaster_user_id":"1234","user_id":"u1","user_login":"viewer","user_name":"Viewer","user_input":"hi","status":"unfulfilled","reward":{"id":"` + rewardID + `","title":"Hydrate","cost":100,"prompt":""},"redeemed_at":"2026-01-01T00:00:00Z"}`)
+}
+
+func TestRedemptionProcessor_Resolves(t *testing.T) {
+	var updates sync.Map
+	client, server := newTestClient(redemptionServer(t, &updates))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var results []RedemptionResult
+	p := NewRedemptionProcessor(client, "1234",
+		WithRedemptionTimeout(50*time.Millisecond),
+		WithRedemptionResultHandler(func(r RedemptionResult) {
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}),
+	)
+	p.Handle("ok", 2, func(ctx context.Context, r *CustomRewardRedemption) error {
+		if r.UserInput != "hi" || r.Status != RedemptionStatusUnfulfilled {
+			t.Errorf("redemption = %+v", r)
+		}
+		return nil
+	})
+	p.Handle("fail", 1, func(ctx context.Context, r *CustomRewardRedemption) error {
+		return errors.New("boom")
+	})
+	p.Handle("slow", 1, func(ctx context.Context, r *CustomRewardRedemption) error {
+		<-ctx.Done()
+		return nil
+	})
+	p.Handle("manual", 1, func(ctx context.Context, r *CustomRewardRedemption) error {
+		return ErrLeaveRedemptionUnfulfilled
+	})
+
+	ctx := context.Background()
+	for _, ev := range []struct{ id, reward string }{
+		{"a", "ok"}, {"a", "ok"}, {"b", "fail"}, {"c", "slow"}, {"d", "manual"}, {"e", "unregistered"},
+	} {
+		if err := p.HandleEvent(ctx, EventSubTypeChannelPointsRedemptionAdd, redemptionEvent(ev.id, ev.reward)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Wait()
+
+	want := map[string]string{"a": "FULFILLED", "b": "CANCELED", "c": "CANCELED"}
+	for id, status := range want {
+		if got, _ := updates.Load(id); got != status {
+			t.Errorf("redemption %s status = %v, want %s", id, got, status)
+		}
+	}
+	if _, ok := updates.Load("d"); ok {
+		t.Error("manual redemption was resolved")
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for _, r := range results {
+		if r.Redemption.ID == "c" && !errors.Is(r.Err, context.DeadlineExceeded) {
+			t.Errorf("timeout result err = %v", r.Err)
+		}
+		if r.UpdateErr != nil {
+			t.Errorf("UpdateErr = %v", r.UpdateErr)
+		}
+	}
+}
+
+func TestRedemptionProcessor_CatchUpAndConcurrency(t *testing.T) {
+	var updates sync.Map
+	client, server := newTestClient(redemptionServer(t, &updates))
+	defer server.Close()
+
+	var running, peak int32
+	p := NewRedemptionProcessor(client, "1234")
+	p.Handle("song", 1, func(ctx context.Context, r *CustomRewardRedemption) error {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+
+	ctx := context.Background()
+	// An event that is also returned by CatchUp is only handled once
+	if err := p.HandleEvent(ctx, EventSubTypeChannelPointsRedemptionAdd, redemptionEvent("old1", "song")); err != nil {
+		t.Fatal(err)
+	}
+	n, err := p.CatchUp(ctx)
+	if err != nil {
+		t.Fatalf("CatchUp() error = %v", err)
+	}
+	if n != 1 {
+		t.Errorf("CatchUp() submitted %d, want 1", n)
+	}
+	p.Wait()
+
+	for _, id := range []string{"old1", "old2"} {
+		if got, _ := updates.Load(id); got != RedemptionStatusFulfilled {
+			t.Errorf("redemption %s status = %v", id, got)
+		}
+	}
+	if peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1", peak)
+	}
+}
+
+func TestRedemptionProcessor_CanceledCatchUpAndRetry(t *testing.T) {
+	var patches atomic.Int32
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			resp := Response[CustomRewardRedemption]{Data: []CustomRewardRedemption{{BroadcasterID: "1234", ID: "r1", Status: "UNFULFILLED"}}}
+			resp.Data[0].Reward.ID = "gift"
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		// The first status update fails
+		if patches.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response[CustomRewardRedemption]{})
+	}))
+	defer server.Close()
+
+	results := make(chan RedemptionResult, 2)
+	p := NewRedemptionProcessor(client, "1234", WithRedemptionResultHandler(func(r RedemptionResult) { results <- r }))
+	var runs atomic.Int32
+	started, release := make(chan struct{}, 1), make(chan struct{})
+	p.Handle("gift", 1, func(ctx context.Context, r *CustomRewardRedemption) error {
+		runs.Add(1)
+		started <- struct{}{}
+		<-release
+		return ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if n, err := p.CatchUp(ctx); n != 1 || err != nil {
+		t.Fatalf("CatchUp() = %d, %v", n, err)
+	}
+	// Canceling the CatchUp context doesn't cancel the running handler
+	<-started
+	cancel()
+	close(release)
+	p.Wait()
+	if r := <-results; r.Status != RedemptionStatusFulfilled || r.Err != nil || r.UpdateErr == nil {
+		t.Errorf("result = %+v", r)
+	}
+
+	// The redemption is still unfulfilled, so the next CatchUp retries the
+	// status update without running the handler again
+	if n, err := p.CatchUp(context.Background()); n != 1 || err != nil {
+		t.Fatalf("second CatchUp() = %d, %v", n, err)
+	}
+	p.Wait()
+	if r := <-results; r.Status != RedemptionStatusFulfilled || r.UpdateErr != nil || patches.Load() != 2 {
+		t.Errorf("retry result = %+v, patches = %d", r, patches.Load())
+	}
+	if runs.Load() != 1 {
+		t.Errorf("handler ran %d times, want 1", runs.Load())
+	}
+
+	// Once resolved, it isn't submitted again
+	if n, err := p.CatchUp(context.Background()); n != 0 || err != nil {
+		t.Errorf("third CatchUp() = %d, %v", n, err)
+	}
+	p.Wait()
+	if patches.Load() != 2 {
+		t.Errorf("patches = %d, want 2", patches.Load())
+	}
+}
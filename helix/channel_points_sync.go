@@ -0,0 +1,398 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// RewardSpec describes the desired state of a custom reward. Specs are matched
+// to existing rewards by ID when set, otherwise by title (case-insensitive),
+// so set ID to rename a reward instead of replacing it. Spec files are JSON,
+// read with ParseRewardSpecs. The yaml tags use the same keys, so YAML files
+// can be read with any YAML decoder and checked with ValidateRewardSpecs.
+type RewardSpec struct {
+	ID                  string `json:"id,omitempty" yaml:"id,omitempty"`
+	Title               string `json:"title" yaml:"title"`
+	Cost                int    `json:"cost" yaml:"cost"`
+	Prompt              string `json:"prompt,omitempty" yaml:"prompt,omitempty"`                     // Empty leaves it unchanged
+	Enabled             *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`                   // Default true
+	BackgroundColor     string `json:"background_color,omitempty" yaml:"background_color,omitempty"` // Empty leaves it unchanged
+	UserInputRequired   bool   `json:"user_input_required,omitempty" yaml:"user_input_required,omitempty"`
+	MaxPerStream        int    `json:"max_per_stream,omitempty" yaml:"max_per_stream,omitempty"`                   // 0 disables the limit
+	MaxPerUserPerStream int    `json:"max_per_user_per_stream,omitempty" yaml:"max_per_user_per_stream,omitempty"` // 0 disables the limit
+	GlobalCooldown      int    `json:"global_cooldown_seconds,omitempty" yaml:"global_cooldown_seconds,omitempty"` // 0 disables the cooldown
+	SkipRequestQueue    bool   `json:"skip_request_queue,omitempty" yaml:"skip_request_queue,omitempty"`
+	Group               string `json:"group,omitempty" yaml:"group,omitempty"` // Used by SetRewardGroupPaused
+}
+
+// ParseRewardSpecs decodes and validates a JSON array of reward specs. A
+// misspelled field name is an error rather than a silently ignored setting.
+func ParseRewardSpecs(r io.Reader) ([]RewardSpec, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var specs []RewardSpec
+	if err := dec.Decode(&specs); err != nil {
+		return nil, fmt.Errorf("decoding reward specs: %w", err)
+	}
+	if err := ValidateRewardSpecs(specs); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// ValidateRewardSpecs checks that every spec has a title and a positive cost,
+// and that titles and IDs are unique.
+func ValidateRewardSpecs(specs []RewardSpec) error {
+	titles := make(map[string]bool, len(specs))
+	ids := make(map[string]bool, len(specs))
+	for i, s := range specs {
+		if s.Title == "" {
+			return fmt.Errorf("reward spec %d: title is required", i)
+		}
+		if s.Cost < 1 {
+			return fmt.Errorf("reward spec %q: cost must be at least 1", s.Title)
+		}
+		key := strings.ToLower(s.Title)
+		if titles[key] {
+			return fmt.Errorf("reward spec %q: duplicate title", s.Title)
+		}
+		titles[key] = true
+		if s.ID != "" {
+			if ids[s.ID] {
+				return fmt.Errorf("reward spec %q: duplicate ID %s", s.Title, s.ID)
+			}
+			ids[s.ID] = true
+		}
+	}
+	return nil
+}
+
+// RewardChangeAction is the action a reward sync takes for one reward.
+type RewardChangeAction string
+
+// Reward change actions
+const (
+	RewardCreate    RewardChangeAction = "create"
+	RewardUpdate    RewardChangeAction = "update"
+	RewardDelete    RewardChangeAction = "delete"
+	RewardUnchanged RewardChangeAction = "unchanged"
+)
+
+// RewardFieldChange is a field that differs between a reward and its spec.
+type RewardFieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// RewardChange is one step of a reward sync plan.
+type RewardChange struct {
+	Action  RewardChangeAction
+	Title   string
+	Spec    *RewardSpec   // Nil for deletes
+	Current *CustomReward // Nil for creates
+	Fields  []RewardFieldChange
+	Result  *CustomReward // Created or updated reward, once applied
+	Err     error         // Error applying the change
+}
+
+// RewardSyncPlan lists the changes needed to bring a channel's rewards in
+// line with a set of specs.
+type RewardSyncPlan struct {
+	BroadcasterID string
+	Changes       []RewardChange
+	Applied       bool
+}
+
+// HasChanges reports whether the plan creates, updates, or deletes anything.
+func (p *RewardSyncPlan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Action != RewardUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the errors from applying the plan, or nil.
+func (p *RewardSyncPlan) Err() error {
+	var errs []error
+	for _, c := range p.Changes {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", c.Action, c.Title, c.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String formats the plan for display, one line per change, with unchanged
+// rewards omitted.
+func (p *RewardSyncPlan) String() string {
+	var b bytes.Buffer
+	for _, c := range p.Changes {
+		switch c.Action {
+		case RewardCreate:
+			fmt.Fprintf(&b, "+ create %q (cost %d)\n", c.Title, c.Spec.Cost)
+		case RewardDelete:
+			fmt.Fprintf(&b, "- delete %q\n", c.Title)
+		case RewardUpdate:
+			fmt.Fprintf(&b, "~ update %q\n", c.Title)
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Field, f.Old, f.New)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "no changes\n"
+	}
+	return b.String()
+}
+
+// RewardSyncOptions configures SyncCustomRewards.
+type RewardSyncOptions struct {
+	// DryRun computes the plan without changing anything.
+	DryRun bool
+	// Delete removes manageable rewards that have no spec. Rewards created
+	// by other clients are never touched.
+	Delete bool
+}
+
+// SyncCustomRewards brings the channel's custom rewards in line with specs.
+// It compares them against the rewards this client can manage (Get Custom
+// Reward with only_manageable_rewards), then creates, updates, and optionally
+// deletes rewards. Paused state is left alone; use SetRewardGroupPaused.
+// A failed change doesn't stop the rest: each records its own Err, and the
+// returned error joins them.
+// Requires: channel:manage:redemptions scope.
+func (c *Client) SyncCustomRewards(ctx context.Context, broadcasterID string, specs []RewardSpec, opts *RewardSyncOptions) (*RewardSyncPlan, error) {
+	if opts == nil {
+		opts = &RewardSyncOptions{}
+	}
+	if err := ValidateRewardSpecs(specs); err != nil {
+		return nil, err
+	}
+	resp, err := c.GetCustomReward(ctx, &GetCustomRewardParams{BroadcasterID: broadcasterID, OnlyManageableRewards: true})
+	if err != nil {
+		return nil, err
+	}
+
+	plan := PlanCustomRewards(broadcasterID, specs, resp.Data, opts.Delete)
+	if opts.DryRun {
+		return plan, nil
+	}
+	plan.Applied = true
+	for i := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return plan, err
+		}
+		change := &plan.Changes[i]
+		switch change.Action {
+		case RewardCreate:
+			change.Result, change.Err = c.CreateCustomReward(ctx, createRewardParams(broadcasterID, change.Spec))
+		case RewardUpdate:
+			change.Result, change.Err = c.UpdateCustomReward(ctx, updateRewardParams(broadcasterID, change.Spec, change.Current))
+		case RewardDelete:
+			change.Err = c.DeleteCustomReward(ctx, broadcasterID, change.Current.ID)
+		}
+	}
+	return plan, plan.Err()
+}
+
+// PlanCustomRewards diffs specs against existing rewards without calling the
+// API. Existing rewards without a spec are deleted when deleteUnknown is set,
+// and otherwise left out of the plan.
+func PlanCustomRewards(broadcasterID string, specs []RewardSpec, existing []CustomReward, deleteUnknown bool) *RewardSyncPlan {
+	plan := &RewardSyncPlan{BroadcasterID: broadcasterID}
+	matched := make(map[string]bool, len(existing))
+
+	for i := range specs {
+		spec := &specs[i]
+		current := findReward(existing, spec)
+		if current == nil {
+			plan.Changes = append(plan.Changes, RewardChange{Action: RewardCreate, Title: spec.Title, Spec: spec})
+			continue
+		}
+		matched[current.ID] = true
+		change := RewardChange{Action: RewardUnchanged, Title: spec.Title, Spec: spec, Current: current, Fields: diffReward(spec, current)}
+		if len(change.Fields) > 0 {
+			change.Action = RewardUpdate
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	if deleteUnknown {
+		for i := range existing {
+			if !matched[existing[i].ID] {
+				plan.Changes = append(plan.Changes, RewardChange{Action: RewardDelete, Title: existing[i].Title, Current: &existing[i]})
+			}
+		}
+	}
+	return plan
+}
+
+// findReward returns the existing reward a spec refers to.
+func findReward(existing []CustomReward, spec *RewardSpec) *CustomReward {
+	for i := range existing {
+		if spec.ID != "" {
+			if existing[i].ID == spec.ID {
+				return &existing[i]
+			}
+		} else if strings.EqualFold(existing[i].Title, spec.Title) {
+			return &existing[i]
+		}
+	}
+	return nil
+}
+
+// specEnabled returns whether a spec's reward should be enabled.
+func specEnabled(spec *RewardSpec) bool {
+	return spec.Enabled == nil || *spec.Enabled
+}
+
+// diffReward lists the fields of current that differ from spec.
+func diffReward(spec *RewardSpec, current *CustomReward) []RewardFieldChange {
+	var fields []RewardFieldChange
+	add := func(field, old, new string) {
+		if old != new {
+			fields = append(fields, RewardFieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	add("title", current.Title, spec.Title)
+	add("cost", strconv.Itoa(current.Cost), strconv.Itoa(spec.Cost))
+	if spec.Prompt != "" {
+		add("prompt", strconv.Quote(current.Prompt), strconv.Quote(spec.Prompt))
+	}
+	add("enabled", strconv.FormatBool(current.IsEnabled), strconv.FormatBool(specEnabled(spec)))
+	if spec.BackgroundColor != "" && !strings.EqualFold(current.BackgroundColor, spec.BackgroundColor) {
+		add("background_color", current.BackgroundColor, spec.BackgroundColor)
+	}
+	add("user_input_required", strconv.FormatBool(current.IsUserInputRequired), strconv.FormatBool(spec.UserInputRequired))
+	add("max_per_stream", limitString(current.MaxPerStreamSetting.IsEnabled, current.MaxPerStreamSetting.MaxPerStream), limitString(spec.MaxPerStream > 0, spec.MaxPerStream))
+	add("max_per_user_per_stream", limitString(current.MaxPerUserPerStreamSetting.IsEnabled, current.MaxPerUserPerStreamSetting.MaxPerUserPerStream), limitString(spec.MaxPerUserPerStream > 0, spec.MaxPerUserPerStream))
+	add("global_cooldown_seconds", limitString(current.GlobalCooldownSetting.IsEnabled, current.GlobalCooldownSetting.GlobalCooldownSeconds), limitString(spec.GlobalCooldown > 0, spec.GlobalCooldown))
+	add("skip_request_queue", strconv.FormatBool(current.ShouldRedemptionsSkipRequestQueue), strconv.FormatBool(spec.SkipRequestQueue))
+	return fields
+}
+
+// limitString formats an optional reward limit.
+func limitString(enabled bool, n int) string {
+	if !enabled {
+		return "off"
+	}
+	return strconv.Itoa(n)
+}
+
+// createRewardParams builds the Create Custom Reward request for a spec.
+func createRewardParams(broadcasterID string, spec *RewardSpec) *CreateCustomRewardParams {
+	enabled := specEnabled(spec)
+	inputRequired := spec.UserInputRequired
+	maxPerStream, maxPerUser, cooldown := spec.MaxPerStream, spec.MaxPerUserPerStream, spec.GlobalCooldown
+	maxPerStreamEnabled, maxPerUserEnabled, cooldownEnabled := maxPerStream > 0, maxPerUser > 0, cooldown > 0
+	skipQueue := spec.SkipRequestQueue
+	p := &CreateCustomRewardParams{
+		BroadcasterID:                     broadcasterID,
+		Title:                             spec.Title,
+		Cost:                              spec.Cost,
+		Prompt:                            spec.Prompt,
+		IsEnabled:                         &enabled,
+		BackgroundColor:                   spec.BackgroundColor,
+		IsUserInputRequired:               &inputRequired,
+		IsMaxPerStreamEnabled:             &maxPerStreamEnabled,
+		IsMaxPerUserPerStreamEnabled:      &maxPerUserEnabled,
+		IsGlobalCooldownEnabled:           &cooldownEnabled,
+		ShouldRedemptionsSkipRequestQueue: &skipQueue,
+	}
+	if maxPerStreamEnabled {
+		p.MaxPerStream = &maxPerStream
+	}
+	if maxPerUserEnabled {
+		p.MaxPerUserPerStream = &maxPerUser
+	}
+	if cooldownEnabled {
+		p.GlobalCooldownSeconds = &cooldown
+	}
+	return p
+}
+
+// updateRewardParams builds the Update Custom Reward request for a spec. Only
+// the changed settings are sent.
+func updateRewardParams(broadcasterID string, spec *RewardSpec, current *CustomReward) *UpdateCustomRewardParams {
+	enabled := specEnabled(spec)
+	cost, inputRequired, skipQueue := spec.Cost, spec.UserInputRequired, spec.SkipRequestQueue
+	maxPerStream, maxPerUser, cooldown := spec.MaxPerStream, spec.MaxPerUserPerStream, spec.GlobalCooldown
+	maxPerStreamEnabled, maxPerUserEnabled, cooldownEnabled := maxPerStream > 0, maxPerUser > 0, cooldown > 0
+
+	p := &UpdateCustomRewardParams{BroadcasterID: broadcasterID, ID: current.ID}
+	for _, f := range diffReward(spec, current) {
+		switch f.Field {
+		case "title":
+			p.Title = spec.Title
+		case "cost":
+			p.Cost = &cost
+		case "prompt":
+			p.Prompt = spec.Prompt
+		case "enabled":
+			p.IsEnabled = &enabled
+		case "background_color":
+			p.BackgroundColor = spec.BackgroundColor
+		case "user_input_required":
+			p.IsUserInputRequired = &inputRequired
+		case "max_per_stream":
+			p.IsMaxPerStreamEnabled = &maxPerStreamEnabled
+			if maxPerStreamEnabled {
+				p.MaxPerStream = &maxPerStream
+			}
+		case "max_per_user_per_stream":
+			p.IsMaxPerUserPerStreamEnabled = &maxPerUserEnabled
+			if maxPerUserEnabled {
+				p.MaxPerUserPerStream = &maxPerUser
+			}
+		case "global_cooldown_seconds":
+			p.IsGlobalCooldownEnabled = &cooldownEnabled
+			if cooldownEnabled {
+				p.GlobalCooldownSeconds = &cooldown
+			}
+		case "skip_request_queue":
+			p.ShouldRedemptionsSkipRequestQueue = &skipQueue
+		}
+	}
+	return p
+}
+
+// SetRewardGroupPaused pauses or resumes every reward whose spec is in group,
+// for example pausing gameplay rewards while the channel is in Just Chatting.
+// Rewards already in the requested state, and specs without an existing
+// reward, are skipped.
+// Requires: channel:manage:redemptions scope.
+func (c *Client) SetRewardGroupPaused(ctx context.Context, broadcasterID string, specs []RewardSpec, group string, paused bool) error {
+	resp, err := c.GetCustomReward(ctx, &GetCustomRewardParams{BroadcasterID: broadcasterID, OnlyManageableRewards: true})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range specs {
+		if specs[i].Group != group {
+			continue
+		}
+		current := findReward(resp.Data, &specs[i])
+		if current == nil || current.IsPaused == paused {
+			continue
+		}
+		_, err := c.UpdateCustomReward(ctx, &UpdateCustomRewardParams{
+			BroadcasterID: broadcasterID,
+			ID:            current.ID,
+			IsPaused:      &paused,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reward %q: %w", current.Title, err))
+		}
+	}
+	return errors.Join(errs...)
+}
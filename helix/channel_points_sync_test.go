@@ -0,0 +1,145 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func rewardSyncHandler(t *testing.T, existing []CustomReward, calls *[]string) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method == http.MethodGet {
+			if q.Get("only_manageable_rewards") != "true" {
+				t.Error("only_manageable_rewards not set")
+			}
+			_ = json.NewEncoder(w).Encode(Response[CustomReward]{Data: existing})
+			return
+		}
+		var body map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		raw, _ := json.Marshal(body)
+		mu.Lock()
+		*calls = append(*calls, r.Method+" "+q.Get("id")+" "+string(raw))
+		mu.Unlock()
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response[CustomReward]{Data: []CustomReward{{ID: "new"}}})
+	}
+}
+
+var syncExisting = []CustomReward{
+	{ID: "r1", Title: "Hydrate", Cost: 100, IsEnabled: true},
+	{ID: "r2", Title: "Song Request", Cost: 500, IsEnabled: true, IsUserInputRequired: true,
+		GlobalCooldownSetting: GlobalCooldown{IsEnabled: true, GlobalCooldownSeconds: 60}},
+	{ID: "r3", Title: "Old Reward", Cost: 10, IsEnabled: true},
+}
+
+var syncSpecs = []RewardSpec{
+	{Title: "Hydrate", Cost: 100},
+	{Title: "Song Request", Cost: 1000, UserInputRequired: true, MaxPerStream: 20},
+	{Title: "Jump", Cost: 50, Group: "gameplay"},
+}
+
+func TestSyncCustomRewards_DryRun(t *testing.T) {
+	var calls []string
+	client, server := newTestClient(rewardSyncHandler(t, syncExisting, &calls))
+	defer server.Close()
+
+	plan, err := client.SyncCustomRewards(context.Background(), "1234", syncSpecs, &RewardSyncOptions{DryRun: true, Delete: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("dry run made calls: %v", calls)
+	}
+	want := `~ update "Song Request"
+    cost: 500 -> 1000
+    max_per_stream: off -> 20
+    global_cooldown_seconds: 60 -> off
++ create "Jump" (cost 50)
+- delete "Old Reward"
+`
+	if got := plan.String(); got != want {
+		t.Errorf("plan =\n%s\nwant\n%s", got, want)
+	}
+	if plan.Changes[0].Action != RewardUnchanged || !plan.HasChanges() {
+		t.Errorf("changes = %+v", plan.Changes)
+	}
+}
+
+func TestSyncCustomRewards_Apply(t *testing.T) {
+	var calls []string
+	client, server := newTestClient(rewardSyncHandler(t, syncExisting, &calls))
+	defer server.Close()
+
+	plan, err := client.SyncCustomRewards(context.Background(), "1234", syncSpecs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Applied {
+		t.Error("plan not applied")
+	}
+	// Without Delete, the unknown reward is left alone
+	if len(calls) != 2 {
+		t.Fatalf("calls = %v", calls)
+	}
+	if calls[0] != `PATCH r2 {"cost":1000,"is_global_cooldown_enabled":false,"is_max_per_stream_enabled":true,"max_per_stream":20}` {
+		t.Errorf("update call = %s", calls[0])
+	}
+	if !strings.HasPrefix(calls[1], "POST ") || !strings.Contains(calls[1], `"title":"Jump"`) || !strings.Contains(calls[1], `"is_enabled":true`) {
+		t.Errorf("create call = %s", calls[1])
+	}
+}
+
+func TestSetRewardGroupPaused(t *testing.T) {
+	existing := []CustomReward{{ID: "r4", Title: "Jump", Cost: 50}, {ID: "r1", Title: "Hydrate", Cost: 100}}
+	var calls []string
+	client, server := newTestClient(rewardSyncHandler(t, existing, &calls))
+	defer server.Close()
+
+	if err := client.SetRewardGroupPaused(context.Background(), "1234", syncSpecs, "gameplay", true); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0] != `PATCH r4 {"is_paused":true}` {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestParseRewardSpecs(t *testing.T) {
+	specs, err := ParseRewardSpecs(strings.NewReader(`[{"title":"Hydrate","cost":100,"enabled":false,"group":"chat"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 || specEnabled(&specs[0]) || specs[0].Group != "chat" {
+		t.Errorf("specs = %+v", specs)
+	}
+
+	for _, bad := range []string{
+		`[{"title":"Hydrate","cost":100,"costt":5}]`,
+		`[{"title":"Hydrate","cost":0}]`,
+		`[{"title":"A","cost":1},{"title":"a","cost":2}]`,
+		`[{"cost":1}]`,
+	} {
+		if _, err := ParseRewardSpecs(strings.NewReader(bad)); err == nil {
+			t.Errorf("ParseRewardSpecs(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestRewardSpec_YAMLTagsMatchJSON(t *testing.T) {
+	typ := reflect.TypeFor[RewardSpec]()
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if f.Tag.Get("yaml") != f.Tag.Get("json") {
+			t.Errorf("%s: yaml tag %q, json tag %q", f.Name, f.Tag.Get("yaml"), f.Tag.Get("json"))
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrCategoryNotFound is returned when a category name matches no category.
+var ErrCategoryNotFound = errors.New("category not found")
+
+// ErrCategoryAmbiguous is returned when a category name only partly matches
+// several categories equally well.
+var ErrCategoryAmbiguous = errors.New("category name is ambiguous")
+
+// Twitch limits on channel information
+const (
+	maxChannelTags      = 10
+	maxChannelTagLength = 25
+	maxChannelTitle     = 140
+	cclMatureGame       = "MatureGame" // Set by Twitch from the category
+)
+
+// ValidateTags checks tags against Twitch's rules: at most 10 tags, each 1 to
+// 25 letters or digits with no spaces or symbols, and no duplicates
+// (case-insensitive).
+func ValidateTags(tags []string) error {
+	if len(tags) > maxChannelTags {
+		return fmt.Errorf("%d tags, at most %d are allowed", len(tags), maxChannelTags)
+	}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if n := utf8.RuneCountInString(tag); n == 0 || n > maxChannelTagLength {
+			return fmt.Errorf("tag %q must be 1 to %d characters", tag, maxChannelTagLength)
+		}
+		for _, r := range tag {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return fmt.Errorf("tag %q contains %q; only letters and digits are allowed", tag, r)
+			}
+		}
+		key := strings.ToLower(tag)
+		if seen[key] {
+			return fmt.Errorf("duplicate tag %q", tag)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// RankCategories orders category search results by how well they match a
+// name: exact (case-insensitive) matches first, then matches ignoring spaces
+// and punctuation, then prefix and substring matches, then the rest in the
+// API's order.
+func RankCategories(name string, results []SearchCategory) []SearchCategory {
+	ranked := slices.Clone(results)
+	slices.SortStableFunc(ranked, func(a, b SearchCategory) int {
+		return categoryRank(name, a.Name) - categoryRank(name, b.Name)
+	})
+	return ranked
+}
+
+// Category match ranks, best first
+const (
+	categoryRankExact = iota
+	categoryRankNormalized
+	categoryRankPrefix
+	categoryRankSubstring
+	categoryRankNone
+)
+
+// categoryRank reports how well a category name matches a query.
+func categoryRank(query, name string) int {
+	q, n := normalizeCategoryName(query), normalizeCategoryName(name)
+	switch {
+	case strings.EqualFold(name, query):
+		return categoryRankExact
+	case n == q:
+		return categoryRankNormalized
+	case strings.HasPrefix(n, q):
+		return categoryRankPrefix
+	case strings.Contains(n, q):
+		return categoryRankSubstring
+	}
+	return categoryRankNone
+}
+
+// normalizeCategoryName lowercases a name and drops everything but letters
+// and digits.
+func normalizeCategoryName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+}
+
+// ResolveCategory finds the category for a name: an exact match from
+// Get Games, or else the best ranked Search Categories result whose name
+// contains the given one (ignoring case, spaces and punctuation). It returns
+// ErrCategoryNotFound if nothing matches, and ErrCategoryAmbiguous if several
+// categories match only partly and equally well; both errors list the
+// candidates found.
+func (c *Client) ResolveCategory(ctx context.Context, name string) (*Category, error) {
+	categories, err := c.ResolveCategories(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	category := categories[name]
+	return &category, nil
+}
+
+// ResolveCategories resolves several category names at once, keyed by the
+// given names. Exact matches are looked up in one Get Games request; the
+// rest are searched one by one.
+func (c *Client) ResolveCategories(ctx context.Context, names ...string) (map[string]Category, error) {
+	categories := make(map[string]Category, len(names))
+	if len(names) == 0 {
+		return categories, nil
+	}
+	games, err := c.GetGames(ctx, &GetGamesParams{Names: names})
+	if err != nil {
+		return nil, fmt.Errorf("looking up categories: %w", err)
+	}
+	for _, name := range names {
+		if i := slices.IndexFunc(games.Data, func(g Game) bool { return strings.EqualFold(g.Name, name) }); i >= 0 {
+			categories[name] = Category{ID: games.Data[i].ID, Name: games.Data[i].Name}
+		}
+	}
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := categories[name]; ok {
+			continue
+		}
+		resp, err := c.SearchCategories(ctx, &SearchCategoriesParams{Query: name, PaginationParams: &PaginationParams{First: 20}})
+		if err != nil {
+			return nil, fmt.Errorf("searching categories for %q: %w", name, err)
+		}
+		ranked := RankCategories(name, resp.Data)
+		if len(ranked) == 0 {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrCategoryNotFound, name))
+			continue
+		}
+		best := categoryRank(name, ranked[0].Name)
+		if best == categoryRankNone {
+			errs = append(errs, fmt.Errorf("%w: %q (search found %s)", ErrCategoryNotFound, name, categoryNames(ranked)))
+			continue
+		}
+		if best >= categoryRankPrefix && len(ranked) > 1 && categoryRank(name, ranked[1].Name) == best {
+			tied := slices.DeleteFunc(slices.Clone(ranked), func(c SearchCategory) bool { return categoryRank(name, c.Name) != best })
+			errs = append(errs, fmt.Errorf("%w: %q matches %s", ErrCategoryAmbiguous, name, categoryNames(tied)))
+			continue
+		}
+		categories[name] = Category{ID: ranked[0].ID, Name: ranked[0].Name}
+	}
+	return categories, errors.Join(errs...)
+}
+
+// categoryNames quotes up to five category names for an error message.
+func categoryNames(results []SearchCategory) string {
+	var names []string
+	for _, c := range results[:min(len(results), 5)] {
+		names = append(names, strconv.Quote(c.Name))
+	}
+	if len(results) > 5 {
+		names = append(names, "...")
+	}
+	return strings.Join(names, ", ")
+}
+
+// ChannelProfile is the desired channel information: title, category, tags,
+// content classification labels, and branded content. Unset fields are left
+// unchanged.
+type ChannelProfile struct {
+	Title          string   `json:"title,omitempty"`
+	Category       string   `json:"category,omitempty"`    // Category name, resolved with ResolveCategory
+	CategoryID     string   `json:"category_id,omitempty"` // Takes precedence over Category
+	Language       string   `json:"language,omitempty"`    // ISO 639-1 code, or "other"
+	Tags           []string `json:"tags,omitempty"`        // Nil leaves them unchanged; empty removes them
+	Labels         []string `json:"labels,omitempty"`      // Enabled CCL IDs; nil leaves them unchanged, empty disables all
+	BrandedContent *bool    `json:"branded_content,omitempty"`
+}
+
+// ParseChannelProfile decodes and validates a JSON channel profile. Keys
+// must match the field tags exactly; "game" instead of "category", for
+// example, is an error rather than a profile that leaves the category alone.
+func ParseChannelProfile(r io.Reader) (*ChannelProfile, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var p ChannelProfile
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("decoding channel profile: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks the profile without calling the API: title length, tags,
+// and that MatureGame, which Twitch sets from the category, isn't listed.
+func (p *ChannelProfile) Validate() error {
+	if utf8.RuneCountInString(p.Title) > maxChannelTitle {
+		return fmt.Errorf("title is longer than %d characters", maxChannelTitle)
+	}
+	if err := ValidateTags(p.Tags); err != nil {
+		return err
+	}
+	if slices.Contains(p.Labels, cclMatureGame) {
+		return fmt.Errorf("content classification label %s is set by the category", cclMatureGame)
+	}
+	return nil
+}
+
+// ChannelFieldChange is a channel field that differs from the profile.
+type ChannelFieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// ChannelProfilePlan lists the channel information changes of a profile.
+type ChannelProfilePlan struct {
+	BroadcasterID string
+	Current       *Channel
+	Category      *Category // Resolved category, nil if the profile has none
+	Fields        []ChannelFieldChange
+	Applied       bool
+
+	body   map[string]any
+	labels []ContentClassificationLabelSetting
+}
+
+// HasChanges reports whether the plan changes anything.
+func (p *ChannelProfilePlan) HasChanges() bool {
+	return len(p.Fields) > 0
+}
+
+// String formats the plan for display, one line per changed field.
+func (p *ChannelProfilePlan) String() string {
+	if !p.HasChanges() {
+		return "no changes\n"
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "~ update channel %s\n", p.BroadcasterID)
+	for _, f := range p.Fields {
+		fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Field, f.Old, f.New)
+	}
+	return b.String()
+}
+
+// ChannelProfileOptions configures ApplyChannelProfile.
+type ChannelProfileOptions struct {
+	// DryRun computes the plan without changing anything.
+	DryRun bool
+	// Locale for validating content classification labels (default en-US).
+	Locale string
+}
+
+// ApplyChannelProfile brings a channel's information in line with a profile.
+// It validates the profile, resolves the category name, checks the content
+// classification labels against Get Content Classification Labels, and diffs
+// everything against Get Channel Information. The changes are then sent in a
+// single Modify Channel Information request, so either all of them apply or
+// none do.
+// Requires: channel:manage:broadcast scope.
+func (c *Client) ApplyChannelProfile(ctx context.Context, broadcasterID string, profile *ChannelProfile, opts *ChannelProfileOptions) (*ChannelProfilePlan, error) {
+	if opts == nil {
+		opts = &ChannelProfileOptions{}
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	resp, err := c.GetChannelInformation(ctx, &GetChannelInformationParams{BroadcasterIDs: []string{broadcasterID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("channel %s not found", broadcasterID)
+	}
+	plan := &ChannelProfilePlan{BroadcasterID: broadcasterID, Current: &resp.Data[0], body: make(map[string]any)}
+
+	switch {
+	case profile.CategoryID != "":
+		plan.Category = &Category{ID: profile.CategoryID}
+	case profile.Category != "":
+		if plan.Category, err = c.ResolveCategory(ctx, profile.Category); err != nil {
+			return nil, err
+		}
+	}
+	if len(profile.Labels) > 0 {
+		if err := c.validateLabels(ctx, profile.Labels, opts.Locale); err != nil {
+			return nil, err
+		}
+	}
+
+	plan.diff(profile)
+	if opts.DryRun || !plan.HasChanges() {
+		return plan, nil
+	}
+	q := url.Values{}
+	q.Set("broadcaster_id", broadcasterID)
+	if err := c.patch(ctx, "/channels", q, plan.body, nil); err != nil {
+		return plan, err
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// validateLabels checks that every label ID exists.
+func (c *Client) validateLabels(ctx context.Context, ids []string, locale string) error {
+	resp, err := c.GetContentClassificationLabels(ctx, &GetContentClassificationLabelsParams{Locale: locale})
+	if err != nil {
+		return fmt.Errorf("getting content classification labels: %w", err)
+	}
+	var unknown []string
+	for _, id := range ids {
+		if !slices.ContainsFunc(resp.Data, func(l ContentClassificationLabel) bool { return l.ID == id }) {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown content classification labels: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// diff fills in the changed fields and the request body.
+func (p *ChannelProfilePlan) diff(profile *ChannelProfile) {
+	current := p.Current
+	change := func(field, key string, old, new string, value any) {
+		p.Fields = append(p.Fields, ChannelFieldChange{Field: field, Old: old, New: new})
+		p.body[key] = value
+	}
+
+	if profile.Title != "" && profile.Title != current.Title {
+		change("title", "title", strconv.Quote(current.Title), strconv.Quote(profile.Title), profile.Title)
+	}
+	if p.Category != nil && p.Category.ID != current.GameID {
+		name := p.Category.Name
+		if name == "" {
+			name = p.Category.ID
+		}
+		change("category", "game_id", strconv.Quote(current.GameName), strconv.Quote(name), p.Category.ID)
+	}
+	if profile.Language != "" && profile.Language != current.BroadcasterLanguage {
+		change("language", "broadcaster_language", current.BroadcasterLanguage, profile.Language, profile.Language)
+	}
+	if profile.Tags != nil && !slices.Equal(profile.Tags, current.Tags) {
+		change("tags", "tags", fmt.Sprint(current.Tags), fmt.Sprint(profile.Tags), profile.Tags)
+	}
+	if profile.Labels != nil {
+		enabled := slices.DeleteFunc(slices.Clone(current.ContentClassificationLabels), func(id string) bool { return id == cclMatureGame })
+		for _, id := range profile.Labels {
+			if !slices.Contains(enabled, id) {
+				p.labels = append(p.labels, ContentClassificationLabelSetting{ID: id, IsEnabled: true})
+			}
+		}
+		for _, id := range enabled {
+			if !slices.Contains(profile.Labels, id) {
+				p.labels = append(p.labels, ContentClassificationLabelSetting{ID: id, IsEnabled: false})
+			}
+		}
+		if len(p.labels) > 0 {
+			change("labels", "content_classification_labels", fmt.Sprint(slices.Sorted(slices.Values(enabled))), fmt.Sprint(slices.Sorted(slices.Values(profile.Labels))), p.labels)
+		}
+	}
+	if profile.BrandedContent != nil && *profile.BrandedContent != current.IsBrandedContent {
+		change("branded_content", "is_branded_content", strconv.FormatBool(current.IsBrandedContent), strconv.FormatBool(*profile.BrandedContent), *profile.BrandedContent)
+	}
+}
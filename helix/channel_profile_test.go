@@ -0,0 +1,188 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResolveCategories(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/games":
+			var data []Game
+			for _, name := range q["name"] {
+				if strings.EqualFold(name, "chess") {
+					data = append(data, Game{ID: "743", Name: "Chess"})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(Response[Game]{Data: data})
+		case "/search/categories":
+			var data []SearchCategory
+			switch q.Get("query") {
+			case "just chat":
+				data = []SearchCategory{{ID: "1", Name: "Chatting Simulator"}, {ID: "509658", Name: "Just Chatting"}}
+			case "fortnight":
+				data = []SearchCategory{{ID: "33214", Name: "Fortnite"}}
+			case "mario":
+				data = []SearchCategory{{ID: "2", Name: "Mario Kart 8"}, {ID: "3", Name: "Super Mario Odyssey"}, {ID: "4", Name: "Mario Party"}}
+			}
+			_ = json.NewEncoder(w).Encode(Response[SearchCategory]{Data: data})
+		}
+	}))
+	defer server.Close()
+
+	categories, err := client.ResolveCategories(context.Background(), "chess", "just chat", "nothing", "fortnight", "mario")
+	if !errors.Is(err, ErrCategoryNotFound) || !strings.Contains(err.Error(), `"nothing"`) {
+		t.Errorf("err = %v", err)
+	}
+	// A search result that doesn't contain the name is not a match
+	if !strings.Contains(err.Error(), `"fortnight" (search found "Fortnite")`) {
+		t.Errorf("err = %v", err)
+	}
+	if !errors.Is(err, ErrCategoryAmbiguous) || !strings.Contains(err.Error(), `"mario" matches "Mario Kart 8", "Mario Party"`) {
+		t.Errorf("err = %v", err)
+	}
+	if categories["chess"] != (Category{ID: "743", Name: "Chess"}) || categories["just chat"].ID != "509658" || len(categories) != 2 {
+		t.Errorf("categories = %v", categories)
+	}
+}
+
+func TestRankCategories(t *testing.T) {
+	results := []SearchCategory{{Name: "Pokémon Go"}, {Name: "Super Mario Bros."}, {Name: "Mario Kart"}, {Name: "super mario bros"}, {Name: "Super Mario Bros. 3"}}
+	var names []string
+	for _, c := range RankCategories("Super Mario Bros", results) {
+		names = append(names, c.Name)
+	}
+	if got := strings.Join(names, "|"); got != "super mario bros|Super Mario Bros.|Super Mario Bros. 3|Pokémon Go|Mario Kart" {
+		t.Errorf("ranked = %s", got)
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	if err := ValidateTags([]string{"English", "Speedrun", "日本語", "100Percent"}); err != nil {
+		t.Errorf("valid tags: %v", err)
+	}
+	for name, tags := range map[string][]string{
+		"too many":  strings.Fields("a b c d e f g h i j k"),
+		"too long":  {strings.Repeat("a", 26)},
+		"empty":     {""},
+		"space":     {"Any Percent"},
+		"symbol":    {"100%"},
+		"duplicate": {"English", "english"},
+	} {
+		if err := ValidateTags(tags); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestApplyChannelProfile(t *testing.T) {
+	var mu sync.Mutex
+	var patches []map[string]any
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/channels" && r.Method == http.MethodPatch:
+			if r.URL.Query().Get("broadcaster_id") != "1234" {
+				t.Errorf("patch query = %v", r.URL.Query())
+			}
+			var body map[string]any
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			patches = append(patches, body)
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/channels":
+			_ = json.NewEncoder(w).Encode(Response[Channel]{Data: []Channel{{
+				BroadcasterID:               "1234",
+				BroadcasterLanguage:         "en",
+				GameID:                      "743",
+				GameName:                    "Chess",
+				Title:                       "Old title",
+				Tags:                        []string{"English", "Chess"},
+				ContentClassificationLabels: []string{"MatureGame", "Gambling"},
+			}}})
+		case r.URL.Path == "/games":
+			_ = json.NewEncoder(w).Encode(Response[Game]{Data: []Game{{ID: "509658", Name: "Just Chatting"}}})
+		case r.URL.Path == "/content_classification_labels":
+			_ = json.NewEncoder(w).Encode(Response[ContentClassificationLabel]{Data: []ContentClassificationLabel{{ID: "Gambling"}, {ID: "ProfanityVulgarity"}, {ID: "MatureGame"}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	profile, err := ParseChannelProfile(strings.NewReader(`{
+		"title": "New title",
+		"category": "Just Chatting",
+		"language": "en",
+		"tags": [],
+		"labels": ["ProfanityVulgarity"],
+		"branded_content": true
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := client.ApplyChannelProfile(ctx, "1234", profile, &ChannelProfileOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `~ update channel 1234
+    title: "Old title" -> "New title"
+    category: "Chess" -> "Just Chatting"
+    tags: [English Chess] -> []
+    labels: [Gambling] -> [ProfanityVulgarity]
+    branded_content: false -> true
+`
+	if plan.String() != want || plan.Applied || len(patches) != 0 {
+		t.Errorf("dry run plan =\n%s", plan)
+	}
+
+	plan, err = client.ApplyChannelProfile(ctx, "1234", profile, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !plan.Applied || len(patches) != 1 {
+		t.Fatalf("applied = %v, patches = %v", plan.Applied, patches)
+	}
+	body, _ := json.Marshal(patches[0])
+	if string(body) != `{"content_classification_labels":[{"id":"ProfanityVulgarity","is_enabled":true},{"id":"Gambling","is_enabled":false}],"game_id":"509658","is_branded_content":true,"tags":[],"title":"New title"}` {
+		t.Errorf("patch body = %s", body)
+	}
+
+	// Nothing to change, no request
+	plan, err = client.ApplyChannelProfile(ctx, "1234", &ChannelProfile{Title: "Old title", CategoryID: "743"}, nil)
+	if err != nil || plan.HasChanges() || plan.String() != "no changes\n" || len(patches) != 1 {
+		t.Errorf("plan = %q, err = %v, patches = %d", plan, err, len(patches))
+	}
+
+	// Unknown labels are rejected before anything is sent
+	if _, err := client.ApplyChannelProfile(ctx, "1234", &ChannelProfile{Labels: []string{"Violence"}}, nil); err == nil || !strings.Contains(err.Error(), "Violence") {
+		t.Errorf("err = %v", err)
+	}
+	if len(patches) != 1 {
+		t.Errorf("patches = %d", len(patches))
+	}
+}
+
+func TestChannelProfile_Validate(t *testing.T) {
+	for name, body := range map[string]string{
+		"long title": `{"title": "` + strings.Repeat("x", 141) + `"}`,
+		"bad tag":    `{"tags": ["two words"]}`,
+		"mature":     `{"labels": ["MatureGame"]}`,
+		"bad field":  `{"game": "Chess"}`,
+	} {
+		if _, err := ParseChannelProfile(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
@@ -23,21 +23,19 @@ type Channel struct {
 
 // GetChannelInformationParams contains parameters for GetChannelInformation.
 type GetChannelInformationParams struct {
-	BroadcasterIDs []string // Up to 100 broadcaster IDs
+	BroadcasterIDs []string
 }
 
 // GetChannelInformation gets channel information for one or more users.
+// More than 100 broadcaster IDs are split into concurrent requests, and the
+// ones that don't exist are listed in Response.NotFound.
 func (c *Client) GetChannelInformation(ctx context.Context, params *GetChannelInformationParams) (*Response[Channel], error) {
-	q := url.Values{}
-	for _, id := range params.BroadcasterIDs {
-		q.Add("broadcaster_id", id)
-	}
-
-	var resp Response[Channel]
-	if err := c.get(ctx, "/channels", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return lookup(ctx, c, lookupRequest[Channel]{
+		endpoint: "/channels",
+		params: []lookupParam[Channel]{
+			{name: "broadcaster_id", values: params.BroadcasterIDs, key: func(ch *Channel) string { return ch.BroadcasterID }},
+		},
+	})
 }
 
 // ModifyChannelInformationParams contains parameters for ModifyChannelInformation.
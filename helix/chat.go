@@ -236,17 +236,15 @@ type UserChatColor struct {
 }
 
 // GetUserChatColor gets the chat color for one or more users.
+// More than 100 user IDs are split into concurrent requests, and the ones
+// that don't exist are listed in Response.NotFound.
 func (c *Client) GetUserChatColor(ctx context.Context, userIDs []string) (*Response[UserChatColor], error) {
-	q := url.Values{}
-	for _, id := range userIDs {
-		q.Add("user_id", id)
-	}
-
-	var resp Response[UserChatColor]
-	if err := c.get(ctx, "/chat/color", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return lookup(ctx, c, lookupRequest[UserChatColor]{
+		endpoint: "/chat/color",
+		params: []lookupParam[UserChatColor]{
+			{name: "user_id", values: userIDs, key: func(c *UserChatColor) string { return c.UserID }},
+		},
+	})
 }
 
 // UpdateUserChatColor updates the authenticated user's chat color.
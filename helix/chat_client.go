@@ -4,16 +4,37 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
+)
+
+// ChatBackend selects the transport a ChatBotClient uses.
+type ChatBackend string
+
+// Chat backends
+const (
+	// ChatBackendIRC reads and writes chat over Twitch IRC (the default).
+	ChatBackendIRC ChatBackend = "irc"
+	// ChatBackendEventSub reads chat through EventSub channel.chat.* events and
+	// writes through the Helix Send Chat Message endpoint.
+	ChatBackendEventSub ChatBackend = "eventsub"
 )
 
 // ChatBotClient provides a high-level interface for Twitch chat bots.
-// It wraps IRCClient with convenience methods and automatic token handling.
+// It wraps IRCClient (or EventSubChatClient, see WithChatBotBackend) with
+// convenience methods and automatic token handling.
 type ChatBotClient struct {
 	irc        *IRCClient
+	eventSub   *EventSubChatClient
 	authClient *AuthClient
 	nick       string
 	ircURL     string // custom IRC URL for testing
 
+	// EventSub backend configuration
+	backend     ChatBackend
+	helixClient *Client
+	userID      string
+	eventSubURL string // custom EventSub WebSocket URL for testing
+
 	// Event handlers
 	onMessage    func(*ChatMessage)
 	onSub        func(*UserNotice)
@@ -26,14 +47,49 @@ type ChatBotClient struct {
 	onRoomState  func(*RoomState)
 	onNotice     func(*Notice)
 	onClearChat  func(*ClearChat)
+	onClearMsg   func(*ClearMessage)
 	onWhisper    func(*Whisper)
 	onConnect    func()
 	onDisconnect func()
 	onError      func(error)
 
+	// Typed notice handlers
+	onSubNotice     func(*UserNotice, *SubNotice)
+	onResubNotice   func(*UserNotice, *ResubNotice)
+	onSubGiftNotice func(*UserNotice, *SubGiftNotice)
+	onCommunityGift func(*CommunityGift)
+	onRaidNotice    func(*UserNotice, *RaidNotice)
+	onAnnouncement  func(*UserNotice, *AnnouncementNotice)
+	onBitsBadgeTier func(*UserNotice, *BitsBadgeTierNotice)
+
+	// Community gift aggregation, keyed by channel and community gift ID
+	giftTimeout time.Duration
+	gifts       map[string]*pendingCommunityGift
+	giftMu      sync.Mutex
+
 	mu sync.RWMutex
 }
 
+// CommunityGift is a community gift bomb: the "submysterygift" notice plus
+// the individual gifts that followed it.
+type CommunityGift struct {
+	Notice     *UserNotice          // The submysterygift notice
+	Gift       *CommunityGiftNotice // Typed payload of Notice
+	Recipients []*SubGiftNotice     // Gifts received so far, in arrival order
+	Complete   bool                 // All MassGiftCount gifts arrived before the timeout
+}
+
+// pendingCommunityGift is a community gift waiting for its recipients.
+type pendingCommunityGift struct {
+	gift  *CommunityGift
+	fn    func(*CommunityGift)
+	timer *time.Timer
+}
+
+// defaultCommunityGiftTimeout is how long a community gift waits for its
+// individual gift notices before being delivered incomplete.
+const defaultCommunityGiftTimeout = 5 * time.Second
+
 // ChatBotOption configures the ChatBotClient.
 type ChatBotOption func(*ChatBotClient)
 
@@ -41,8 +97,11 @@ type ChatBotOption func(*ChatBotClient)
 // The nick should be the bot's username, and authClient should have a valid user access token.
 func NewChatBotClient(nick string, authClient *AuthClient, opts ...ChatBotOption) *ChatBotClient {
 	c := &ChatBotClient{
-		authClient: authClient,
-		nick:       nick,
+		authClient:  authClient,
+		nick:        nick,
+		backend:     ChatBackendIRC,
+		giftTimeout: defaultCommunityGiftTimeout,
+		gifts:       make(map[string]*pendingCommunityGift),
 	}
 
 	for _, opt := range opts {
@@ -59,6 +118,49 @@ func WithChatBotURL(url string) ChatBotOption {
 	}
 }
 
+// WithChatBotBackend selects the chat transport. ChatBackendEventSub requires
+// WithChatBotHelixClient. Handlers receive the same types on either backend,
+// but EventSub has no equivalent of IRC JOIN/PART, so OnJoin and OnPart are
+// never called, and incoming whispers are not delivered.
+func WithChatBotBackend(backend ChatBackend) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.backend = backend
+	}
+}
+
+// WithChatBotHelixClient sets the Helix client used by the EventSub backend.
+// Its token must be a user access token for the bot with the user:read:chat
+// and user:write:chat scopes.
+func WithChatBotHelixClient(client *Client) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.helixClient = client
+	}
+}
+
+// WithChatBotUserID sets the bot's user ID for the EventSub backend. If not
+// set, it is looked up from the Helix client's token on Connect.
+func WithChatBotUserID(userID string) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.userID = userID
+	}
+}
+
+// WithChatBotEventSubURL sets a custom EventSub WebSocket URL (for testing).
+func WithChatBotEventSubURL(url string) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.eventSubURL = url
+	}
+}
+
+// WithChatBotCommunityGiftTimeout sets how long OnCommunityGift waits for the
+// individual gifts of a community gift before delivering it incomplete
+// (default 5 seconds).
+func WithChatBotCommunityGiftTimeout(d time.Duration) ChatBotOption {
+	return func(c *ChatBotClient) {
+		c.giftTimeout = d
+	}
+}
+
 // OnMessage sets the handler for all chat messages.
 func (c *ChatBotClient) OnMessage(fn func(*ChatMessage)) {
 	c.mu.Lock()
@@ -94,6 +196,59 @@ func (c *ChatBotClient) OnRaid(fn func(*UserNotice)) {
 	c.onRaid = fn
 }
 
+// OnSubNotice sets a typed handler for new subscription events.
+func (c *ChatBotClient) OnSubNotice(fn func(*UserNotice, *SubNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSubNotice = fn
+}
+
+// OnResubNotice sets a typed handler for resubscription events.
+func (c *ChatBotClient) OnResubNotice(fn func(*UserNotice, *ResubNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onResubNotice = fn
+}
+
+// OnSubGiftNotice sets a typed handler for single gift subscriptions.
+// When OnCommunityGift is set, gifts that are part of a community gift are
+// delivered there instead.
+func (c *ChatBotClient) OnSubGiftNotice(fn func(*UserNotice, *SubGiftNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSubGiftNotice = fn
+}
+
+// OnCommunityGift sets the handler for community gift bombs. The handler is
+// called once per bomb, after all of its gifts have arrived or the community
+// gift timeout has passed.
+func (c *ChatBotClient) OnCommunityGift(fn func(*CommunityGift)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onCommunityGift = fn
+}
+
+// OnRaidNotice sets a typed handler for raid events.
+func (c *ChatBotClient) OnRaidNotice(fn func(*UserNotice, *RaidNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRaidNotice = fn
+}
+
+// OnAnnouncement sets the handler for announcements.
+func (c *ChatBotClient) OnAnnouncement(fn func(*UserNotice, *AnnouncementNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onAnnouncement = fn
+}
+
+// OnBitsBadgeTier sets the handler for bits badge tier unlocks.
+func (c *ChatBotClient) OnBitsBadgeTier(fn func(*UserNotice, *BitsBadgeTierNotice)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onBitsBadgeTier = fn
+}
+
 // OnCheer sets the handler for cheer (bits) messages.
 func (c *ChatBotClient) OnCheer(fn func(*ChatMessage)) {
 	c.mu.Lock()
@@ -136,6 +291,13 @@ func (c *ChatBotClient) OnClearChat(fn func(*ClearChat)) {
 	c.onClearChat = fn
 }
 
+// OnClearMessage sets the handler for single message deletions.
+func (c *ChatBotClient) OnClearMessage(fn func(*ClearMessage)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onClearMsg = fn
+}
+
 // OnWhisper sets the handler for whisper messages.
 func (c *ChatBotClient) OnWhisper(fn func(*Whisper)) {
 	c.mu.Lock()
@@ -166,6 +328,10 @@ func (c *ChatBotClient) OnError(fn func(error)) {
 
 // Connect establishes a connection to Twitch chat.
 func (c *ChatBotClient) Connect(ctx context.Context) error {
+	if c.backend == ChatBackendEventSub {
+		return c.connectEventSub(ctx)
+	}
+
 	token := ""
 	if c.authClient != nil {
 		if t := c.authClient.GetToken(); t != nil {
@@ -185,6 +351,7 @@ func (c *ChatBotClient) Connect(ctx context.Context) error {
 		WithRoomStateHandler(c.handleRoomState),
 		WithNoticeHandler(c.handleNotice),
 		WithClearChatHandler(c.handleClearChat),
+		WithClearMessageHandler(c.handleClearMessage),
 		WithWhisperHandler(c.handleWhisper),
 		WithConnectHandler(c.handleConnect),
 		WithDisconnectHandler(c.handleDisconnect),
@@ -202,8 +369,46 @@ func (c *ChatBotClient) Connect(ctx context.Context) error {
 	return c.irc.Connect(ctx)
 }
 
-// Close closes the chat connection.
+// connectEventSub connects using the EventSub backend.
+func (c *ChatBotClient) connectEventSub(ctx context.Context) error {
+	if c.helixClient == nil {
+		return errors.New("chatbot: eventsub backend requires a helix client")
+	}
+
+	esOpts := []EventSubChatOption{
+		WithEventSubChatMessageHandler(c.handleMessage),
+		WithEventSubChatUserNoticeHandler(c.handleUserNotice),
+		WithEventSubChatRoomStateHandler(c.handleRoomState),
+		WithEventSubChatClearChatHandler(c.handleClearChat),
+		WithEventSubChatClearMessageHandler(c.handleClearMessage),
+		WithEventSubChatConnectHandler(c.handleConnect),
+		WithEventSubChatDisconnectHandler(c.handleDisconnect),
+		WithEventSubChatErrorHandler(c.handleError),
+	}
+	if c.userID != "" {
+		esOpts = append(esOpts, WithEventSubChatUserID(c.userID))
+	}
+	if c.eventSubURL != "" {
+		esOpts = append(esOpts, WithEventSubChatURL(c.eventSubURL))
+	}
+	c.eventSub = NewEventSubChatClient(c.helixClient, esOpts...)
+
+	return c.eventSub.Connect(ctx)
+}
+
+// Close closes the chat connection. Community gifts still being collected
+// are dropped.
 func (c *ChatBotClient) Close() error {
+	c.giftMu.Lock()
+	for key, pending := range c.gifts {
+		pending.timer.Stop()
+		delete(c.gifts, key)
+	}
+	c.giftMu.Unlock()
+
+	if c.eventSub != nil {
+		return c.eventSub.Close()
+	}
 	if c.irc != nil {
 		return c.irc.Close()
 	}
@@ -212,6 +417,9 @@ func (c *ChatBotClient) Close() error {
 
 // IsConnected returns whether the client is connected.
 func (c *ChatBotClient) IsConnected() bool {
+	if c.eventSub != nil {
+		return c.eventSub.IsConnected()
+	}
 	if c.irc == nil {
 		return false
 	}
@@ -220,6 +428,9 @@ func (c *ChatBotClient) IsConnected() bool {
 
 // Join joins one or more channels.
 func (c *ChatBotClient) Join(channels ...string) error {
+	if c.eventSub != nil {
+		return c.eventSub.Join(context.Background(), channels...)
+	}
 	if c.irc == nil {
 		return ErrIRCNotConnected
 	}
@@ -228,6 +439,9 @@ func (c *ChatBotClient) Join(channels ...string) error {
 
 // Part leaves one or more channels.
 func (c *ChatBotClient) Part(channels ...string) error {
+	if c.eventSub != nil {
+		return c.eventSub.Part(context.Background(), channels...)
+	}
 	if c.irc == nil {
 		return ErrIRCNotConnected
 	}
@@ -236,6 +450,9 @@ func (c *ChatBotClient) Part(channels ...string) error {
 
 // Say sends a message to a channel.
 func (c *ChatBotClient) Say(channel, message string) error {
+	if c.eventSub != nil {
+		return c.eventSub.Say(context.Background(), channel, message)
+	}
 	if c.irc == nil {
 		return ErrIRCNotConnected
 	}
@@ -244,6 +461,9 @@ func (c *ChatBotClient) Say(channel, message string) error {
 
 // Reply sends a reply to a specific message.
 func (c *ChatBotClient) Reply(channel, parentMsgID, message string) error {
+	if c.eventSub != nil {
+		return c.eventSub.Reply(context.Background(), channel, parentMsgID, message)
+	}
 	if c.irc == nil {
 		return ErrIRCNotConnected
 	}
@@ -252,6 +472,9 @@ func (c *ChatBotClient) Reply(channel, parentMsgID, message string) error {
 
 // Whisper sends a whisper to a user.
 func (c *ChatBotClient) Whisper(user, message string) error {
+	if c.eventSub != nil {
+		return c.eventSub.Whisper(context.Background(), user, message)
+	}
 	if c.irc == nil {
 		return ErrIRCNotConnected
 	}
@@ -260,17 +483,42 @@ func (c *ChatBotClient) Whisper(user, message string) error {
 
 // GetJoinedChannels returns the list of joined channels.
 func (c *ChatBotClient) GetJoinedChannels() []string {
+	if c.eventSub != nil {
+		return c.eventSub.GetJoinedChannels()
+	}
 	if c.irc == nil {
 		return nil
 	}
 	return c.irc.GetJoinedChannels()
 }
 
+// Backend returns the configured chat backend.
+func (c *ChatBotClient) Backend() ChatBackend {
+	return c.backend
+}
+
+// ChannelState returns the tracked state of a joined channel (room modes and
+// the bot's own mod/VIP status), or nil if unavailable. State is only tracked
+// on the IRC backend.
+func (c *ChatBotClient) ChannelState(channel string) *ChannelState {
+	if c.irc == nil {
+		return nil
+	}
+	return c.irc.ChannelState(channel)
+}
+
 // IRC returns the underlying IRC client for advanced usage.
+// Returns nil when using the EventSub backend.
 func (c *ChatBotClient) IRC() *IRCClient {
 	return c.irc
 }
 
+// EventSub returns the underlying EventSub chat client for advanced usage.
+// Returns nil when using the IRC backend.
+func (c *ChatBotClient) EventSub() *EventSubChatClient {
+	return c.eventSub
+}
+
 // Internal handlers
 
 func (c *ChatBotClient) handleMessage(msg *ChatMessage) {
@@ -306,7 +554,7 @@ func (c *ChatBotClient) handleUserNotice(notice *UserNotice) {
 		if onResub != nil {
 			onResub(notice)
 		}
-	case UserNoticeTypeSubGift, UserNoticeTypeAnonSubGift, UserNoticeTypeSubMysteryGift:
+	case UserNoticeTypeSubGift, UserNoticeTypeAnonSubGift, UserNoticeTypeSubMysteryGift, UserNoticeTypeAnonSubMysteryGift:
 		if onSubGift != nil {
 			onSubGift(notice)
 		}
@@ -315,6 +563,120 @@ func (c *ChatBotClient) handleUserNotice(notice *UserNotice) {
 			onRaid(notice)
 		}
 	}
+
+	c.handleTypedUserNotice(notice)
+}
+
+func (c *ChatBotClient) handleTypedUserNotice(notice *UserNotice) {
+	c.mu.RLock()
+	onSubNotice := c.onSubNotice
+	onResubNotice := c.onResubNotice
+	onSubGiftNotice := c.onSubGiftNotice
+	onCommunityGift := c.onCommunityGift
+	onRaidNotice := c.onRaidNotice
+	onAnnouncement := c.onAnnouncement
+	onBitsBadgeTier := c.onBitsBadgeTier
+	c.mu.RUnlock()
+
+	switch data := notice.Data.(type) {
+	case *SubNotice:
+		if onSubNotice != nil {
+			onSubNotice(notice, data)
+		}
+	case *ResubNotice:
+		if onResubNotice != nil {
+			onResubNotice(notice, data)
+		}
+	case *SubGiftNotice:
+		if onCommunityGift != nil && c.addCommunityGiftRecipient(notice.Channel, data) {
+			return
+		}
+		if onSubGiftNotice != nil {
+			onSubGiftNotice(notice, data)
+		}
+	case *CommunityGiftNotice:
+		if onCommunityGift != nil {
+			c.startCommunityGift(notice, data, onCommunityGift)
+		}
+	case *RaidNotice:
+		if onRaidNotice != nil {
+			onRaidNotice(notice, data)
+		}
+	case *AnnouncementNotice:
+		if onAnnouncement != nil {
+			onAnnouncement(notice, data)
+		}
+	case *BitsBadgeTierNotice:
+		if onBitsBadgeTier != nil {
+			onBitsBadgeTier(notice, data)
+		}
+	}
+}
+
+// startCommunityGift begins collecting the gifts of a community gift bomb.
+func (c *ChatBotClient) startCommunityGift(notice *UserNotice, gift *CommunityGiftNotice, fn func(*CommunityGift)) {
+	cg := &CommunityGift{Notice: notice, Gift: gift}
+	if gift.CommunityGiftID == "" || gift.MassGiftCount <= 0 {
+		cg.Complete = gift.MassGiftCount <= 0
+		fn(cg)
+		return
+	}
+
+	key := notice.Channel + "/" + gift.CommunityGiftID
+	pending := &pendingCommunityGift{gift: cg, fn: fn}
+
+	timeout := c.giftTimeout
+	if timeout <= 0 {
+		timeout = defaultCommunityGiftTimeout
+	}
+
+	c.giftMu.Lock()
+	if c.gifts == nil {
+		c.gifts = make(map[string]*pendingCommunityGift)
+	}
+	pending.timer = time.AfterFunc(timeout, func() { c.finishCommunityGift(key, pending) })
+	c.gifts[key] = pending
+	c.giftMu.Unlock()
+}
+
+// addCommunityGiftRecipient adds a gift to its pending community gift.
+// It returns false if the gift is not part of a community gift being collected.
+func (c *ChatBotClient) addCommunityGiftRecipient(channel string, gift *SubGiftNotice) bool {
+	if gift.CommunityGiftID == "" {
+		return false
+	}
+	key := channel + "/" + gift.CommunityGiftID
+
+	c.giftMu.Lock()
+	pending, ok := c.gifts[key]
+	if !ok {
+		c.giftMu.Unlock()
+		return false
+	}
+	pending.gift.Recipients = append(pending.gift.Recipients, gift)
+	done := len(pending.gift.Recipients) >= pending.gift.Gift.MassGiftCount
+	c.giftMu.Unlock()
+
+	if done {
+		pending.timer.Stop()
+		c.finishCommunityGift(key, pending)
+	}
+	return true
+}
+
+// finishCommunityGift delivers a community gift once, either when complete
+// or when its timeout fires.
+func (c *ChatBotClient) finishCommunityGift(key string, pending *pendingCommunityGift) {
+	c.giftMu.Lock()
+	if c.gifts[key] != pending {
+		c.giftMu.Unlock()
+		return // already delivered
+	}
+	delete(c.gifts, key)
+	pending.gift.Complete = len(pending.gift.Recipients) >= pending.gift.Gift.MassGiftCount
+	c.giftMu.Unlock()
+
+	pending.fn(pending.gift)
 }
 
 func (c *ChatBotClient) handleJoin(channel, user string) {
@@ -367,6 +729,16 @@ func (c *ChatBotClient) handleClearChat(clear *ClearChat) {
 	}
 }
 
+func (c *ChatBotClient) handleClearMessage(msg *ClearMessage) {
+	c.mu.RLock()
+	fn := c.onClearMsg
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(msg)
+	}
+}
+
 func (c *ChatBotClient) handleWhisper(whisper *Whisper) {
 	c.mu.RLock()
 	fn := c.onWhisper
@@ -961,3 +961,119 @@ func TestChatBotClient_OperationsWithMockConnection(t *testing.T) {
 		t.Error("GetJoinedChannels returned nil")
 	}
 }
+
+func TestChatBotClient_TypedNoticeHandlers(t *testing.T) {
+	client := NewChatBotClient("justinfan12345", nil)
+
+	var sub *SubNotice
+	var resub *ResubNotice
+	var raid *RaidNotice
+	var announcement *AnnouncementNotice
+	var bits *BitsBadgeTierNotice
+	client.OnSubNotice(func(_ *UserNotice, n *SubNotice) { sub = n })
+	client.OnResubNotice(func(_ *UserNotice, n *ResubNotice) { resub = n })
+	client.OnRaidNotice(func(_ *UserNotice, n *RaidNotice) { raid = n })
+	client.OnAnnouncement(func(_ *UserNotice, n *AnnouncementNotice) { announcement = n })
+	client.OnBitsBadgeTier(func(_ *UserNotice, n *BitsBadgeTierNotice) { bits = n })
+
+	for _, raw := range []string{
+		"@login=a;msg-id=sub;msg-param-sub-plan=1000;msg-param-cumulative-months=1 :tmi.twitch.tv USERNOTICE #dallas",
+		"@login=a;msg-id=resub;msg-param-sub-plan=1000;msg-param-cumulative-months=7 :tmi.twitch.tv USERNOTICE #dallas",
+		"@login=a;msg-id=raid;msg-param-login=a;msg-param-viewerCount=50 :tmi.twitch.tv USERNOTICE #dallas",
+		"@login=a;msg-id=announcement;msg-param-color=BLUE :tmi.twitch.tv USERNOTICE #dallas :hi",
+		"@login=a;msg-id=bitsbadgetier;msg-param-threshold=1000 :tmi.twitch.tv USERNOTICE #dallas",
+	} {
+		client.handleUserNotice(parseUserNotice(parseIRCMessage(raw)))
+	}
+
+	if sub == nil || sub.CumulativeMonths != 1 {
+		t.Errorf("OnSubNotice got %+v", sub)
+	}
+	if resub == nil || resub.CumulativeMonths != 7 {
+		t.Errorf("OnResubNotice got %+v", resub)
+	}
+	if raid == nil || raid.ViewerCount != 50 {
+		t.Errorf("OnRaidNotice got %+v", raid)
+	}
+	if announcement == nil || announcement.Color != "BLUE" {
+		t.Errorf("OnAnnouncement got %+v", announcement)
+	}
+	if bits == nil || bits.Threshold != 1000 {
+		t.Errorf("OnBitsBadgeTier got %+v", bits)
+	}
+}
+
+func TestChatBotClient_OnCommunityGift(t *testing.T) {
+	client := NewChatBotClient("justinfan12345", nil)
+
+	var gifts []*CommunityGift
+	var single []*SubGiftNotice
+	var legacy int
+	client.OnCommunityGift(func(g *CommunityGift) { gifts = append(gifts, g) })
+	client.OnSubGiftNotice(func(_ *UserNotice, n *SubGiftNotice) { single = append(single, n) })
+	client.OnSubGift(func(*UserNotice) { legacy++ })
+
+	client.handleUserNotice(parseUserNotice(parseIRCMessage(
+		"@login=gifter;msg-id=submysterygift;msg-param-mass-gift-count=2;msg-param-community-gift-id=abc;msg-param-sub-plan=1000 :tmi.twitch.tv USERNOTICE #dallas")))
+	for _, recipient := range []string{"r1", "r2"} {
+		client.handleUserNotice(parseUserNotice(parseIRCMessage(
+			"@login=gifter;msg-id=subgift;msg-param-community-gift-id=abc;msg-param-recipient-user-name=" + recipient + ";msg-param-sub-plan=1000 :tmi.twitch.tv USERNOTICE #dallas")))
+	}
+	// A direct gift is not aggregated
+	client.handleUserNotice(parseUserNotice(parseIRCMessage(
+		"@login=gifter;msg-id=subgift;msg-param-recipient-user-name=r3;msg-param-sub-plan=1000 :tmi.twitch.tv USERNOTICE #dallas")))
+
+	if len(gifts) != 1 {
+		t.Fatalf("OnCommunityGift called %d times, want 1", len(gifts))
+	}
+	g := gifts[0]
+	if !g.Complete || len(g.Recipients) != 2 || g.Recipients[1].RecipientLogin != "r2" || g.Gift.MassGiftCount != 2 {
+		t.Errorf("CommunityGift = %+v", g)
+	}
+	if len(single) != 1 || single[0].RecipientLogin != "r3" {
+		t.Errorf("OnSubGiftNotice got %d gifts", len(single))
+	}
+	if legacy != 4 {
+		t.Errorf("OnSubGift called %d times, want 4", legacy)
+	}
+}
+
+func TestChatBotClient_OnCommunityGift_Timeout(t *testing.T) {
+	client := NewChatBotClient("justinfan12345", nil, WithChatBotCommunityGiftTimeout(20*time.Millisecond))
+
+	done := make(chan *CommunityGift, 1)
+	client.OnCommunityGift(func(g *CommunityGift) { done <- g })
+
+	client.handleUserNotice(parseUserNotice(parseIRCMessage(
+		"@login=gifter;msg-id=submysterygift;msg-param-mass-gift-count=3;msg-param-community-gift-id=xyz :tmi.twitch.tv USERNOTICE #dallas")))
+	client.handleUserNotice(parseUserNotice(parseIRCMessage(
+		"@login=gifter;msg-id=subgift;msg-param-community-gift-id=xyz;msg-param-recipient-user-name=r1 :tmi.twitch.tv USERNOTICE #dallas")))
+
+	select {
+	case g := <-done:
+		if g.Complete || len(g.Recipients) != 1 {
+			t.Errorf("CommunityGift = %+v, want incomplete with 1 recipient", g)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnCommunityGift not called after timeout")
+	}
+}
+
+func TestChatBotClient_Close_DropsPendingCommunityGifts(t *testing.T) {
+	client := NewChatBotClient("justinfan12345", nil, WithChatBotCommunityGiftTimeout(20*time.Millisecond))
+
+	done := make(chan *CommunityGift, 1)
+	client.OnCommunityGift(func(g *CommunityGift) { done <- g })
+
+	client.handleUserNotice(parseUserNotice(parseIRCMessage(
+		"@login=gifter;msg-id=submysterygift;msg-param-mass-gift-count=3;msg-param-community-gift-id=xyz :tmi.twitch.tv USERNOTICE #dallas")))
+	if err := client.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case g := <-done:
+		t.Errorf("OnCommunityGift called after Close: %+v", g)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,1037 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// eventSubChatTypes are the subscriptions created for every joined channel.
+var eventSubChatTypes = []string{
+	EventSubTypeChannelChatMessage,
+	EventSubTypeChannelChatNotification,
+	EventSubTypeChannelChatClear,
+	EventSubTypeChannelChatClearUserMessages,
+	EventSubTypeChannelChatMessageDelete,
+	EventSubTypeChannelChatSettingsUpdate,
+}
+
+// ChatMessageDroppedError is returned when Twitch accepts a Send Chat Message
+// request but does not deliver the message (is_sent is false), for example
+// because AutoMod held it or the channel is in followers-only mode.
+type ChatMessageDroppedError struct {
+	Code    string
+	Message string
+}
+
+func (e *ChatMessageDroppedError) Error() string {
+	return fmt.Sprintf("chat message dropped: %s - %s", e.Code, e.Message)
+}
+
+// EventSubChatClient reads chat through EventSub (channel.chat.* over WebSocket)
+// and writes through the Helix Send Chat Message endpoint, which is the
+// transport Twitch recommends over IRC.
+//
+// Events are converted to the same types IRCClient produces (ChatMessage,
+// UserNotice, RoomState, ClearChat, ClearMessage), so handlers can be shared
+// between the two clients. EventSub has no membership events, so there is no
+// equivalent of IRC JOIN/PART notifications.
+type EventSubChatClient struct {
+	client *Client
+	userID string // chatting user; also the user_id of every subscription
+	wsURL  string // custom EventSub WebSocket URL for testing
+	ws     *EventSubWebSocket
+
+	// Channel tracking, keyed by login
+	channels map[string]*eventSubChatChannel
+	logins   map[string]string // broadcaster ID -> login
+
+	// Handlers
+	onMessage      func(*ChatMessage)
+	onUserNotice   func(*UserNotice)
+	onRoomState    func(*RoomState)
+	onClearChat    func(*ClearChat)
+	onClearMessage func(*ClearMessage)
+	onConnect      func()
+	onDisconnect   func()
+	onError        func(error)
+
+	// Reconnection after the session drops
+	autoReconnect  bool
+	reconnectDelay time.Duration
+	stop           chan struct{} // closed by Close
+
+	mu sync.RWMutex
+}
+
+// eventSubChatChannel tracks the subscriptions created for a joined channel.
+type eventSubChatChannel struct {
+	login           string
+	broadcasterID   string
+	subscriptionIDs []string
+}
+
+// EventSubChatOption configures the EventSubChatClient.
+type EventSubChatOption func(*EventSubChatClient)
+
+// WithEventSubChatUserID sets the ID of the chatting user. If not set, it is
+// looked up from the client's user access token on Connect.
+func WithEventSubChatUserID(userID string) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.userID = userID
+	}
+}
+
+// WithEventSubChatURL sets a custom EventSub WebSocket URL (for testing).
+func WithEventSubChatURL(url string) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.wsURL = url
+	}
+}
+
+// WithEventSubChatMessageHandler sets the handler for chat messages.
+func WithEventSubChatMessageHandler(fn func(*ChatMessage)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onMessage = fn
+	}
+}
+
+// WithEventSubChatUserNoticeHandler sets the handler for chat notifications
+// (subs, gifts, raids, announcements, etc.).
+func WithEventSubChatUserNoticeHandler(fn func(*UserNotice)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onUserNotice = fn
+	}
+}
+
+// WithEventSubChatRoomStateHandler sets the handler for chat settings updates.
+func WithEventSubChatRoomStateHandler(fn func(*RoomState)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onRoomState = fn
+	}
+}
+
+// WithEventSubChatClearChatHandler sets the handler for chat clears and
+// user message clears (bans and timeouts).
+func WithEventSubChatClearChatHandler(fn func(*ClearChat)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onClearChat = fn
+	}
+}
+
+// WithEventSubChatClearMessageHandler sets the handler for single message deletions.
+func WithEventSubChatClearMessageHandler(fn func(*ClearMessage)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onClearMessage = fn
+	}
+}
+
+// WithEventSubChatConnectHandler sets the handler for successful connections.
+func WithEventSubChatConnectHandler(fn func()) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onConnect = fn
+	}
+}
+
+// WithEventSubChatDisconnectHandler sets the handler for disconnections.
+func WithEventSubChatDisconnectHandler(fn func()) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onDisconnect = fn
+	}
+}
+
+// WithEventSubChatAutoReconnect enables or disables reconnecting, and
+// resubscribing every joined channel, after the session drops.
+func WithEventSubChatAutoReconnect(enabled bool) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.autoReconnect = enabled
+	}
+}
+
+// WithEventSubChatReconnectDelay sets the delay between reconnection attempts.
+func WithEventSubChatReconnectDelay(d time.Duration) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.reconnectDelay = d
+	}
+}
+
+// WithEventSubChatErrorHandler sets the handler for errors.
+func WithEventSubChatErrorHandler(fn func(error)) EventSubChatOption {
+	return func(c *EventSubChatClient) {
+		c.onError = fn
+	}
+}
+
+// NewEventSubChatClient creates a new EventSub-based chat client.
+// The Helix client must carry a user access token with the user:read:chat
+// and user:write:chat scopes. Returns nil if helixClient is nil.
+func NewEventSubChatClient(helixClient *Client, opts ...EventSubChatOption) *EventSubChatClient {
+	if helixClient == nil {
+		return nil
+	}
+
+	c := &EventSubChatClient{
+		client:         helixClient,
+		channels:       make(map[string]*eventSubChatChannel),
+		logins:         make(map[string]string),
+		autoReconnect:  true,
+		reconnectDelay: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Connect opens the EventSub WebSocket session and subscribes to every channel
+// joined so far. If already connected, the existing session is closed first.
+// If the session drops later, the client reconnects and resubscribes unless
+// auto-reconnect is disabled.
+func (c *EventSubChatClient) Connect(ctx context.Context) error {
+	// Detach the old session before closing it, so its drop isn't handled
+	c.mu.Lock()
+	userID := c.userID
+	old := c.ws
+	c.ws = nil
+	c.mu.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+
+	if userID == "" {
+		user, err := c.client.GetCurrentUser(ctx)
+		if err != nil {
+			return fmt.Errorf("eventsub chat: resolving user: %w", err)
+		}
+		if user == nil {
+			return errors.New("eventsub chat: token is not associated with a user")
+		}
+		c.mu.Lock()
+		c.userID = user.ID
+		c.mu.Unlock()
+	}
+
+	wsOpts := []EventSubWebSocketOption{
+		WithEventSubErrorHandler(c.handleError),
+	}
+	if c.wsURL != "" {
+		wsOpts = append(wsOpts, WithEventSubWSURL(c.wsURL))
+	}
+	ws := NewEventSubWebSocket(c.client, wsOpts...)
+	ws.onSubscriptionRevoked = c.handleRevocation
+	ws.onDisconnect = func(err error) { c.handleDrop(ws, err) }
+	if err := ws.Connect(ctx); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.ws = ws
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+	}
+	pending := make([]*eventSubChatChannel, 0, len(c.channels))
+	for _, ch := range c.channels {
+		ch.subscriptionIDs = nil // subscriptions do not survive a new session
+		pending = append(pending, ch)
+	}
+	c.mu.Unlock()
+
+	if err := c.subscribeChannels(ctx, pending); err != nil {
+		c.handleError(fmt.Errorf("eventsub chat: rejoining channels: %w", err))
+	}
+
+	c.mu.RLock()
+	onConnect := c.onConnect
+	c.mu.RUnlock()
+	if onConnect != nil {
+		onConnect()
+	}
+
+	return nil
+}
+
+// Close closes the EventSub session. WebSocket subscriptions are removed by
+// Twitch when the session ends.
+func (c *EventSubChatClient) Close() error {
+	c.mu.Lock()
+	ws := c.ws
+	c.ws = nil
+	for _, ch := range c.channels {
+		ch.subscriptionIDs = nil
+	}
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	onDisconnect := c.onDisconnect
+	c.mu.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+	err := ws.Close()
+	if onDisconnect != nil {
+		onDisconnect()
+	}
+	return err
+}
+
+// handleRevocation drops a revoked subscription from its channel, for
+// example after the chatting user is banned there. The other channels keep
+// receiving events.
+func (c *EventSubChatClient) handleRevocation(sub *EventSubSubscription) {
+	channel := sub.Condition["broadcaster_user_id"]
+	c.mu.Lock()
+	for _, ch := range c.channels {
+		if i := slices.Index(ch.subscriptionIDs, sub.ID); i >= 0 {
+			ch.subscriptionIDs = slices.Delete(ch.subscriptionIDs, i, i+1)
+			channel = ch.login
+			break
+		}
+	}
+	c.mu.Unlock()
+	c.handleError(fmt.Errorf("eventsub chat: %s subscription for %s revoked: %s", sub.Type, channel, sub.Status))
+}
+
+// handleDrop reports a dropped session and starts reconnecting.
+func (c *EventSubChatClient) handleDrop(ws *EventSubWebSocket, err error) {
+	c.mu.Lock()
+	if c.ws != ws {
+		c.mu.Unlock()
+		return
+	}
+	c.ws = nil
+	for _, ch := range c.channels {
+		ch.subscriptionIDs = nil
+	}
+	stop := c.stop
+	autoReconnect := c.autoReconnect
+	onDisconnect := c.onDisconnect
+	c.mu.Unlock()
+
+	c.handleError(fmt.Errorf("eventsub chat: connection lost: %w", err))
+	if onDisconnect != nil {
+		onDisconnect()
+	}
+	if autoReconnect && stop != nil {
+		go c.reconnect(stop)
+	}
+}
+
+// reconnect opens a new session, which resubscribes every joined channel,
+// retrying until it succeeds or the client is closed.
+func (c *EventSubChatClient) reconnect(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(c.reconnectDelay):
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		err := c.Connect(ctx)
+		cancel()
+		if err == nil {
+			select {
+			case <-stop:
+				// Closed while reconnecting
+				_ = c.Close()
+			default:
+			}
+			return
+		}
+		c.handleError(fmt.Errorf("eventsub chat: reconnect failed: %w", err))
+	}
+}
+
+// IsConnected returns whether the EventSub session is open.
+func (c *EventSubChatClient) IsConnected() bool {
+	c.mu.RLock()
+	ws := c.ws
+	c.mu.RUnlock()
+	return ws != nil && ws.IsConnected()
+}
+
+// UserID returns the ID of the chatting user.
+func (c *EventSubChatClient) UserID() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.userID
+}
+
+// Join subscribes to the chat events of one or more channels, given by login.
+// If the client is not connected yet, the channels are subscribed on Connect.
+// Channels that fail to subscribe are not joined, so Join can be retried.
+func (c *EventSubChatClient) Join(ctx context.Context, channels ...string) error {
+	var added []*eventSubChatChannel
+	c.mu.Lock()
+	for _, name := range channels {
+		login := normalizeChannelName(name)
+		if login == "" {
+			continue
+		}
+		if _, ok := c.channels[login]; ok {
+			continue
+		}
+		ch := &eventSubChatChannel{login: login}
+		c.channels[login] = ch
+		added = append(added, ch)
+	}
+	connected := c.ws != nil
+	c.mu.Unlock()
+
+	if !connected || len(added) == 0 {
+		return nil
+	}
+	err := c.subscribeChannels(ctx, added)
+	if err != nil {
+		c.mu.Lock()
+		for _, ch := range added {
+			if len(ch.subscriptionIDs) > 0 || c.channels[ch.login] != ch {
+				continue
+			}
+			delete(c.channels, ch.login)
+			if ch.broadcasterID != "" {
+				delete(c.logins, ch.broadcasterID)
+			}
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+// Part removes the chat subscriptions of one or more channels.
+func (c *EventSubChatClient) Part(ctx context.Context, channels ...string) error {
+	var removed []*eventSubChatChannel
+	c.mu.Lock()
+	ws := c.ws
+	for _, name := range channels {
+		login := normalizeChannelName(name)
+		ch, ok := c.channels[login]
+		if !ok {
+			continue
+		}
+		delete(c.channels, login)
+		if ch.broadcasterID != "" {
+			delete(c.logins, ch.broadcasterID)
+		}
+		removed = append(removed, ch)
+	}
+	c.mu.Unlock()
+
+	if ws == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, ch := range removed {
+		for _, id := range ch.subscriptionIDs {
+			if err := ws.Unsubscribe(ctx, id); err != nil {
+				errs = append(errs, fmt.Errorf("parting %s: %w", ch.login, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetJoinedChannels returns the logins of the joined channels.
+func (c *EventSubChatClient) GetJoinedChannels() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	channels := make([]string, 0, len(c.channels))
+	for login := range c.channels {
+		channels = append(channels, login)
+	}
+	return channels
+}
+
+// Say sends a message to a channel.
+func (c *EventSubChatClient) Say(ctx context.Context, channel, message string) error {
+	return c.send(ctx, channel, "", message)
+}
+
+// Reply sends a reply to a specific message.
+func (c *EventSubChatClient) Reply(ctx context.Context, channel, parentMsgID, message string) error {
+	return c.send(ctx, channel, parentMsgID, message)
+}
+
+// Whisper sends a whisper to a user, given by login.
+// Requires: user:manage:whispers scope.
+func (c *EventSubChatClient) Whisper(ctx context.Context, user, message string) error {
+	toID, err := c.lookupUserID(ctx, normalizeChannelName(user))
+	if err != nil {
+		return err
+	}
+	return c.client.SendWhisper(ctx, &SendWhisperParams{
+		FromUserID: c.UserID(),
+		ToUserID:   toID,
+		Message:    message,
+	})
+}
+
+// send sends a chat message through the Helix API.
+func (c *EventSubChatClient) send(ctx context.Context, channel, parentMsgID, message string) error {
+	broadcasterID, err := c.broadcasterID(ctx, normalizeChannelName(channel))
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.client.SendChatMessage(ctx, &SendChatMessageParams{
+		BroadcasterID:        broadcasterID,
+		SenderID:             c.UserID(),
+		Message:              message,
+		ReplyParentMessageID: parentMsgID,
+	})
+	if err != nil {
+		return err
+	}
+	if resp != nil && !resp.IsSent {
+		dropped := &ChatMessageDroppedError{}
+		if resp.DropReason != nil {
+			dropped.Code = resp.DropReason.Code
+			dropped.Message = resp.DropReason.Message
+		}
+		return dropped
+	}
+	return nil
+}
+
+// broadcasterID returns the ID of a channel, using the joined channel when known.
+func (c *EventSubChatClient) broadcasterID(ctx context.Context, login string) (string, error) {
+	c.mu.RLock()
+	ch, ok := c.channels[login]
+	var id string
+	if ok {
+		id = ch.broadcasterID
+	}
+	c.mu.RUnlock()
+
+	if id != "" {
+		return id, nil
+	}
+	return c.lookupUserID(ctx, login)
+}
+
+// lookupUserID resolves a login to a user ID via Get Users.
+func (c *EventSubChatClient) lookupUserID(ctx context.Context, login string) (string, error) {
+	resp, err := c.client.GetUsers(ctx, &GetUsersParams{Logins: []string{login}})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Data) == 0 {
+		return "", fmt.Errorf("eventsub chat: unknown user %q", login)
+	}
+	return resp.Data[0].ID, nil
+}
+
+// subscribeChannels resolves channel IDs and creates the chat subscriptions.
+func (c *EventSubChatClient) subscribeChannels(ctx context.Context, channels []*eventSubChatChannel) error {
+	if len(channels) == 0 {
+		return nil
+	}
+
+	var unresolved []string
+	c.mu.RLock()
+	for _, ch := range channels {
+		if ch.broadcasterID == "" {
+			unresolved = append(unresolved, ch.login)
+		}
+	}
+	c.mu.RUnlock()
+
+	if len(unresolved) > 0 {
+		resp, err := c.client.GetUsers(ctx, &GetUsersParams{Logins: unresolved})
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		for _, u := range resp.Data {
+			if ch, ok := c.channels[u.Login]; ok {
+				ch.broadcasterID = u.ID
+				c.logins[u.ID] = u.Login
+			}
+		}
+		c.mu.Unlock()
+	}
+
+	var errs []error
+	for _, ch := range channels {
+		if err := c.subscribeChannel(ctx, ch); err != nil {
+			errs = append(errs, fmt.Errorf("joining %s: %w", ch.login, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribeChannel creates every chat subscription for a single channel. If one
+// fails, the subscriptions already created for the channel are removed again.
+func (c *EventSubChatClient) subscribeChannel(ctx context.Context, ch *eventSubChatChannel) error {
+	c.mu.RLock()
+	ws := c.ws
+	userID := c.userID
+	broadcasterID := ch.broadcasterID
+	c.mu.RUnlock()
+
+	if broadcasterID == "" {
+		return fmt.Errorf("eventsub chat: unknown channel %q", ch.login)
+	}
+	if ws == nil {
+		return nil
+	}
+
+	condition := BroadcasterUserCondition(broadcasterID, userID)
+	var ids []string
+	for _, eventType := range eventSubChatTypes {
+		sub, err := ws.CreateSubscription(ctx, eventType, GetEventSubVersion(eventType), condition, c.eventHandler(eventType))
+		if err != nil {
+			for _, id := range ids {
+				_ = ws.Unsubscribe(ctx, id)
+			}
+			return err
+		}
+		if sub != nil {
+			ids = append(ids, sub.ID)
+		}
+	}
+
+	c.mu.Lock()
+	ch.subscriptionIDs = ids
+	c.mu.Unlock()
+	return nil
+}
+
+// eventHandler returns the notification handler for a chat subscription type.
+func (c *EventSubChatClient) eventHandler(eventType string) func(json.RawMessage) {
+	switch eventType {
+	case EventSubTypeChannelChatMessage:
+		return c.handleChatMessage
+	case EventSubTypeChannelChatNotification:
+		return c.handleChatNotification
+	case EventSubTypeChannelChatClear:
+		return c.handleChatClear
+	case EventSubTypeChannelChatClearUserMessages:
+		return c.handleChatClearUserMessages
+	case EventSubTypeChannelChatMessageDelete:
+		return c.handleChatMessageDelete
+	case EventSubTypeChannelChatSettingsUpdate:
+		return c.handleChatSettingsUpdate
+	}
+	return func(json.RawMessage) {}
+}
+
+// Internal handlers
+
+func (c *EventSubChatClient) handleChatMessage(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatMessageEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onMessage
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(chatMessageFromEvent(event, string(data), time.Now()))
+	}
+}
+
+func (c *EventSubChatClient) handleChatNotification(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatNotificationEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onUserNotice
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(userNoticeFromEvent(event, string(data), time.Now()))
+	}
+}
+
+func (c *EventSubChatClient) handleChatClear(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatClearEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onClearChat
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(&ClearChat{
+			Channel:   event.BroadcasterUserLogin,
+			RoomID:    event.BroadcasterUserID,
+			Timestamp: time.Now(),
+			Raw:       string(data),
+		})
+	}
+}
+
+func (c *EventSubChatClient) handleChatClearUserMessages(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatClearUserMessagesEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onClearChat
+	c.mu.RUnlock()
+
+	// channel.chat.clear_user_messages does not say whether the user was
+	// banned or timed out, so BanDuration is left at 0.
+	if fn != nil {
+		fn(&ClearChat{
+			Channel:      event.BroadcasterUserLogin,
+			User:         event.TargetUserLogin,
+			RoomID:       event.BroadcasterUserID,
+			TargetUserID: event.TargetUserID,
+			Timestamp:    time.Now(),
+			Raw:          string(data),
+		})
+	}
+}
+
+func (c *EventSubChatClient) handleChatMessageDelete(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatMessageDeleteEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onClearMessage
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(&ClearMessage{
+			Channel:     event.BroadcasterUserLogin,
+			User:        event.TargetUserLogin,
+			TargetMsgID: event.MessageID,
+			Timestamp:   time.Now(),
+			Raw:         string(data),
+		})
+	}
+}
+
+func (c *EventSubChatClient) handleChatSettingsUpdate(data json.RawMessage) {
+	event, err := ParseWSEvent[ChannelChatSettingsUpdateEvent](data)
+	if err != nil {
+		c.handleError(err)
+		return
+	}
+
+	c.mu.RLock()
+	fn := c.onRoomState
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(roomStateFromEvent(event, string(data)))
+	}
+}
+
+func (c *EventSubChatClient) handleError(err error) {
+	c.mu.RLock()
+	fn := c.onError
+	c.mu.RUnlock()
+
+	if fn != nil {
+		fn(err)
+	}
+}
+
+// normalizeChannelName lowercases a channel name and strips a leading # or @.
+func normalizeChannelName(name string) string {
+	name = strings.TrimSpace(name)
+	name = strings.TrimPrefix(name, "#")
+	name = strings.TrimPrefix(name, "@")
+	return strings.ToLower(name)
+}
+
+// chatMessageFromEvent converts a channel.chat.message event into a ChatMessage.
+func chatMessageFromEvent(event *ChannelChatMessageEvent, raw string, ts time.Time) *ChatMessage {
+	badges, badgeInfo := badgesFromEvent(event.Badges)
+
+	order := make([]string, len(event.Badges))
+	for i, b := range event.Badges {
+		order[i] = b.SetID
+	}
+
+	msg := &ChatMessage{
+		ID:            event.MessageID,
+		Channel:       event.BroadcasterUserLogin,
+		RoomID:        event.BroadcasterUserID,
+		User:          event.ChatterUserLogin,
+		UserID:        event.ChatterUserID,
+		Message:       event.Message.Text,
+		Emotes:        emotesFromFragments(event.Message.Fragments),
+		Badges:        badges,
+		BadgeOrder:    order,
+		BadgeInfo:     badgeInfo,
+		Color:         event.Color,
+		DisplayName:   event.ChatterUserName,
+		IsMod:         badges["moderator"] != "",
+		IsVIP:         badges["vip"] != "",
+		IsSubscriber:  badges["subscriber"] != "" || badges["founder"] != "",
+		IsBroadcaster: badges["broadcaster"] != "",
+		FirstMessage:  event.MessageType == "user_intro",
+		Timestamp:     ts,
+		Raw:           raw,
+	}
+	if event.Cheer != nil {
+		msg.Bits = event.Cheer.Bits
+	}
+	if event.Reply != nil {
+		msg.ReplyParentMsgID = event.Reply.ParentMessageID
+		msg.ReplyParentUserID = event.Reply.ParentUserID
+		msg.ReplyParentUserLogin = event.Reply.ParentUserLogin
+		msg.ReplyParentDisplayName = event.Reply.ParentUserName
+		msg.ReplyParentMsgBody = event.Reply.ParentMessageBody
+	}
+	return msg
+}
+
+// badgesFromEvent converts EventSub badges into the IRC badges and badge-info maps.
+func badgesFromEvent(eventBadges []ChatEventBadge) (badges, badgeInfo map[string]string) {
+	badges = make(map[string]string, len(eventBadges))
+	badgeInfo = make(map[string]string)
+	for _, b := range eventBadges {
+		badges[b.SetID] = b.ID
+		if b.Info != "" {
+			badgeInfo[b.SetID] = b.Info
+		}
+	}
+	return badges, badgeInfo
+}
+
+// emotesFromFragments computes IRC-style emote positions (inclusive rune
+// offsets into the message text) from EventSub message fragments.
+func emotesFromFragments(fragments []ChatEventFragment) []IRCEmote {
+	var emotes []IRCEmote
+	pos := 0
+	for _, f := range fragments {
+		n := utf8.RuneCountInString(f.Text)
+		if f.Type == "emote" && f.Emote != nil && n > 0 {
+			emotes = append(emotes, IRCEmote{
+				ID:    f.Emote.ID,
+				Name:  f.Text,
+				Start: pos,
+				End:   pos + n - 1,
+				Count: 1,
+			})
+		}
+		pos += n
+	}
+	return emotes
+}
+
+// roomStateFromEvent converts a channel.chat_settings.update event into a RoomState.
+func roomStateFromEvent(event *ChannelChatSettingsUpdateEvent, raw string) *RoomState {
+	followersOnly := -1
+	if event.FollowerMode {
+		followersOnly = 0
+		if event.FollowerModeDurationMinutes != nil {
+			followersOnly = *event.FollowerModeDurationMinutes
+		}
+	}
+	slow := 0
+	if event.SlowMode {
+		slow = event.SlowModeWaitTimeSeconds
+	}
+
+	return &RoomState{
+		Channel:       event.BroadcasterUserLogin,
+		EmoteOnly:     event.EmoteMode,
+		FollowersOnly: followersOnly,
+		R9K:           event.UniqueChatMode,
+		Slow:          slow,
+		SubsOnly:      event.SubscriberMode,
+		RoomID:        event.BroadcasterUserID,
+		Raw:           raw,
+	}
+}
+
+// userNoticeFromEvent converts a channel.chat.notification event into a
+// UserNotice. The notice type and MsgParams use the IRC USERNOTICE names
+// (msg-id and msg-param-* without the prefix), so the same handler code works
+// for both transports. Shared chat notices are mapped like their regular
+// counterparts.
+func userNoticeFromEvent(event *ChannelChatNotificationEvent, raw string, ts time.Time) *UserNotice {
+	badges, badgeInfo := badgesFromEvent(event.Badges)
+	params := make(map[string]string)
+	noticeType := strings.TrimPrefix(event.NoticeType, "shared_chat_")
+
+	plan := func(tier string, prime bool) string {
+		if prime {
+			return "Prime"
+		}
+		return tier
+	}
+	setPtr := func(key string, v *string) {
+		if v != nil {
+			params[key] = *v
+		}
+	}
+
+	switch noticeType {
+	case "sub":
+		noticeType = UserNoticeTypeSub
+		if sub := firstNonNil(event.Sub, event.SharedChatSub); sub != nil {
+			params["sub-plan"] = plan(sub.SubTier, sub.IsPrime)
+			params["multimonth-duration"] = strconv.Itoa(sub.DurationMonths)
+			params["cumulative-months"] = "1"
+		}
+	case "resub":
+		noticeType = UserNoticeTypeResub
+		if resub := firstNonNil(event.Resub, event.SharedChatResub); resub != nil {
+			params["sub-plan"] = plan(resub.SubTier, resub.IsPrime)
+			params["cumulative-months"] = strconv.Itoa(resub.CumulativeMonths)
+			params["multimonth-duration"] = strconv.Itoa(resub.DurationMonths)
+			params["streak-months"] = strconv.Itoa(resub.StreakMonths)
+			if resub.StreakMonths > 0 {
+				params["should-share-streak"] = "1"
+			} else {
+				params["should-share-streak"] = "0"
+			}
+			if resub.IsGift {
+				params["was-gifted"] = "true"
+				params["anon-gift"] = strconv.FormatBool(resub.GifterIsAnonymous)
+				setPtr("gifter-id", resub.GifterUserID)
+				setPtr("gifter-login", resub.GifterUserLogin)
+				setPtr("gifter-name", resub.GifterUserName)
+			}
+		}
+	case "sub_gift":
+		noticeType = UserNoticeTypeSubGift
+		if event.ChatterIsAnonymous {
+			noticeType = UserNoticeTypeAnonSubGift
+		}
+		if gift := firstNonNil(event.SubGift, event.SharedChatSubGift); gift != nil {
+			params["sub-plan"] = gift.SubTier
+			params["gift-months"] = strconv.Itoa(gift.DurationMonths)
+			params["recipient-id"] = gift.RecipientUserID
+			params["recipient-user-name"] = gift.RecipientUserLogin
+			params["recipient-display-name"] = gift.RecipientUserName
+			if gift.CumulativeTotal != nil {
+				params["sender-count"] = strconv.Itoa(*gift.CumulativeTotal)
+			}
+			setPtr("community-gift-id", gift.CommunityGiftID)
+		}
+	case "community_sub_gift":
+		noticeType = UserNoticeTypeSubMysteryGift
+		if event.ChatterIsAnonymous {
+			noticeType = UserNoticeTypeAnonSubMysteryGift
+		}
+		if gift := firstNonNil(event.CommunitySubGift, event.SharedChatCommunitySubGift); gift != nil {
+			params["sub-plan"] = gift.SubTier
+			params["mass-gift-count"] = strconv.Itoa(gift.Total)
+			params["community-gift-id"] = gift.ID
+			if gift.CumulativeTotal != nil {
+				params["sender-count"] = strconv.Itoa(*gift.CumulativeTotal)
+			}
+		}
+	case "gift_paid_upgrade":
+		noticeType = UserNoticeTypeGiftPaidUpgrade
+		up := firstNonNil(event.GiftPaidUpgrade, event.SharedChatGiftPaidUpgrade)
+		if event.ChatterIsAnonymous || (up != nil && up.GifterIsAnonymous) {
+			noticeType = UserNoticeTypeAnonGiftPaidUpgrade
+		}
+		if up != nil {
+			setPtr("sender-login", up.GifterUserLogin)
+			setPtr("sender-name", up.GifterUserName)
+		}
+	case "prime_paid_upgrade":
+		noticeType = UserNoticeTypePrimePaidUpgrade
+		if up := firstNonNil(event.PrimePaidUpgrade, event.SharedChatPrimePaidUpgrade); up != nil {
+			params["sub-plan"] = up.SubTier
+		}
+	case "raid":
+		noticeType = UserNoticeTypeRaid
+		if raid := firstNonNil(event.Raid, event.SharedChatRaid); raid != nil {
+			params["login"] = raid.UserLogin
+			params["displayName"] = raid.UserName
+			params["viewerCount"] = strconv.Itoa(raid.ViewerCount)
+			params["profileImageURL"] = raid.ProfileImageURL
+		}
+	case "unraid":
+		noticeType = UserNoticeTypeUnraid
+	case "pay_it_forward":
+		noticeType = UserNoticeTypeStandardPayForward
+		if pf := firstNonNil(event.PayItForward, event.SharedChatPayItForward); pf != nil {
+			params["prior-gifter-anonymous"] = strconv.FormatBool(pf.GifterIsAnonymous)
+			setPtr("prior-gifter-id", pf.GifterUserID)
+			setPtr("prior-gifter-user-name", pf.GifterUserLogin)
+			setPtr("prior-gifter-display-name", pf.GifterUserName)
+		}
+	case "announcement":
+		noticeType = UserNoticeTypeAnnouncement
+		if a := firstNonNil(event.Announcement, event.SharedChatAnnouncement); a != nil {
+			params["color"] = strings.ToUpper(a.Color)
+		}
+	case "bits_badge_tier":
+		noticeType = UserNoticeTypeBitsBadgeTier
+		if event.BitsBadgeTier != nil {
+			params["threshold"] = strconv.Itoa(event.BitsBadgeTier.Tier)
+		}
+	case "charity_donation":
+		noticeType = UserNoticeTypeCharityDonation
+		if event.CharityDonation != nil {
+			params["charity-name"] = event.CharityDonation.CharityName
+			params["donation-amount"] = strconv.Itoa(event.CharityDonation.Amount.Value)
+			params["donation-currency"] = event.CharityDonation.Amount.Currency
+			params["exponent"] = strconv.Itoa(event.CharityDonation.Amount.DecimalPlaces)
+		}
+	case "watch_streak":
+		noticeType = UserNoticeTypeViewerMilestone
+		params["category"] = "watch-streak"
+		if event.WatchStreak != nil {
+			params["value"] = strconv.Itoa(event.WatchStreak.StreakCount)
+			params["copoReward"] = strconv.Itoa(event.WatchStreak.ChannelPointsAwarded)
+		}
+	}
+
+	notice := &UserNotice{
+		Type:          noticeType,
+		Channel:       event.BroadcasterUserLogin,
+		User:          event.ChatterUserLogin,
+		UserID:        event.ChatterUserID,
+		DisplayName:   event.ChatterUserName,
+		Message:       event.Message.Text,
+		SystemMessage: event.SystemMessage,
+		MsgParams:     params,
+		Badges:        badges,
+		BadgeInfo:     badgeInfo,
+		Color:         event.Color,
+		Emotes:        emotesFromFragments(event.Message.Fragments),
+		Timestamp:     ts,
+		Raw:           raw,
+	}
+	notice.Data = parseUserNoticeData(notice)
+
+	return notice
+}
+
+// firstNonNil returns the first non-nil pointer, or nil.
+func firstNonNil[T any](ptrs ...*T) *T {
+	for _, p := range ptrs {
+		if p != nil {
+			return p
+		}
+	}
+	return nil
+}
@@ -0,0 +1,743 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Values from the Twitch channel.chat.message and channel.chat.notification
+// reference payloads: https://dev.twitch.tv/docs/eventsub/eventsub-subscription-types/
+const (
+	twitchChatEventBroadcasterID    = "1971641"
+	twitchChatEventBroadcasterLogin = "streamer"
+	twitchChatEventChatterID        = "4145994"
+	twitchChatEventChatterLogin     = "viewer32"
+	twitchChatEventMessageID        = "cc106a89-1814-919d-454c-f4f2f970aae7"
+)
+
+const twitchChatMessageEventJSON = `{
+	"broadcaster_user_id": "1971641",
+	"broadcaster_user_login": "streamer",
+	"broadcaster_user_name": "streamer",
+	"chatter_user_id": "4145994",
+	"chatter_user_login": "viewer32",
+	"chatter_user_name": "viewer32",
+	"message_id": "cc106a89-1814-919d-454c-f4f2f970aae7",
+	"message": {
+		"text": "Hi chat Kappa",
+		"fragments": [
+			{"type": "text", "text": "Hi chat ", "cheermote": null, "emote": null, "mention": null},
+			{"type": "emote", "text": "Kappa", "cheermote": null, "emote": {"id": "25", "emote_set_id": "0", "owner_id": "0", "format": ["static"]}, "mention": null}
+		]
+	},
+	"color": "#00FF7F",
+	"badges": [
+		{"set_id": "moderator", "id": "1", "info": ""},
+		{"set_id": "subscriber", "id": "12", "info": "16"}
+	],
+	"message_type": "text",
+	"cheer": {"bits": 100},
+	"reply": {
+		"parent_message_id": "1234",
+		"parent_message_body": "hello",
+		"parent_user_id": "5678",
+		"parent_user_name": "Parent",
+		"parent_user_login": "parent",
+		"thread_message_id": "1234",
+		"thread_user_id": "5678",
+		"thread_user_name": "Parent",
+		"thread_user_login": "parent"
+	},
+	"channel_points_custom_reward_id": null
+}`
+
+func TestChatMessageFromEvent(t *testing.T) {
+	event, err := ParseWSEvent[ChannelChatMessageEvent](json.RawMessage(twitchChatMessageEventJSON))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	ts := time.Unix(1700000000, 0)
+
+	msg := chatMessageFromEvent(event, twitchChatMessageEventJSON, ts)
+
+	if msg.ID != twitchChatEventMessageID {
+		t.Errorf("ID = %q", msg.ID)
+	}
+	if msg.Channel != twitchChatEventBroadcasterLogin || msg.User != twitchChatEventChatterLogin || msg.UserID != twitchChatEventChatterID {
+		t.Errorf("unexpected identity: channel=%q user=%q id=%q", msg.Channel, msg.User, msg.UserID)
+	}
+	if msg.Message != "Hi chat Kappa" {
+		t.Errorf("Message = %q", msg.Message)
+	}
+	if len(msg.Emotes) != 1 || msg.Emotes[0].ID != "25" || msg.Emotes[0].Start != 8 || msg.Emotes[0].End != 12 {
+		t.Errorf("unexpected emotes: %+v", msg.Emotes)
+	}
+	if !msg.IsMod || !msg.IsSubscriber || msg.IsVIP || msg.IsBroadcaster {
+		t.Errorf("unexpected flags: mod=%v sub=%v vip=%v bc=%v", msg.IsMod, msg.IsSubscriber, msg.IsVIP, msg.IsBroadcaster)
+	}
+	if msg.BadgeInfo["subscriber"] != "16" {
+		t.Errorf("BadgeInfo = %v", msg.BadgeInfo)
+	}
+	if msg.Bits != 100 {
+		t.Errorf("Bits = %d", msg.Bits)
+	}
+	if msg.ReplyParentMsgID != "1234" || msg.ReplyParentUserLogin != "parent" || msg.ReplyParentMsgBody != "hello" {
+		t.Errorf("unexpected reply fields: %+v", msg)
+	}
+	if !msg.Timestamp.Equal(ts) {
+		t.Errorf("Timestamp = %v", msg.Timestamp)
+	}
+}
+
+func TestEmotesFromFragments_Unicode(t *testing.T) {
+	fragments := []ChatEventFragment{
+		{Type: "text", Text: "héllo "},
+		{Type: "emote", Text: "Kappa", Emote: &ChatEventEmote{ID: "25"}},
+		{Type: "text", Text: " "},
+		{Type: "emote", Text: "Kappa", Emote: &ChatEventEmote{ID: "25"}},
+	}
+
+	emotes := emotesFromFragments(fragments)
+	if len(emotes) != 2 {
+		t.Fatalf("expected 2 emotes, got %d", len(emotes))
+	}
+	if emotes[0].Start != 6 || emotes[0].End != 10 {
+		t.Errorf("first emote at %d-%d, want 6-10", emotes[0].Start, emotes[0].End)
+	}
+	if emotes[1].Start != 12 || emotes[1].End != 16 {
+		t.Errorf("second emote at %d-%d, want 12-16", emotes[1].Start, emotes[1].End)
+	}
+}
+
+func TestUserNoticeFromEvent(t *testing.T) {
+	total := 5
+	giftID := "gift-1"
+	gifter := "gifter"
+	tests := []struct {
+		name       string
+		event      ChannelChatNotificationEvent
+		wantType   string
+		wantParams map[string]string
+	}{
+		{
+			name: "resub",
+			event: ChannelChatNotificationEvent{
+				NoticeType: "resub",
+				Resub:      &ChatNotificationResub{CumulativeMonths: 10, StreakMonths: 3, DurationMonths: 1, SubTier: "1000"},
+			},
+			wantType:   UserNoticeTypeResub,
+			wantParams: map[string]string{"cumulative-months": "10", "streak-months": "3", "sub-plan": "1000", "should-share-streak": "1"},
+		},
+		{
+			name: "prime sub",
+			event: ChannelChatNotificationEvent{
+				NoticeType: "sub",
+				Sub:        &ChatNotificationSub{SubTier: "1000", IsPrime: true, DurationMonths: 1},
+			},
+			wantType:   UserNoticeTypeSub,
+			wantParams: map[string]string{"sub-plan": "Prime"},
+		},
+		{
+			name: "anonymous gift",
+			event: ChannelChatNotificationEvent{
+				NoticeType:         "sub_gift",
+				ChatterIsAnonymous: true,
+				SubGift:            &ChatNotificationSubGift{RecipientUserID: "42", RecipientUserLogin: "lucky", SubTier: "2000", DurationMonths: 1, CommunityGiftID: &giftID},
+			},
+			wantType:   UserNoticeTypeAnonSubGift,
+			wantParams: map[string]string{"recipient-id": "42", "recipient-user-name": "lucky", "sub-plan": "2000", "community-gift-id": "gift-1"},
+		},
+		{
+			name: "community gift",
+			event: ChannelChatNotificationEvent{
+				NoticeType:       "community_sub_gift",
+				CommunitySubGift: &ChatNotificationCommunitySubGift{ID: "gift-1", Total: 5, SubTier: "1000", CumulativeTotal: &total},
+			},
+			wantType:   UserNoticeTypeSubMysteryGift,
+			wantParams: map[string]string{"mass-gift-count": "5", "community-gift-id": "gift-1", "sender-count": "5"},
+		},
+		{
+			name: "anonymous community gift",
+			event: ChannelChatNotificationEvent{
+				NoticeType:         "community_sub_gift",
+				ChatterIsAnonymous: true,
+				CommunitySubGift:   &ChatNotificationCommunitySubGift{ID: "gift-2", Total: 10, SubTier: "1000"},
+			},
+			wantType:   UserNoticeTypeAnonSubMysteryGift,
+			wantParams: map[string]string{"mass-gift-count": "10", "community-gift-id": "gift-2"},
+		},
+		{
+			name: "anonymous gift paid upgrade",
+			event: ChannelChatNotificationEvent{
+				NoticeType:         "gift_paid_upgrade",
+				ChatterIsAnonymous: true,
+				GiftPaidUpgrade:    &ChatNotificationGiftPaidUpgrade{GifterIsAnonymous: true},
+			},
+			wantType:   UserNoticeTypeAnonGiftPaidUpgrade,
+			wantParams: map[string]string{},
+		},
+		{
+			name: "gift paid upgrade",
+			event: ChannelChatNotificationEvent{
+				NoticeType:      "gift_paid_upgrade",
+				GiftPaidUpgrade: &ChatNotificationGiftPaidUpgrade{GifterUserLogin: &gifter},
+			},
+			wantType:   UserNoticeTypeGiftPaidUpgrade,
+			wantParams: map[string]string{"sender-login": "gifter"},
+		},
+		{
+			name: "shared chat raid",
+			event: ChannelChatNotificationEvent{
+				NoticeType:     "shared_chat_raid",
+				SharedChatRaid: &ChatNotificationRaid{UserLogin: "raider", UserName: "Raider", ViewerCount: 120},
+			},
+			wantType:   UserNoticeTypeRaid,
+			wantParams: map[string]string{"login": "raider", "displayName": "Raider", "viewerCount": "120"},
+		},
+		{
+			name: "announcement",
+			event: ChannelChatNotificationEvent{
+				NoticeType:   "announcement",
+				Announcement: &ChatNotificationAnnouncement{Color: "blue"},
+			},
+			wantType:   UserNoticeTypeAnnouncement,
+			wantParams: map[string]string{"color": "BLUE"},
+		},
+		{
+			name: "bits badge tier",
+			event: ChannelChatNotificationEvent{
+				NoticeType:    "bits_badge_tier",
+				BitsBadgeTier: &ChatNotificationBitsBadgeTier{Tier: 1000},
+			},
+			wantType:   UserNoticeTypeBitsBadgeTier,
+			wantParams: map[string]string{"threshold": "1000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice := userNoticeFromEvent(&tt.event, "", time.Now())
+			if notice.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", notice.Type, tt.wantType)
+			}
+			for k, v := range tt.wantParams {
+				if notice.MsgParams[k] != v {
+					t.Errorf("MsgParams[%q] = %q, want %q", k, notice.MsgParams[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestRoomStateFromEvent(t *testing.T) {
+	minutes := 10
+	state := roomStateFromEvent(&ChannelChatSettingsUpdateEvent{
+		EventSubBroadcaster:         EventSubBroadcaster{BroadcasterUserID: "1", BroadcasterUserLogin: "chan"},
+		FollowerMode:                true,
+		FollowerModeDurationMinutes: &minutes,
+		SlowMode:                    true,
+		SlowModeWaitTimeSeconds:     30,
+		UniqueChatMode:              true,
+	}, "")
+
+	if state.Channel != "chan" || state.RoomID != "1" {
+		t.Errorf("unexpected channel: %+v", state)
+	}
+	if state.FollowersOnly != 10 || state.Slow != 30 || !state.R9K || state.EmoteOnly || state.SubsOnly {
+		t.Errorf("unexpected modes: %+v", state)
+	}
+
+	off := roomStateFromEvent(&ChannelChatSettingsUpdateEvent{SlowModeWaitTimeSeconds: 30}, "")
+	if off.FollowersOnly != -1 || off.Slow != 0 {
+		t.Errorf("expected modes off, got followers=%d slow=%d", off.FollowersOnly, off.Slow)
+	}
+}
+
+func TestNewEventSubChatClient_NilClient(t *testing.T) {
+	if NewEventSubChatClient(nil) != nil {
+		t.Error("expected nil client for nil helix client")
+	}
+}
+
+func TestNormalizeChannelName(t *testing.T) {
+	for in, want := range map[string]string{"#Streamer": "streamer", "@Viewer": "viewer", " chan ": "chan"} {
+		if got := normalizeChannelName(in); got != want {
+			t.Errorf("normalizeChannelName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// chatEventSubTestServer serves the Helix endpoints the EventSub chat client uses.
+type chatEventSubTestServer struct {
+	mu       sync.Mutex
+	created  []CreateEventSubSubscriptionParams
+	deleted  []string
+	sent     []SendChatMessageParams
+	dropSend bool
+	failUser bool // fail the next login lookup
+}
+
+func (s *chatEventSubTestServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/users":
+			logins := r.URL.Query()["login"]
+			if len(logins) > 0 && s.failUser {
+				s.failUser = false
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var users []User
+			if len(logins) == 0 {
+				users = append(users, User{ID: "999", Login: "bot"})
+			}
+			for _, login := range logins {
+				id := twitchChatEventBroadcasterID
+				if login != twitchChatEventBroadcasterLogin {
+					id = "id-" + login
+				}
+				users = append(users, User{ID: id, Login: login})
+			}
+			_ = json.NewEncoder(w).Encode(Response[User]{Data: users})
+		case r.URL.Path == "/eventsub/subscriptions" && r.Method == http.MethodPost:
+			var params CreateEventSubSubscriptionParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			s.created = append(s.created, params)
+			w.WriteHeader(http.StatusAccepted)
+			_ = json.NewEncoder(w).Encode(EventSubResponse{Data: []EventSubSubscription{{
+				ID:        params.Type + "-" + params.Condition["broadcaster_user_id"],
+				Type:      params.Type,
+				Condition: params.Condition,
+			}}})
+		case r.URL.Path == "/eventsub/subscriptions" && r.Method == http.MethodDelete:
+			s.deleted = append(s.deleted, r.URL.Query().Get("id"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/chat/messages":
+			var params SendChatMessageParams
+			_ = json.NewDecoder(r.Body).Decode(&params)
+			s.sent = append(s.sent, params)
+			resp := SendChatMessageResponse{MessageID: "sent-1", IsSent: true}
+			if s.dropSend {
+				resp = SendChatMessageResponse{IsSent: false}
+				resp.DropReason = &struct {
+					Code    string `json:"code"`
+					Message string `json:"message"`
+				}{Code: "msg_duplicate", Message: "duplicate"}
+			}
+			_ = json.NewEncoder(w).Encode(Response[SendChatMessageResponse]{Data: []SendChatMessageResponse{resp}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// newChatEventSubWSServer returns a WebSocket server that sends a welcome and
+// then each of the given notification events once the test signals ready.
+func newChatEventSubWSServer(ready <-chan struct{}, events map[string]string) *mockWSServer {
+	return newMockWSServer(func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeWelcome, MessageTimestamp: time.Now()},
+			Payload: mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{
+				ID:                      twitchWSExampleSessionID,
+				Status:                  "connected",
+				KeepaliveTimeoutSeconds: 10,
+			}}),
+		})
+		<-ready
+		for eventType, event := range events {
+			_ = conn.WriteJSON(WebSocketMessage{
+				Metadata: WebSocketMetadata{MessageType: WSMessageTypeNotification, SubscriptionType: eventType},
+				Payload: mustMarshal(WebSocketNotificationPayload{
+					Subscription: EventSubSubscription{Type: eventType},
+					Event:        json.RawMessage(event),
+				}),
+			})
+		}
+		time.Sleep(500 * time.Millisecond)
+	})
+}
+
+func TestChatBotClient_EventSubBackend(t *testing.T) {
+	api := &chatEventSubTestServer{}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	ready := make(chan struct{})
+	wsServer := newChatEventSubWSServer(ready, map[string]string{
+		EventSubTypeChannelChatMessage: twitchChatMessageEventJSON,
+		EventSubTypeChannelChatNotification: `{"broadcaster_user_login":"streamer","chatter_user_login":"raider",
+			"notice_type":"raid","raid":{"user_login":"raider","user_name":"Raider","viewer_count":42},"message":{"text":""}}`,
+	})
+	defer wsServer.Close()
+
+	bot := NewChatBotClient("bot", nil,
+		WithChatBotBackend(ChatBackendEventSub),
+		WithChatBotHelixClient(client),
+		WithChatBotEventSubURL(wsServer.URL()),
+	)
+
+	messages := make(chan *ChatMessage, 1)
+	cheers := make(chan *ChatMessage, 1)
+	raids := make(chan *UserNotice, 1)
+	bot.OnMessage(func(m *ChatMessage) { messages <- m })
+	bot.OnCheer(func(m *ChatMessage) { cheers <- m })
+	bot.OnRaid(func(n *UserNotice) { raids <- n })
+
+	if err := bot.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = bot.Close() }()
+
+	if bot.IRC() != nil || bot.EventSub() == nil {
+		t.Fatal("expected EventSub backend to be active")
+	}
+	if got := bot.EventSub().UserID(); got != "999" {
+		t.Errorf("UserID = %q, want 999 (resolved from token)", got)
+	}
+	if !bot.IsConnected() {
+		t.Error("expected connected")
+	}
+
+	if err := bot.Join("#Streamer"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	api.mu.Lock()
+	if len(api.created) != len(eventSubChatTypes) {
+		t.Errorf("created %d subscriptions, want %d", len(api.created), len(eventSubChatTypes))
+	}
+	for _, sub := range api.created {
+		if sub.Condition["broadcaster_user_id"] != twitchChatEventBroadcasterID || sub.Condition["user_id"] != "999" {
+			t.Errorf("unexpected condition for %s: %v", sub.Type, sub.Condition)
+		}
+		if sub.Transport.SessionID != twitchWSExampleSessionID {
+			t.Errorf("unexpected session ID %q", sub.Transport.SessionID)
+		}
+	}
+	api.mu.Unlock()
+	close(ready)
+
+	select {
+	case m := <-messages:
+		if m.User != twitchChatEventChatterLogin {
+			t.Errorf("message user = %q", m.User)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+	}
+	select {
+	case <-cheers:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for cheer")
+	}
+	select {
+	case n := <-raids:
+		if n.MsgParams["viewerCount"] != "42" {
+			t.Errorf("viewerCount = %q", n.MsgParams["viewerCount"])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for raid")
+	}
+
+	if err := bot.Reply("streamer", "parent-1", "hello"); err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	api.mu.Lock()
+	if len(api.sent) != 1 || api.sent[0].BroadcasterID != twitchChatEventBroadcasterID ||
+		api.sent[0].SenderID != "999" || api.sent[0].ReplyParentMessageID != "parent-1" {
+		t.Errorf("unexpected sent messages: %+v", api.sent)
+	}
+	api.dropSend = true
+	api.mu.Unlock()
+
+	var dropped *ChatMessageDroppedError
+	if err := bot.Say("streamer", "hello again"); !errors.As(err, &dropped) || dropped.Code != "msg_duplicate" {
+		t.Errorf("expected ChatMessageDroppedError, got %v", err)
+	}
+
+	if err := bot.Part("streamer"); err != nil {
+		t.Fatalf("Part: %v", err)
+	}
+	api.mu.Lock()
+	if len(api.deleted) != len(eventSubChatTypes) {
+		t.Errorf("deleted %d subscriptions, want %d", len(api.deleted), len(eventSubChatTypes))
+	}
+	api.mu.Unlock()
+	if len(bot.GetJoinedChannels()) != 0 {
+		t.Errorf("expected no joined channels, got %v", bot.GetJoinedChannels())
+	}
+}
+
+func TestChatBotClient_EventSubBackend_NoHelixClient(t *testing.T) {
+	bot := NewChatBotClient("bot", nil, WithChatBotBackend(ChatBackendEventSub))
+	err := bot.Connect(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "helix client") {
+		t.Errorf("expected helix client error, got %v", err)
+	}
+}
+
+func TestEventSubChatClient_JoinBeforeConnect(t *testing.T) {
+	api := &chatEventSubTestServer{}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	ready := make(chan struct{})
+	defer close(ready)
+	wsServer := newChatEventSubWSServer(ready, nil)
+	defer wsServer.Close()
+
+	es := NewEventSubChatClient(client, WithEventSubChatUserID("777"), WithEventSubChatURL(wsServer.URL()))
+	if err := es.Join(context.Background(), "streamer", "other"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	api.mu.Lock()
+	if len(api.created) != 0 {
+		t.Errorf("expected no subscriptions before Connect, got %d", len(api.created))
+	}
+	api.mu.Unlock()
+
+	if err := es.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = es.Close() }()
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.created) != 2*len(eventSubChatTypes) {
+		t.Errorf("created %d subscriptions, want %d", len(api.created), 2*len(eventSubChatTypes))
+	}
+	for _, sub := range api.created {
+		if sub.Condition["user_id"] != "777" {
+			t.Errorf("unexpected user_id %q", sub.Condition["user_id"])
+		}
+	}
+}
+
+func TestEventSubChatClient_RevocationKeepsOtherChannels(t *testing.T) {
+	api := &chatEventSubTestServer{}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	ready := make(chan struct{})
+	wsServer := newMockWSServer(func(conn *websocket.Conn) {
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeWelcome},
+			Payload:  mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{ID: twitchWSExampleSessionID, KeepaliveTimeoutSeconds: 10}}),
+		})
+		<-ready
+		// The bot was banned in the other channel
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeRevocation},
+			Payload: mustMarshal(WebSocketNotificationPayload{Subscription: EventSubSubscription{
+				ID:     EventSubTypeChannelChatMessage + "-id-other",
+				Type:   EventSubTypeChannelChatMessage,
+				Status: "user_removed",
+			}}),
+		})
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeNotification},
+			Payload: mustMarshal(WebSocketNotificationPayload{
+				Subscription: EventSubSubscription{Type: EventSubTypeChannelChatMessage},
+				Event:        json.RawMessage(twitchChatMessageEventJSON),
+			}),
+		})
+		time.Sleep(500 * time.Millisecond)
+	})
+	defer wsServer.Close()
+
+	messages := make(chan *ChatMessage, 1)
+	errs := make(chan error, 10)
+	es := NewEventSubChatClient(client,
+		WithEventSubChatUserID("777"),
+		WithEventSubChatURL(wsServer.URL()),
+		WithEventSubChatAutoReconnect(false),
+		WithEventSubChatMessageHandler(func(m *ChatMessage) { messages <- m }),
+		WithEventSubChatErrorHandler(func(err error) { errs <- err }),
+	)
+	if err := es.Join(context.Background(), "streamer", "other"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := es.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = es.Close() }()
+	close(ready)
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "for other revoked: user_removed") {
+			t.Errorf("revocation error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for revocation error")
+	}
+	select {
+	case m := <-messages:
+		if m.Channel != twitchChatEventBroadcasterLogin {
+			t.Errorf("message channel = %q", m.Channel)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("chat for the remaining channel stopped after the revocation")
+	}
+}
+
+func TestEventSubChatClient_ReconnectsAfterDrop(t *testing.T) {
+	api := &chatEventSubTestServer{}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	var mu sync.Mutex
+	sessions := 0
+	wsServer := newMockWSServer(func(conn *websocket.Conn) {
+		mu.Lock()
+		sessions++
+		n := sessions
+		mu.Unlock()
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeWelcome},
+			Payload:  mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{ID: fmt.Sprintf("session-%d", n), KeepaliveTimeoutSeconds: 10}}),
+		})
+		if n == 1 {
+			// Drop the first session once the channel is subscribed
+			time.Sleep(200 * time.Millisecond)
+			return
+		}
+		time.Sleep(2 * time.Second)
+	})
+	defer wsServer.Close()
+
+	disconnects := make(chan struct{}, 1)
+	connects := make(chan struct{}, 2)
+	es := NewEventSubChatClient(client,
+		WithEventSubChatUserID("777"),
+		WithEventSubChatURL(wsServer.URL()),
+		WithEventSubChatReconnectDelay(10*time.Millisecond),
+		WithEventSubChatConnectHandler(func() { connects <- struct{}{} }),
+		WithEventSubChatDisconnectHandler(func() { disconnects <- struct{}{} }),
+	)
+	if err := es.Join(context.Background(), "streamer"); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+	if err := es.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = es.Close() }()
+	<-connects
+
+	for name, ch := range map[string]chan struct{}{"disconnect": disconnects, "reconnect": connects} {
+		select {
+		case <-ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", name)
+		}
+	}
+	if !es.IsConnected() {
+		t.Error("expected connected after reconnect")
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.created) != 2*len(eventSubChatTypes) || api.created[len(api.created)-1].Transport.SessionID != "session-2" {
+		t.Errorf("created %d subscriptions, want %d with the last on session-2", len(api.created), 2*len(eventSubChatTypes))
+	}
+}
+
+func TestEventSubChatClient_ConnectClosesOldSession(t *testing.T) {
+	api := &chatEventSubTestServer{}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	var mu sync.Mutex
+	sessions := 0
+	closed := make(chan int, 2)
+	wsServer := newMockWSServer(func(conn *websocket.Conn) {
+		mu.Lock()
+		sessions++
+		n := sessions
+		mu.Unlock()
+		_ = conn.WriteJSON(WebSocketMessage{
+			Metadata: WebSocketMetadata{MessageType: WSMessageTypeWelcome},
+			Payload:  mustMarshal(WebSocketWelcomePayload{Session: WebSocketSession{ID: fmt.Sprintf("session-%d", n), KeepaliveTimeoutSeconds: 10}}),
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				closed <- n
+				return
+			}
+		}
+	})
+	defer wsServer.Close()
+
+	disconnects := make(chan struct{}, 2)
+	es := NewEventSubChatClient(client,
+		WithEventSubChatUserID("777"),
+		WithEventSubChatURL(wsServer.URL()),
+		WithEventSubChatDisconnectHandler(func() { disconnects <- struct{}{} }),
+	)
+	for range 2 {
+		if err := es.Connect(context.Background()); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+	}
+	defer func() { _ = es.Close() }()
+
+	select {
+	case n := <-closed:
+		if n != 1 {
+			t.Errorf("closed session %d, want 1", n)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("first session was not closed")
+	}
+	// Replacing the session isn't reported as a drop
+	time.Sleep(50 * time.Millisecond)
+	if len(disconnects) != 0 || !es.IsConnected() {
+		t.Errorf("disconnects = %d, connected = %v", len(disconnects), es.IsConnected())
+	}
+}
+
+func TestEventSubChatClient_JoinRetryAfterFailure(t *testing.T) {
+	api := &chatEventSubTestServer{failUser: true}
+	client, server := newTestClient(api.handler(t))
+	defer server.Close()
+
+	ready := make(chan struct{})
+	defer close(ready)
+	wsServer := newChatEventSubWSServer(ready, nil)
+	defer wsServer.Close()
+
+	es := NewEventSubChatClient(client, WithEventSubChatUserID("777"), WithEventSubChatURL(wsServer.URL()))
+	if err := es.Connect(context.Background()); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer func() { _ = es.Close() }()
+
+	if err := es.Join(context.Background(), "streamer"); err == nil {
+		t.Fatal("expected first Join to fail")
+	}
+	if joined := es.GetJoinedChannels(); len(joined) != 0 {
+		t.Errorf("joined after failure = %v", joined)
+	}
+	if err := es.Join(context.Background(), "streamer"); err != nil {
+		t.Fatalf("second Join: %v", err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	if len(api.created) != len(eventSubChatTypes) {
+		t.Errorf("created %d subscriptions, want %d", len(api.created), len(eventSubChatTypes))
+	}
+}
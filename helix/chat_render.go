@@ -0,0 +1,651 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// MessageTokenType identifies the kind of a rendered message token.
+type MessageTokenType string
+
+// Message token types
+const (
+	MessageTokenText      MessageTokenType = "text"
+	MessageTokenEmote     MessageTokenType = "emote"
+	MessageTokenCheermote MessageTokenType = "cheermote"
+	MessageTokenMention   MessageTokenType = "mention"
+	MessageTokenLink      MessageTokenType = "link"
+)
+
+// EmoteProviderTwitch is the provider name set on tokens for Twitch emotes.
+const EmoteProviderTwitch = "twitch"
+
+// Emote image options used by MessageRenderer.
+const (
+	EmoteScale1x = "1.0"
+	EmoteScale2x = "2.0"
+	EmoteScale3x = "3.0"
+
+	EmoteThemeLight = "light"
+	EmoteThemeDark  = "dark"
+
+	EmoteFormatStatic   = "static"
+	EmoteFormatAnimated = "animated"
+)
+
+// emoteCDNTemplate is the Twitch emote CDN URL template returned by the
+// Get Emotes endpoints.
+const emoteCDNTemplate = "https://static-cdn.jtvnw.net/emoticons/v2/%s/%s/%s/%s"
+
+// MessageToken is one piece of a rendered chat message. Concatenating the
+// Text of every token reproduces the original message.
+type MessageToken struct {
+	Type MessageTokenType
+	Text string
+
+	// Emote and cheermote fields
+	EmoteID  string // Emote ID (emote tokens)
+	Provider string // "twitch" or the third-party provider name (emote tokens)
+	ImageURL string // Image at the renderer's scale, theme and format
+	Bits     int    // Bits cheered (cheermote tokens)
+	Color    string // Tier color (cheermote tokens)
+
+	// Mention fields
+	UserID    string // Mentioned user's ID, when known
+	UserLogin string // Mentioned user's login (without @)
+
+	// Link fields
+	URL string // Link target, with a scheme added if the message omitted one
+}
+
+// ThirdPartyEmote is an emote supplied by an EmoteProvider.
+type ThirdPartyEmote struct {
+	ID       string
+	Name     string // Code that is matched as a whole word in messages
+	Provider string // Defaults to the provider's Name()
+	Images   EmoteImages
+	Animated bool
+}
+
+// EmoteProvider supplies emotes from outside Twitch (for example 7TV, BTTV
+// or FFZ). Emotes returns both the provider's global emotes and the ones
+// enabled for broadcasterID; results are cached by the MessageRenderer.
+type EmoteProvider interface {
+	Name() string
+	Emotes(ctx context.Context, broadcasterID string) ([]ThirdPartyEmote, error)
+}
+
+// staticEmoteProvider is an EmoteProvider backed by a fixed list.
+type staticEmoteProvider struct {
+	name   string
+	emotes []ThirdPartyEmote
+}
+
+// NewStaticEmoteProvider returns an EmoteProvider that serves the same emotes
+// for every channel. Useful for custom emote sets and tests.
+func NewStaticEmoteProvider(name string, emotes ...ThirdPartyEmote) EmoteProvider {
+	return &staticEmoteProvider{name: name, emotes: emotes}
+}
+
+func (p *staticEmoteProvider) Name() string { return p.name }
+
+func (p *staticEmoteProvider) Emotes(_ context.Context, _ string) ([]ThirdPartyEmote, error) {
+	return p.emotes, nil
+}
+
+// renderCacheEntry holds a cached value with its fetch time.
+type renderCacheEntry[T any] struct {
+	value     T
+	fetchedAt time.Time
+}
+
+// MessageRenderer turns chat messages into a token stream of text, emotes,
+// cheermotes, mentions and links, ready to be drawn by an overlay or UI.
+//
+// Twitch emotes, cheermotes and third-party emotes are fetched on demand and
+// cached per channel. A MessageRenderer is safe for concurrent use.
+type MessageRenderer struct {
+	client    *Client
+	scale     string
+	theme     string
+	format    string
+	ttl       time.Duration
+	providers []EmoteProvider
+
+	globalEmotes  renderCacheEntry[map[string]Emote]
+	channelEmotes map[string]renderCacheEntry[map[string]Emote]
+	setEmotes     map[string]Emote
+	cheermotes    map[string]renderCacheEntry[map[string]Cheermote]
+	thirdParty    map[string]renderCacheEntry[map[string]ThirdPartyEmote]
+
+	mu sync.RWMutex
+}
+
+// MessageRendererOption configures a MessageRenderer.
+type MessageRendererOption func(*MessageRenderer)
+
+// WithRenderScale sets the emote image scale (EmoteScale1x, 2x or 3x).
+func WithRenderScale(scale string) MessageRendererOption {
+	return func(r *MessageRenderer) {
+		r.scale = scale
+	}
+}
+
+// WithRenderTheme sets the emote theme (EmoteThemeLight or EmoteThemeDark).
+func WithRenderTheme(theme string) MessageRendererOption {
+	return func(r *MessageRenderer) {
+		r.theme = theme
+	}
+}
+
+// WithRenderFormat sets the preferred emote format. EmoteFormatAnimated
+// falls back to static images for emotes that are not animated.
+func WithRenderFormat(format string) MessageRendererOption {
+	return func(r *MessageRenderer) {
+		r.format = format
+	}
+}
+
+// WithRenderCacheTTL sets how long fetched emotes are reused (default 1 hour).
+// A TTL of 0 keeps them until ClearCache is called.
+func WithRenderCacheTTL(ttl time.Duration) MessageRendererOption {
+	return func(r *MessageRenderer) {
+		r.ttl = ttl
+	}
+}
+
+// WithEmoteProvider adds a third-party emote provider. Providers are checked
+// in the order they were added; the first match wins.
+func WithEmoteProvider(p EmoteProvider) MessageRendererOption {
+	return func(r *MessageRenderer) {
+		r.providers = append(r.providers, p)
+	}
+}
+
+// NewMessageRenderer creates a new message renderer. The client is used to
+// fetch emotes and cheermotes; it may be nil if only third-party emotes and
+// static Twitch emote URLs are needed.
+func NewMessageRenderer(client *Client, opts ...MessageRendererOption) *MessageRenderer {
+	r := &MessageRenderer{
+		client:        client,
+		scale:         EmoteScale1x,
+		theme:         EmoteThemeDark,
+		format:        EmoteFormatStatic,
+		ttl:           time.Hour,
+		channelEmotes: make(map[string]renderCacheEntry[map[string]Emote]),
+		setEmotes:     make(map[string]Emote),
+		cheermotes:    make(map[string]renderCacheEntry[map[string]Cheermote]),
+		thirdParty:    make(map[string]renderCacheEntry[map[string]ThirdPartyEmote]),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// EmoteURL builds the CDN URL for a Twitch emote at the renderer's scale and
+// theme. formats lists the formats the emote supports; if it is empty or does
+// not include the preferred format, the static image is used.
+func (r *MessageRenderer) EmoteURL(emoteID string, formats []string) string {
+	format := EmoteFormatStatic
+	if r.format != EmoteFormatStatic && slices.Contains(formats, r.format) {
+		format = r.format
+	}
+	return fmt.Sprintf(emoteCDNTemplate, emoteID, format, r.theme, r.scale)
+}
+
+// Render tokenizes an IRC chat message. Emote positions come from the
+// message's emotes tag; cheermotes are only matched when the message carries
+// bits. Third-party emotes and cheermotes are looked up for msg.RoomID.
+func (r *MessageRenderer) Render(ctx context.Context, msg *ChatMessage) ([]MessageToken, error) {
+	if msg == nil {
+		return nil, nil
+	}
+
+	emotes := make([]IRCEmote, 0, len(msg.Emotes))
+	emotes = append(emotes, msg.Emotes...)
+	sort.Slice(emotes, func(i, j int) bool { return emotes[i].Start < emotes[j].Start })
+
+	t, err := r.newTokenizer(ctx, msg.RoomID, msg.Bits > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var twitch map[string]Emote
+	if len(emotes) > 0 && r.format != EmoteFormatStatic {
+		if twitch, err = r.twitchEmotes(ctx, msg.RoomID); err != nil {
+			return nil, err
+		}
+	}
+
+	runes := []rune(msg.Message)
+	pos := 0
+	for _, e := range emotes {
+		if e.Start < pos || e.End >= len(runes) || e.End < e.Start {
+			continue // overlapping or out of range
+		}
+		t.words(string(runes[pos:e.Start]))
+		t.emote(e.ID, string(runes[e.Start:e.End+1]), twitch[e.ID].Format)
+		pos = e.End + 1
+	}
+	t.words(string(runes[pos:]))
+
+	return t.tokens, nil
+}
+
+// RenderEvent tokenizes an EventSub channel.chat.message event.
+func (r *MessageRenderer) RenderEvent(ctx context.Context, event *ChannelChatMessageEvent) ([]MessageToken, error) {
+	if event == nil {
+		return nil, nil
+	}
+	return r.RenderFragments(ctx, event.BroadcasterUserID, event.Message.Fragments)
+}
+
+// RenderFragments tokenizes EventSub message fragments (as found in chat
+// messages, notifications and shared chat events) for a broadcaster.
+func (r *MessageRenderer) RenderFragments(ctx context.Context, broadcasterID string, fragments []ChatEventFragment) ([]MessageToken, error) {
+	hasCheer := false
+	for _, f := range fragments {
+		if f.Type == "cheermote" {
+			hasCheer = true
+			break
+		}
+	}
+
+	t, err := r.newTokenizer(ctx, broadcasterID, hasCheer)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range fragments {
+		switch {
+		case f.Type == "emote" && f.Emote != nil:
+			t.emote(f.Emote.ID, f.Text, f.Emote.Format)
+		case f.Type == "cheermote" && f.Cheermote != nil:
+			if !t.cheer(f.Text, f.Cheermote.Prefix, f.Cheermote.Bits) {
+				t.text(f.Text)
+			}
+		case f.Type == "mention" && f.Mention != nil:
+			t.tokens = append(t.tokens, MessageToken{
+				Type:      MessageTokenMention,
+				Text:      f.Text,
+				UserID:    f.Mention.UserID,
+				UserLogin: f.Mention.UserLogin,
+			})
+		default:
+			t.words(f.Text)
+		}
+	}
+
+	return t.tokens, nil
+}
+
+// LoadEmoteSets fetches emote sets (for example from GLOBALUSERSTATE) so
+// their animated formats are known when rendering. Sets are requested in
+// chunks of 25, the endpoint's limit.
+func (r *MessageRenderer) LoadEmoteSets(ctx context.Context, setIDs ...string) error {
+	if r.client == nil {
+		return nil
+	}
+	for start := 0; start < len(setIDs); start += 25 {
+		end := min(start+25, len(setIDs))
+		resp, err := r.client.GetEmoteSets(ctx, setIDs[start:end])
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		for _, e := range resp.Data {
+			r.setEmotes[e.ID] = e
+		}
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+// ClearCache drops every cached emote, emote set, cheermote and third-party
+// emote so they are fetched again on the next render.
+func (r *MessageRenderer) ClearCache() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.globalEmotes = renderCacheEntry[map[string]Emote]{}
+	r.channelEmotes = make(map[string]renderCacheEntry[map[string]Emote])
+	r.setEmotes = make(map[string]Emote)
+	r.cheermotes = make(map[string]renderCacheEntry[map[string]Cheermote])
+	r.thirdParty = make(map[string]renderCacheEntry[map[string]ThirdPartyEmote])
+}
+
+// InvalidateChannel drops the cached emotes and cheermotes for one channel,
+// e.g. after the broadcaster uploads a new emote.
+func (r *MessageRenderer) InvalidateChannel(broadcasterID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.channelEmotes, broadcasterID)
+	delete(r.cheermotes, broadcasterID)
+	delete(r.thirdParty, broadcasterID)
+}
+
+// fresh reports whether a cache entry can be reused.
+func (r *MessageRenderer) fresh(fetchedAt time.Time) bool {
+	return !fetchedAt.IsZero() && (r.ttl <= 0 || time.Since(fetchedAt) < r.ttl)
+}
+
+// twitchEmotes returns the global, channel and loaded emote set emotes by ID.
+func (r *MessageRenderer) twitchEmotes(ctx context.Context, broadcasterID string) (map[string]Emote, error) {
+	if r.client == nil {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+		return r.setEmotes, nil
+	}
+
+	r.mu.RLock()
+	global := r.globalEmotes
+	channel, ok := r.channelEmotes[broadcasterID]
+	r.mu.RUnlock()
+
+	if !r.fresh(global.fetchedAt) {
+		resp, err := r.client.GetGlobalEmotes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		global = renderCacheEntry[map[string]Emote]{value: emotesByID(resp.Data), fetchedAt: time.Now()}
+		r.mu.Lock()
+		r.globalEmotes = global
+		r.mu.Unlock()
+	}
+
+	if broadcasterID != "" && (!ok || !r.fresh(channel.fetchedAt)) {
+		resp, err := r.client.GetChannelEmotes(ctx, broadcasterID)
+		if err != nil {
+			return nil, err
+		}
+		channel = renderCacheEntry[map[string]Emote]{value: emotesByID(resp.Data), fetchedAt: time.Now()}
+		r.mu.Lock()
+		r.channelEmotes[broadcasterID] = channel
+		r.mu.Unlock()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	merged := make(map[string]Emote, len(global.value)+len(channel.value)+len(r.setEmotes))
+	maps.Copy(merged, r.setEmotes)
+	maps.Copy(merged, global.value)
+	maps.Copy(merged, channel.value)
+	return merged, nil
+}
+
+// cheermotesFor returns the broadcaster's cheermotes keyed by lowercase prefix.
+func (r *MessageRenderer) cheermotesFor(ctx context.Context, broadcasterID string) (map[string]Cheermote, error) {
+	r.mu.RLock()
+	entry, ok := r.cheermotes[broadcasterID]
+	r.mu.RUnlock()
+	if ok && r.fresh(entry.fetchedAt) {
+		return entry.value, nil
+	}
+	if r.client == nil {
+		return nil, nil
+	}
+
+	resp, err := r.client.GetCheermotes(ctx, broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	byPrefix := make(map[string]Cheermote, len(resp.Data))
+	for _, c := range resp.Data {
+		byPrefix[strings.ToLower(c.Prefix)] = c
+	}
+
+	r.mu.Lock()
+	r.cheermotes[broadcasterID] = renderCacheEntry[map[string]Cheermote]{value: byPrefix, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return byPrefix, nil
+}
+
+// thirdPartyFor returns the providers' emotes for a broadcaster keyed by name.
+func (r *MessageRenderer) thirdPartyFor(ctx context.Context, broadcasterID string) (map[string]ThirdPartyEmote, error) {
+	if len(r.providers) == 0 {
+		return nil, nil
+	}
+
+	r.mu.RLock()
+	entry, ok := r.thirdParty[broadcasterID]
+	r.mu.RUnlock()
+	if ok && r.fresh(entry.fetchedAt) {
+		return entry.value, nil
+	}
+
+	byName := make(map[string]ThirdPartyEmote)
+	for _, p := range r.providers {
+		emotes, err := p.Emotes(ctx, broadcasterID)
+		if err != nil {
+			return nil, fmt.Errorf("emote provider %s: %w", p.Name(), err)
+		}
+		for _, e := range emotes {
+			if _, exists := byName[e.Name]; exists {
+				continue
+			}
+			if e.Provider == "" {
+				e.Provider = p.Name()
+			}
+			byName[e.Name] = e
+		}
+	}
+
+	r.mu.Lock()
+	r.thirdParty[broadcasterID] = renderCacheEntry[map[string]ThirdPartyEmote]{value: byName, fetchedAt: time.Now()}
+	r.mu.Unlock()
+	return byName, nil
+}
+
+func emotesByID(emotes []Emote) map[string]Emote {
+	m := make(map[string]Emote, len(emotes))
+	for _, e := range emotes {
+		m[e.ID] = e
+	}
+	return m
+}
+
+// imageForScale picks the image matching the renderer's scale, falling back
+// to the next smaller size that is available.
+func (r *MessageRenderer) imageForScale(images EmoteImages) string {
+	candidates := []string{images.URL1x}
+	switch r.scale {
+	case EmoteScale2x:
+		candidates = []string{images.URL2x, images.URL1x}
+	case EmoteScale3x:
+		candidates = []string{images.URL4x, images.URL2x, images.URL1x}
+	}
+	for _, u := range candidates {
+		if u != "" {
+			return u
+		}
+	}
+	return ""
+}
+
+// cheermoteImage picks the tier image for the renderer's theme, format and scale.
+func (r *MessageRenderer) cheermoteImage(tier CheermoteTier) string {
+	theme := tier.Images.Dark
+	if r.theme == EmoteThemeLight {
+		theme = tier.Images.Light
+	}
+	images := theme.Static
+	if r.format == EmoteFormatAnimated && len(theme.Animated) > 0 {
+		images = theme.Animated
+	}
+
+	// Cheermote sizes are keyed 1, 1.5, 2, 3 and 4; the emote 3.0 scale is 4x.
+	key := "1"
+	switch r.scale {
+	case EmoteScale2x:
+		key = "2"
+	case EmoteScale3x:
+		key = "4"
+	}
+	return images[key]
+}
+
+// messageTokenizer accumulates tokens for a single message.
+type messageTokenizer struct {
+	r          *MessageRenderer
+	cheermotes map[string]Cheermote
+	thirdParty map[string]ThirdPartyEmote
+	tokens     []MessageToken
+}
+
+func (r *MessageRenderer) newTokenizer(ctx context.Context, broadcasterID string, cheers bool) (*messageTokenizer, error) {
+	t := &messageTokenizer{r: r}
+
+	var err error
+	if cheers {
+		if t.cheermotes, err = r.cheermotesFor(ctx, broadcasterID); err != nil {
+			return nil, err
+		}
+	}
+	if t.thirdParty, err = r.thirdPartyFor(ctx, broadcasterID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// text appends plain text, merging it with a preceding text token.
+func (t *messageTokenizer) text(s string) {
+	if s == "" {
+		return
+	}
+	if n := len(t.tokens); n > 0 && t.tokens[n-1].Type == MessageTokenText {
+		t.tokens[n-1].Text += s
+		return
+	}
+	t.tokens = append(t.tokens, MessageToken{Type: MessageTokenText, Text: s})
+}
+
+// emote appends a Twitch emote token.
+func (t *messageTokenizer) emote(id, code string, formats []string) {
+	t.tokens = append(t.tokens, MessageToken{
+		Type:     MessageTokenEmote,
+		Text:     code,
+		EmoteID:  id,
+		Provider: EmoteProviderTwitch,
+		ImageURL: t.r.EmoteURL(id, formats),
+	})
+}
+
+// cheer appends a cheermote token if prefix is a known cheermote.
+func (t *messageTokenizer) cheer(code, prefix string, bits int) bool {
+	c, ok := t.cheermotes[strings.ToLower(prefix)]
+	if !ok || bits <= 0 || len(c.Tiers) == 0 {
+		return false
+	}
+
+	tier := c.Tiers[0]
+	for _, candidate := range c.Tiers {
+		if candidate.MinBits <= bits && candidate.MinBits >= tier.MinBits {
+			tier = candidate
+		}
+	}
+
+	t.tokens = append(t.tokens, MessageToken{
+		Type:     MessageTokenCheermote,
+		Text:     code,
+		Provider: EmoteProviderTwitch,
+		ImageURL: t.r.cheermoteImage(tier),
+		Bits:     bits,
+		Color:    tier.Color,
+	})
+	return true
+}
+
+var (
+	cheerWordPattern = regexp.MustCompile(`^([A-Za-z]+?)([0-9]+)$`)
+	mentionPattern   = regexp.MustCompile(`^@([A-Za-z0-9_]+)$`)
+	linkPattern      = regexp.MustCompile(`(?i)^(?:https?://)?(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,24}(?::[0-9]+)?(?:[/?#][^\s]*)?$`)
+)
+
+// words splits free text on whitespace and classifies each word as a
+// cheermote, third-party emote, mention, link or plain text.
+func (t *messageTokenizer) words(s string) {
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsSpace(r) })
+		if i < 0 {
+			t.text(s)
+			return
+		}
+		t.text(s[:i])
+		s = s[i:]
+
+		j := strings.IndexFunc(s, unicode.IsSpace)
+		if j < 0 {
+			j = len(s)
+		}
+		t.word(s[:j])
+		s = s[j:]
+	}
+}
+
+func (t *messageTokenizer) word(w string) {
+	if t.cheermotes != nil {
+		if m := cheerWordPattern.FindStringSubmatch(w); m != nil {
+			bits, _ := strconv.Atoi(m[2])
+			if t.cheer(w, m[1], bits) {
+				return
+			}
+		}
+	}
+
+	if e, ok := t.thirdParty[w]; ok {
+		t.tokens = append(t.tokens, MessageToken{
+			Type:     MessageTokenEmote,
+			Text:     w,
+			EmoteID:  e.ID,
+			Provider: e.Provider,
+			ImageURL: t.r.imageForScale(e.Images),
+		})
+		return
+	}
+
+	// Trailing punctuation belongs to the sentence, not the mention or link.
+	core := strings.TrimRight(w, ".,!?;:)'\"")
+	suffix := w[len(core):]
+
+	if m := mentionPattern.FindStringSubmatch(core); m != nil {
+		t.tokens = append(t.tokens, MessageToken{
+			Type:      MessageTokenMention,
+			Text:      core,
+			UserLogin: strings.ToLower(m[1]),
+		})
+		t.text(suffix)
+		return
+	}
+
+	if u, ok := parseChatLink(core); ok {
+		t.tokens = append(t.tokens, MessageToken{Type: MessageTokenLink, Text: core, URL: u})
+		t.text(suffix)
+		return
+	}
+
+	t.text(w)
+}
+
+// parseChatLink reports whether a word looks like a link and returns it with
+// an https scheme added when none was given.
+func parseChatLink(word string) (string, bool) {
+	if !linkPattern.MatchString(word) {
+		return "", false
+	}
+	lower := strings.ToLower(word)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		return word, true
+	}
+	return "https://" + word, true
+}
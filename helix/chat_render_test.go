@@ -0,0 +1,305 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// renderFixtureHandler serves emote and cheermote fixtures and counts requests per path.
+func renderFixtureHandler(calls map[string]*int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if n, ok := calls[r.URL.Path]; ok {
+			atomic.AddInt32(n, 1)
+		}
+		var data any
+		switch r.URL.Path {
+		case "/chat/emotes/global":
+			data = []Emote{
+				{ID: "25", Name: "Kappa", Format: []string{"static"}},
+			}
+		case "/chat/emotes":
+			data = []Emote{
+				{ID: "emotesv2_abc", Name: "chanHype", Format: []string{"static", "animated"}},
+			}
+		case "/chat/emotes/set":
+			data = []Emote{
+				{ID: "emotesv2_set", Name: "setDance", Format: []string{"static", "animated"}},
+			}
+		case "/bits/cheermotes":
+			data = []Cheermote{{
+				Prefix: "Cheer",
+				Tiers: []CheermoteTier{
+					{MinBits: 1, ID: "1", Color: "#979797", Images: CheermoteImages{
+						Dark: CheermoteTheme{
+							Static:   map[string]string{"1": "cheer1-dark-static-1", "4": "cheer1-dark-static-4"},
+							Animated: map[string]string{"1": "cheer1-dark-animated-1"},
+						},
+					}},
+					{MinBits: 100, ID: "100", Color: "#9c3ee8", Images: CheermoteImages{
+						Dark: CheermoteTheme{
+							Static:   map[string]string{"1": "cheer100-dark-static-1"},
+							Animated: map[string]string{"1": "cheer100-dark-animated-1"},
+						},
+						Light: CheermoteTheme{
+							Static: map[string]string{"1": "cheer100-light-static-1"},
+						},
+					}},
+				},
+			}}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}
+}
+
+func tokenTypes(tokens []MessageToken) string {
+	types := make([]string, len(tokens))
+	for i, t := range tokens {
+		types[i] = string(t.Type)
+	}
+	return strings.Join(types, ",")
+}
+
+func tokenText(tokens []MessageToken) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Text)
+	}
+	return b.String()
+}
+
+func TestMessageRenderer_RenderIRC(t *testing.T) {
+	calls := map[string]*int32{"/bits/cheermotes": new(int32)}
+	client, server := newTestClient(renderFixtureHandler(calls))
+	defer server.Close()
+
+	r := NewMessageRenderer(client, WithEmoteProvider(NewStaticEmoteProvider("7tv",
+		ThirdPartyEmote{ID: "7tv1", Name: "catJAM", Images: EmoteImages{URL1x: "https://cdn.7tv.app/emote/7tv1/1x.webp"}},
+	)))
+
+	msg := &ChatMessage{
+		RoomID:  "1234",
+		Message: "Kappa hi @Friend, cheer100 catJAM see twitch.tv/foo.",
+		Emotes:  []IRCEmote{{ID: "25", Start: 0, End: 4}},
+		Bits:    100,
+	}
+
+	tokens, err := r.Render(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	if got := tokenText(tokens); got != msg.Message {
+		t.Errorf("tokens do not reproduce message: %q", got)
+	}
+	want := "emote,text,mention,text,cheermote,text,emote,text,link,text"
+	if got := tokenTypes(tokens); got != want {
+		t.Fatalf("token types = %s, want %s", got, want)
+	}
+
+	if tokens[0].ImageURL != "https://static-cdn.jtvnw.net/emoticons/v2/25/static/dark/1.0" {
+		t.Errorf("emote URL = %s", tokens[0].ImageURL)
+	}
+	if tokens[2].UserLogin != "friend" || tokens[2].Text != "@Friend" {
+		t.Errorf("mention = %+v", tokens[2])
+	}
+	if tokens[4].Bits != 100 || tokens[4].Color != "#9c3ee8" || tokens[4].ImageURL != "cheer100-dark-static-1" {
+		t.Errorf("cheermote = %+v", tokens[4])
+	}
+	if tokens[6].Provider != "7tv" || tokens[6].EmoteID != "7tv1" {
+		t.Errorf("third-party emote = %+v", tokens[6])
+	}
+	if tokens[8].URL != "https://twitch.tv/foo" || tokens[8].Text != "twitch.tv/foo" {
+		t.Errorf("link = %+v", tokens[8])
+	}
+
+	// Cheermotes are cached per broadcaster
+	if _, err := r.Render(context.Background(), msg); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if n := atomic.LoadInt32(calls["/bits/cheermotes"]); n != 1 {
+		t.Errorf("GetCheermotes called %d times, want 1", n)
+	}
+}
+
+func TestMessageRenderer_NoBitsSkipsCheermotes(t *testing.T) {
+	calls := map[string]*int32{"/bits/cheermotes": new(int32)}
+	client, server := newTestClient(renderFixtureHandler(calls))
+	defer server.Close()
+
+	r := NewMessageRenderer(client)
+	tokens, err := r.Render(context.Background(), &ChatMessage{RoomID: "1234", Message: "cheer100 please"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if got := tokenTypes(tokens); got != "text" {
+		t.Errorf("token types = %s, want text", got)
+	}
+	if n := atomic.LoadInt32(calls["/bits/cheermotes"]); n != 0 {
+		t.Errorf("GetCheermotes called %d times, want 0", n)
+	}
+}
+
+func TestMessageRenderer_AnimatedFormat(t *testing.T) {
+	calls := map[string]*int32{
+		"/chat/emotes/global": new(int32),
+		"/chat/emotes":        new(int32),
+	}
+	client, server := newTestClient(renderFixtureHandler(calls))
+	defer server.Close()
+
+	r := NewMessageRenderer(client,
+		WithRenderFormat(EmoteFormatAnimated),
+		WithRenderTheme(EmoteThemeLight),
+		WithRenderScale(EmoteScale3x),
+	)
+	if err := r.LoadEmoteSets(context.Background(), "set1"); err != nil {
+		t.Fatalf("LoadEmoteSets() error = %v", err)
+	}
+
+	msg := &ChatMessage{
+		RoomID:  "1234",
+		Message: "Kappa chanHype setDance",
+		Emotes: []IRCEmote{
+			{ID: "emotesv2_set", Start: 15, End: 22},
+			{ID: "25", Start: 0, End: 4},
+			{ID: "emotesv2_abc", Start: 6, End: 13},
+		},
+	}
+	tokens, err := r.Render(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := []string{
+		"https://static-cdn.jtvnw.net/emoticons/v2/25/static/light/3.0",
+		"https://static-cdn.jtvnw.net/emoticons/v2/emotesv2_abc/animated/light/3.0",
+		"https://static-cdn.jtvnw.net/emoticons/v2/emotesv2_set/animated/light/3.0",
+	}
+	var got []string
+	for _, tok := range tokens {
+		if tok.Type == MessageTokenEmote {
+			got = append(got, tok.ImageURL)
+		}
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("emote URLs = %v, want %v", got, want)
+	}
+
+	if _, err := r.Render(context.Background(), msg); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if n := atomic.LoadInt32(calls["/chat/emotes/global"]); n != 1 {
+		t.Errorf("GetGlobalEmotes called %d times, want 1", n)
+	}
+
+	r.InvalidateChannel("1234")
+	if _, err := r.Render(context.Background(), msg); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if n := atomic.LoadInt32(calls["/chat/emotes"]); n != 2 {
+		t.Errorf("GetChannelEmotes called %d times, want 2", n)
+	}
+}
+
+func TestMessageRenderer_RenderEvent(t *testing.T) {
+	client, server := newTestClient(renderFixtureHandler(nil))
+	defer server.Close()
+
+	r := NewMessageRenderer(client, WithRenderFormat(EmoteFormatAnimated))
+	event := &ChannelChatMessageEvent{
+		EventSubBroadcaster: EventSubBroadcaster{BroadcasterUserID: "1234"},
+		Message: ChatEventMessage{
+			Text: "Cheer5 LUL @bob https://example.com",
+			Fragments: []ChatEventFragment{
+				{Type: "cheermote", Text: "Cheer5", Cheermote: &ChatEventCheermote{Prefix: "cheer", Bits: 5, Tier: 1}},
+				{Type: "text", Text: " "},
+				{Type: "emote", Text: "LUL", Emote: &ChatEventEmote{ID: "425618", Format: []string{"static"}}},
+				{Type: "text", Text: " "},
+				{Type: "mention", Text: "@bob", Mention: &ChatEventMention{UserID: "99", UserLogin: "bob", UserName: "Bob"}},
+				{Type: "text", Text: " https://example.com"},
+			},
+		},
+	}
+
+	tokens, err := r.RenderEvent(context.Background(), event)
+	if err != nil {
+		t.Fatalf("RenderEvent() error = %v", err)
+	}
+	if got := tokenTypes(tokens); got != "cheermote,text,emote,text,mention,text,link" {
+		t.Fatalf("token types = %s", got)
+	}
+	if tokens[0].ImageURL != "cheer1-dark-animated-1" || tokens[0].Bits != 5 {
+		t.Errorf("cheermote = %+v", tokens[0])
+	}
+	if tokens[2].ImageURL != "https://static-cdn.jtvnw.net/emoticons/v2/425618/static/dark/1.0" {
+		t.Errorf("emote URL = %s", tokens[2].ImageURL)
+	}
+	if tokens[4].UserID != "99" {
+		t.Errorf("mention = %+v", tokens[4])
+	}
+	if tokens[6].URL != "https://example.com" {
+		t.Errorf("link = %+v", tokens[6])
+	}
+}
+
+type failingEmoteProvider struct{}
+
+func (failingEmoteProvider) Name() string { return "broken" }
+
+func (failingEmoteProvider) Emotes(context.Context, string) ([]ThirdPartyEmote, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestMessageRenderer_ProviderError(t *testing.T) {
+	r := NewMessageRenderer(nil, WithEmoteProvider(failingEmoteProvider{}))
+	_, err := r.Render(context.Background(), &ChatMessage{Message: "hello"})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Render() error = %v, want provider error", err)
+	}
+}
+
+func TestMessageRenderer_ProviderOrderAndScale(t *testing.T) {
+	r := NewMessageRenderer(nil,
+		WithRenderScale(EmoteScale3x),
+		WithEmoteProvider(NewStaticEmoteProvider("first", ThirdPartyEmote{ID: "a", Name: "pepeD", Images: EmoteImages{URL1x: "a1", URL2x: "a2"}})),
+		WithEmoteProvider(NewStaticEmoteProvider("second", ThirdPartyEmote{ID: "b", Name: "pepeD"})),
+	)
+	tokens, err := r.Render(context.Background(), &ChatMessage{Message: "pepeD"})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Provider != "first" || tokens[0].ImageURL != "a2" {
+		t.Errorf("tokens = %+v", tokens)
+	}
+}
+
+func TestParseChatLink(t *testing.T) {
+	tests := []struct {
+		word string
+		url  string
+		ok   bool
+	}{
+		{"https://twitch.tv", "https://twitch.tv", true},
+		{"HTTP://example.com/a?b=c", "HTTP://example.com/a?b=c", true},
+		{"clips.twitch.tv/abc", "https://clips.twitch.tv/abc", true},
+		{"example.com:8080/x", "https://example.com:8080/x", true},
+		{"hello", "", false},
+		{"1.5", "", false},
+		{"e.g", "", false},
+	}
+	for _, tt := range tests {
+		url, ok := parseChatLink(tt.word)
+		if ok != tt.ok || url != tt.url {
+			t.Errorf("parseChatLink(%q) = %q, %v; want %q, %v", tt.word, url, ok, tt.url, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package helix
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default chat vote settings
+const (
+	defaultChatVoteCommand   = "!vote"
+	maxChatAnnouncementRunes = 500
+)
+
+// ErrChatVoteNotActive is returned when ending a chat poll or prediction that
+// isn't running or has already been decided.
+var ErrChatVoteNotActive = errors.New("chat vote is not active")
+
+// ErrChatVoteStarted is returned when starting a chat poll or prediction a
+// second time.
+var ErrChatVoteStarted = errors.New("chat vote already started")
+
+// chatVoteConfig holds the settings shared by chat polls and predictions.
+type chatVoteConfig struct {
+	moderatorID      string
+	announceInterval time.Duration
+	color            string
+	command          string
+	onError          func(error)
+}
+
+// ChatVoteOption configures a ChatPoll or ChatPrediction.
+type ChatVoteOption func(*chatVoteConfig)
+
+// WithChatVoteAnnouncements announces the start, the live tallies (every
+// interval, when they changed), and the result with Send Chat Announcement,
+// sent as moderatorID. Tallies are not announced if interval is 0.
+func WithChatVoteAnnouncements(moderatorID string, interval time.Duration) ChatVoteOption {
+	return func(c *chatVoteConfig) {
+		c.moderatorID = moderatorID
+		c.announceInterval = interval
+	}
+}
+
+// WithChatVoteAnnouncementColor sets the announcement color: blue, green,
+// orange, purple, or primary (default).
+func WithChatVoteAnnouncementColor(color string) ChatVoteOption {
+	return func(c *chatVoteConfig) {
+		c.color = color
+	}
+}
+
+// WithChatVoteCommand sets the optional command prefix for votes (default
+// "!vote"). Votes are accepted with or without it.
+func WithChatVoteCommand(command string) ChatVoteOption {
+	return func(c *chatVoteConfig) {
+		c.command = command
+	}
+}
+
+// WithChatVoteErrorHandler sets the handler for announcement errors.
+func WithChatVoteErrorHandler(fn func(error)) ChatVoteOption {
+	return func(c *chatVoteConfig) {
+		c.onError = fn
+	}
+}
+
+// chatVote collects one vote per user for a fixed list of options.
+type chatVote struct {
+	client        *Client
+	broadcasterID string
+	cfg           chatVoteConfig
+	titles        []string
+
+	mu      sync.Mutex
+	voters  map[string]int // user ID -> option index
+	logins  map[string]string
+	started bool // Start was called; a vote is never restarted
+	open    bool
+	dirty   bool // Votes since the last tally announcement
+	stop    chan struct{}
+	timer   *time.Timer
+}
+
+func newChatVote(client *Client, broadcasterID string, titles []string, opts []ChatVoteOption) *chatVote {
+	v := &chatVote{
+		client:        client,
+		broadcasterID: broadcasterID,
+		cfg:           chatVoteConfig{command: defaultChatVoteCommand},
+		titles:        titles,
+		voters:        make(map[string]int),
+		logins:        make(map[string]string),
+		stop:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&v.cfg)
+	}
+	return v
+}
+
+// parse returns the option a message votes for: its number or title, with or
+// without the vote command.
+func (v *chatVote) parse(text string) (int, bool) {
+	text = strings.TrimSpace(text)
+	if v.cfg.command != "" {
+		if rest, ok := strings.CutPrefix(text, v.cfg.command); ok && (rest == "" || rest[0] == ' ') {
+			text = strings.TrimSpace(rest)
+		}
+	}
+	if n, err := strconv.Atoi(text); err == nil {
+		if n >= 1 && n <= len(v.titles) {
+			return n - 1, true
+		}
+		return 0, false
+	}
+	for i, title := range v.titles {
+		if strings.EqualFold(text, title) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// vote records a chat message's vote, returning the option index. Messages
+// from other channels, repeat votes, and votes while closed are ignored.
+func (v *chatVote) vote(msg *ChatMessage) (int, bool) {
+	if msg.RoomID != "" && msg.RoomID != v.broadcasterID {
+		return 0, false
+	}
+	idx, ok := v.parse(msg.Message)
+	if !ok {
+		return 0, false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.open {
+		return 0, false
+	}
+	if _, voted := v.voters[msg.UserID]; voted {
+		return 0, false
+	}
+	v.voters[msg.UserID] = idx
+	v.logins[msg.UserID] = msg.User
+	v.dirty = true
+	return idx, true
+}
+
+// start opens voting, closing it by calling onExpire after d, and announces
+// the tallies returned by tally every announcement interval. onStart runs
+// before voting opens. It returns ErrChatVoteStarted if the vote was already
+// started.
+func (v *chatVote) start(d time.Duration, onStart, onExpire func(), tally func() string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.started {
+		return ErrChatVoteStarted
+	}
+	v.started = true
+	onStart()
+	v.open = true
+	if d > 0 {
+		v.timer = time.AfterFunc(d, onExpire)
+	}
+	if v.cfg.moderatorID == "" || v.cfg.announceInterval <= 0 {
+		return nil
+	}
+	go func() {
+		ticker := time.NewTicker(v.cfg.announceInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				v.mu.Lock()
+				dirty := v.dirty
+				v.dirty = false
+				v.mu.Unlock()
+				if dirty {
+					v.announce(context.Background(), tally())
+				}
+			case <-v.stop:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// isStarted reports whether start was called.
+func (v *chatVote) isStarted() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.started
+}
+
+// close stops voting. It returns false if voting was already closed.
+func (v *chatVote) close() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if !v.open {
+		return false
+	}
+	v.open = false
+	if v.timer != nil {
+		v.timer.Stop()
+	}
+	close(v.stop)
+	return true
+}
+
+// votersFor returns the logins of the users who voted for an option.
+func (v *chatVote) votersFor(idx int) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	var logins []string
+	for userID, i := range v.voters {
+		if i == idx {
+			logins = append(logins, v.logins[userID])
+		}
+	}
+	return logins
+}
+
+// announce sends an announcement if announcements are enabled.
+func (v *chatVote) announce(ctx context.Context, message string) {
+	if v.cfg.moderatorID == "" {
+		return
+	}
+	if r := []rune(message); len(r) > maxChatAnnouncementRunes {
+		message = string(r[:maxChatAnnouncementRunes])
+	}
+	err := v.client.SendChatAnnouncement(ctx, &SendChatAnnouncementParams{
+		BroadcasterID: v.broadcasterID,
+		ModeratorID:   v.cfg.moderatorID,
+		Message:       message,
+		Color:         v.cfg.color,
+	})
+	if err != nil && v.cfg.onError != nil {
+		v.cfg.onError(fmt.Errorf("sending announcement: %w", err))
+	}
+}
+
+// chatMessageFromData converts a raw channel.chat.message event for HandleEvent.
+func chatMessageFromData(eventType string, data json.RawMessage) (*ChatMessage, error) {
+	if eventType != EventSubTypeChannelChatMessage {
+		return nil, nil
+	}
+	event, err := ParseWSEvent[ChannelChatMessageEvent](data)
+	if err != nil {
+		return nil, err
+	}
+	return chatMessageFromEvent(event, string(data), time.Now()), nil
+}
+
+// formatTally formats option counts as "1) Yes: 3 (60%) | 2) No: 2 (40%)".
+func formatTally(titles []string, counts []int) string {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	parts := make([]string, len(titles))
+	for i, title := range titles {
+		pct := 0
+		if total > 0 {
+			pct = counts[i] * 100 / total
+		}
+		parts[i] = fmt.Sprintf("%d) %s: %d (%d%%)", i+1, title, counts[i], pct)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// formatOptions formats options as "1) Yes, 2) No".
+func formatOptions(titles []string) string {
+	parts := make([]string, len(titles))
+	for i, title := range titles {
+		parts[i] = fmt.Sprintf("%d) %s", i+1, title)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ChatPoll runs a poll through chat, for channels that can't use Helix polls
+// (which need affiliate or partner status). Viewers vote once by sending the
+// number or title of a choice, optionally after the vote command, and the
+// results use the same Poll type as Helix polls.
+//
+// Feed it chat messages with HandleMessage (for example from
+// ChatBotClient.OnMessage) or channel.chat.message events with HandleEvent.
+type ChatPoll struct {
+	vote     *chatVote
+	state    *liveState[Poll]
+	duration time.Duration
+}
+
+// NewChatPoll creates a chat poll from the same parameters as CreatePoll.
+// Bits and channel points voting are not supported and are ignored. Call
+// Start to open voting.
+func NewChatPoll(client *Client, params *CreatePollParams, opts ...ChatVoteOption) *ChatPoll {
+	titles := make([]string, len(params.Choices))
+	choices := make([]PollChoice, len(params.Choices))
+	for i, c := range params.Choices {
+		titles[i] = c.Title
+		choices[i] = PollChoice{ID: strconv.Itoa(i + 1), Title: c.Title}
+	}
+	poll := Poll{
+		ID:            "chat-" + rand.Text(),
+		BroadcasterID: params.BroadcasterID,
+		Title:         params.Title,
+		Choices:       choices,
+		Duration:      params.Duration,
+	}
+	return &ChatPoll{
+		vote:     newChatVote(client, params.BroadcasterID, titles, opts),
+		state:    newLiveState(poll.ID, poll, clonePoll),
+		duration: time.Duration(params.Duration) * time.Second,
+	}
+}
+
+// Start opens voting for the poll's duration and announces the poll. A poll
+// runs once; starting it again returns ErrChatVoteStarted.
+func (p *ChatPoll) Start(ctx context.Context) error {
+	if len(p.vote.titles) < 2 {
+		return errors.New("chat poll needs at least 2 choices")
+	}
+	err := p.vote.start(p.duration, func() {
+		p.state.update(func(poll *Poll) bool {
+			poll.Status = PollStatusActive
+			poll.StartedAt = time.Now()
+			return false
+		})
+	}, func() {
+		_ = p.finish(context.Background(), PollStatusCompleted)
+	}, p.tally)
+	if err != nil {
+		return err
+	}
+	snapshot := p.Snapshot()
+	p.vote.announce(ctx, fmt.Sprintf("Poll: %s Vote in chat with %s", snapshot.Title, formatOptions(p.vote.titles)))
+	return nil
+}
+
+// ID returns the poll's generated ID.
+func (p *ChatPoll) ID() string {
+	return p.state.id
+}
+
+// Snapshot returns the latest state of the poll.
+func (p *ChatPoll) Snapshot() Poll {
+	return p.state.get()
+}
+
+// Updates returns a channel that receives a snapshot after each change. Only
+// the latest snapshot is kept if the receiver falls behind. The channel is
+// closed after the final snapshot once the poll ends.
+func (p *ChatPoll) Updates() <-chan Poll {
+	return p.state.updates
+}
+
+// Done returns a channel that is closed when the poll ends.
+func (p *ChatPoll) Done() <-chan struct{} {
+	return p.state.done
+}
+
+// Wait blocks until the poll ends and returns its final state.
+func (p *ChatPoll) Wait(ctx context.Context) (Poll, error) {
+	return p.state.wait(ctx)
+}
+
+// HandleMessage counts the vote in a chat message, if it has one. It reports
+// whether a vote was counted.
+func (p *ChatPoll) HandleMessage(msg *ChatMessage) bool {
+	idx, ok := p.vote.vote(msg)
+	if ok {
+		p.state.update(func(poll *Poll) bool {
+			poll.Choices[idx].Votes++
+			return false
+		})
+	}
+	return ok
+}
+
+// HandleEvent counts the vote in a raw channel.chat.message event. Other event
+// types are ignored.
+func (p *ChatPoll) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	msg, err := chatMessageFromData(eventType, data)
+	if msg != nil {
+		p.HandleMessage(msg)
+	}
+	return err
+}
+
+// End ends the poll early (TERMINATED) and announces the result.
+func (p *ChatPoll) End(ctx context.Context) error {
+	return p.finish(ctx, PollStatusTerminated)
+}
+
+func (p *ChatPoll) finish(ctx context.Context, status string) error {
+	if !p.vote.close() {
+		return ErrChatVoteNotActive
+	}
+	var final Poll
+	p.state.update(func(poll *Poll) bool {
+		poll.Status = status
+		poll.EndedAt = NewNullableTime(time.Now())
+		final = clonePoll(*poll)
+		return true
+	})
+
+	winner, top := "", -1
+	tie := false
+	for _, c := range final.Choices {
+		switch {
+		case c.Votes > top:
+			winner, top, tie = c.Title, c.Votes, false
+		case c.Votes == top:
+			tie = true
+		}
+	}
+	result := fmt.Sprintf("%s wins!", winner)
+	if top == 0 {
+		result = "No votes."
+	} else if tie {
+		result = "It's a tie!"
+	}
+	p.vote.announce(ctx, fmt.Sprintf("Poll ended: %s %s %s", final.Title, result, p.tally()))
+	return nil
+}
+
+// tally formats the current vote counts.
+func (p *ChatPoll) tally() string {
+	snapshot := p.Snapshot()
+	counts := make([]int, len(snapshot.Choices))
+	for i, c := range snapshot.Choices {
+		counts[i] = c.Votes
+	}
+	return formatTally(p.vote.titles, counts)
+}
+
+// ChatPrediction runs a prediction through chat, without channel points, for
+// channels that can't use Helix predictions. Viewers predict once by sending
+// the number or title of an outcome, optionally after the vote command, and
+// the results use the same Prediction type as Helix predictions, with each
+// outcome's Users counting its predictors.
+//
+// Feed it chat messages with HandleMessage or channel.chat.message events
+// with HandleEvent.
+type ChatPrediction struct {
+	vote   *chatVote
+	state  *liveState[Prediction]
+	window time.Duration
+}
+
+// NewChatPrediction creates a chat prediction from the same parameters as
+// CreatePrediction. Call Start to open predictions.
+func NewChatPrediction(client *Client, params *CreatePredictionParams, opts ...ChatVoteOption) *ChatPrediction {
+	titles := make([]string, len(params.Outcomes))
+	outcomes := make([]PredictionOutcome, len(params.Outcomes))
+	for i, o := range params.Outcomes {
+		titles[i] = o.Title
+		outcomes[i] = PredictionOutcome{ID: strconv.Itoa(i + 1), Title: o.Title, Color: "BLUE"}
+	}
+	if len(outcomes) == 2 {
+		outcomes[1].Color = "PINK"
+	}
+	prediction := Prediction{
+		ID:               "chat-" + rand.Text(),
+		BroadcasterID:    params.BroadcasterID,
+		Title:            params.Title,
+		Outcomes:         outcomes,
+		PredictionWindow: params.PredictionWindow,
+	}
+	return &ChatPrediction{
+		vote:   newChatVote(client, params.BroadcasterID, titles, opts),
+		state:  newLiveState(prediction.ID, prediction, clonePrediction),
+		window: time.Duration(params.PredictionWindow) * time.Second,
+	}
+}
+
+// Start opens predictions for the prediction window, after which the
+// prediction locks, and announces the prediction. A prediction runs once;
+// starting it again returns ErrChatVoteStarted.
+func (p *ChatPrediction) Start(ctx context.Context) error {
+	if len(p.vote.titles) < 2 {
+		return errors.New("chat prediction needs at least 2 outcomes")
+	}
+	err := p.vote.start(p.window, func() {
+		p.state.update(func(prediction *Prediction) bool {
+			prediction.Status = PredictionStatusActive
+			prediction.CreatedAt = time.Now()
+			return false
+		})
+	}, func() {
+		_ = p.Lock(context.Background())
+	}, p.tally)
+	if err != nil {
+		return err
+	}
+	snapshot := p.Snapshot()
+	p.vote.announce(ctx, fmt.Sprintf("Prediction: %s Predict in chat with %s", snapshot.Title, formatOptions(p.vote.titles)))
+	return nil
+}
+
+// ID returns the prediction's generated ID.
+func (p *ChatPrediction) ID() string {
+	return p.state.id
+}
+
+// Snapshot returns the latest state of the prediction.
+func (p *ChatPrediction) Snapshot() Prediction {
+	return p.state.get()
+}
+
+// Updates returns a channel that receives a snapshot after each change. Only
+// the latest snapshot is kept if the receiver falls behind. The channel is
+// closed after the final snapshot once the prediction is resolved or canceled.
+func (p *ChatPrediction) Updates() <-chan Prediction {
+	return p.state.updates
+}
+
+// Done returns a channel that is closed when the prediction is resolved or
+// canceled.
+func (p *ChatPrediction) Done() <-chan struct{} {
+	return p.state.done
+}
+
+// Wait blocks until the prediction is resolved or canceled and returns its
+// final state.
+func (p *ChatPrediction) Wait(ctx context.Context) (Prediction, error) {
+	return p.state.wait(ctx)
+}
+
+// HandleMessage counts the prediction in a chat message, if it has one. It
+// reports whether a prediction was counted.
+func (p *ChatPrediction) HandleMessage(msg *ChatMessage) bool {
+	idx, ok := p.vote.vote(msg)
+	if ok {
+		p.state.update(func(prediction *Prediction) bool {
+			prediction.Outcomes[idx].Users++
+			return false
+		})
+	}
+	return ok
+}
+
+// HandleEvent counts the prediction in a raw channel.chat.message event.
+// Other event types are ignored.
+func (p *ChatPrediction) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	msg, err := chatMessageFromData(eventType, data)
+	if msg != nil {
+		p.HandleMessage(msg)
+	}
+	return err
+}
+
+// Lock stops accepting predictions and announces the totals.
+func (p *ChatPrediction) Lock(ctx context.Context) error {
+	if !p.vote.close() {
+		return ErrChatVoteNotActive
+	}
+	p.state.update(func(prediction *Prediction) bool {
+		prediction.Status = PredictionStatusLocked
+		prediction.LockedAt = NewNullableTime(time.Now())
+		return false
+	})
+	p.vote.announce(ctx, fmt.Sprintf("Predictions are locked! %s", p.tally()))
+	return nil
+}
+
+// Resolve resolves the prediction with the outcome whose title matches
+// outcomeTitle (case-insensitive), locking it first if needed, and announces
+// the winners.
+func (p *ChatPrediction) Resolve(ctx context.Context, outcomeTitle string) error {
+	idx := -1
+	for i, title := range p.vote.titles {
+		if strings.EqualFold(title, outcomeTitle) {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("%w: %q", ErrPredictionOutcomeNotFound, outcomeTitle)
+	}
+	if !p.end(PredictionStatusResolved, strconv.Itoa(idx+1)) {
+		return ErrChatVoteNotActive
+	}
+	winners := len(p.vote.votersFor(idx))
+	p.vote.announce(ctx, fmt.Sprintf("Prediction resolved: %s %d %s predicted %s!", p.Snapshot().Title, winners, pluralize(winners, "viewer", "viewers"), p.vote.titles[idx]))
+	return nil
+}
+
+// Cancel cancels the prediction.
+func (p *ChatPrediction) Cancel(ctx context.Context) error {
+	if !p.end(PredictionStatusCanceled, "") {
+		return ErrChatVoteNotActive
+	}
+	p.vote.announce(ctx, fmt.Sprintf("Prediction canceled: %s", p.Snapshot().Title))
+	return nil
+}
+
+// Predictors returns the logins of the viewers who predicted an outcome,
+// identified by title (case-insensitive).
+func (p *ChatPrediction) Predictors(outcomeTitle string) []string {
+	for i, title := range p.vote.titles {
+		if strings.EqualFold(title, outcomeTitle) {
+			return p.vote.votersFor(i)
+		}
+	}
+	return nil
+}
+
+// end closes the prediction with a final status. It returns false if the
+// prediction was never started or was already resolved or canceled.
+func (p *ChatPrediction) end(status, winningOutcomeID string) bool {
+	if !p.vote.isStarted() {
+		return false
+	}
+	p.vote.close()
+	return p.state.update(func(prediction *Prediction) bool {
+		prediction.Status = status
+		prediction.WinningOutcomeID = winningOutcomeID
+		prediction.EndedAt = NewNullableTime(time.Now())
+		if !prediction.LockedAt.Valid {
+			prediction.LockedAt = prediction.EndedAt
+		}
+		return true
+	})
+}
+
+// tally formats the current prediction counts.
+func (p *ChatPrediction) tally() string {
+	snapshot := p.Snapshot()
+	counts := make([]int, len(snapshot.Outcomes))
+	for i, o := range snapshot.Outcomes {
+		counts[i] = o.Users
+	}
+	return formatTally(p.vote.titles, counts)
+}
+
+// pluralize returns singular when n is 1 and plural otherwise.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
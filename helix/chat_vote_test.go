@@ -0,0 +1,244 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func announcementHandler(t *testing.T, announcements *[]string) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/announcements" || r.URL.Query().Get("moderator_id") != "mod1" {
+			t.Errorf("unexpected request %s %s", r.URL.Path, r.URL.RawQuery)
+		}
+		var body SendChatAnnouncementParams
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		*announcements = append(*announcements, body.Message)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestChatPoll(t *testing.T) {
+	var announcements []string
+	client, server := newTestClient(announcementHandler(t, &announcements))
+	defer server.Close()
+
+	poll := NewChatPoll(client, &CreatePollParams{
+		BroadcasterID: "1234",
+		Title:         "Best map?",
+		Choices:       []CreatePollChoice{{Title: "Dust"}, {Title: "Mirage"}},
+		Duration:      60,
+	}, WithChatVoteAnnouncements("mod1", 0))
+	ctx := context.Background()
+
+	msg := func(userID, text string) *ChatMessage {
+		return &ChatMessage{RoomID: "1234", UserID: userID, User: "user" + userID, Message: text}
+	}
+	if poll.HandleMessage(msg("u0", "1")) {
+		t.Error("vote counted before Start")
+	}
+	if err := poll.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	votes := []struct {
+		msg  *ChatMessage
+		want bool
+	}{
+		{msg("u1", "1"), true},
+		{msg("u1", "2"), false}, // one vote per user
+		{msg("u2", "!vote mirage"), true},
+		{msg("u3", " MIRAGE "), true},
+		{msg("u4", "3"), false},
+		{msg("u5", "I like dust"), false},
+		{msg("u6", "!voted 1"), false},
+		{&ChatMessage{RoomID: "999", UserID: "u7", Message: "1"}, false},
+	}
+	for _, v := range votes {
+		if got := poll.HandleMessage(v.msg); got != v.want {
+			t.Errorf("HandleMessage(%q) = %v, want %v", v.msg.Message, got, v.want)
+		}
+	}
+
+	// EventSub chat messages count too
+	event := `{"broadcaster_user_id":"1234","chatter_user_id":"u8","chatter_user_login":"eight","message_id":"m1","message":{"text":"2","fragments":[]}}`
+	if err := poll.HandleEvent(ctx, EventSubTypeChannelChatMessage, json.RawMessage(event)); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := poll.Snapshot()
+	if snapshot.Status != PollStatusActive || snapshot.Choices[0].Votes != 1 || snapshot.Choices[1].Votes != 3 {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+
+	if err := poll.End(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := poll.End(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("second End() error = %v", err)
+	}
+	final, _ := poll.Wait(ctx)
+	if final.Status != PollStatusTerminated || !final.EndedAt.Valid {
+		t.Errorf("final = %+v", final)
+	}
+	if len(announcements) != 2 || !strings.Contains(announcements[0], "1) Dust, 2) Mirage") ||
+		announcements[1] != "Poll ended: Best map? Mirage wins! 1) Dust: 1 (25%) | 2) Mirage: 3 (75%)" {
+		t.Errorf("announcements = %q", announcements)
+	}
+}
+
+func TestChatPoll_Expires(t *testing.T) {
+	poll := NewChatPoll(nil, &CreatePollParams{
+		Title:   "Quick",
+		Choices: []CreatePollChoice{{Title: "A"}, {Title: "B"}},
+	})
+	poll.duration = 10 * time.Millisecond
+	if err := poll.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	final, err := poll.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Status != PollStatusCompleted {
+		t.Errorf("status = %s", final.Status)
+	}
+}
+
+func TestChatPrediction(t *testing.T) {
+	var announcements []string
+	client, server := newTestClient(announcementHandler(t, &announcements))
+	defer server.Close()
+
+	prediction := NewChatPrediction(client, &CreatePredictionParams{
+		BroadcasterID:    "1234",
+		Title:            "Win?",
+		Outcomes:         []CreatePredictionOutcome{{Title: "Yes"}, {Title: "No"}},
+		PredictionWindow: 120,
+	}, WithChatVoteAnnouncements("mod1", 0), WithChatVoteCommand("!predict"))
+	ctx := context.Background()
+	if err := prediction.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, text := range []string{"!predict yes", "1", "no", "!vote 1"} {
+		prediction.HandleMessage(&ChatMessage{UserID: string(rune('a' + i)), User: "user" + string(rune('a'+i)), Message: text})
+	}
+	if err := prediction.Lock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if prediction.HandleMessage(&ChatMessage{UserID: "late", Message: "1"}) {
+		t.Error("prediction counted after lock")
+	}
+	snapshot := prediction.Snapshot()
+	if snapshot.Status != PredictionStatusLocked || snapshot.Outcomes[0].Users != 2 || snapshot.Outcomes[1].Users != 1 || snapshot.Outcomes[1].Color != "PINK" {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+
+	if err := prediction.Resolve(ctx, "maybe"); !errors.Is(err, ErrPredictionOutcomeNotFound) {
+		t.Errorf("Resolve(maybe) error = %v", err)
+	}
+	if err := prediction.Resolve(ctx, "yes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := prediction.Cancel(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("Cancel() after Resolve error = %v", err)
+	}
+	final, _ := prediction.Wait(ctx)
+	if final.Status != PredictionStatusResolved || final.WinningOutcomeID != "1" {
+		t.Errorf("final = %+v", final)
+	}
+	if winners := prediction.Predictors("Yes"); len(winners) != 2 {
+		t.Errorf("Predictors(Yes) = %v", winners)
+	}
+	if len(announcements) != 3 || announcements[2] != "Prediction resolved: Win? 2 viewers predicted Yes!" {
+		t.Errorf("announcements = %q", announcements)
+	}
+}
+
+func TestChatPoll_StartOnce(t *testing.T) {
+	ctx := context.Background()
+	poll := NewChatPoll(nil, &CreatePollParams{
+		BroadcasterID: "1234",
+		Choices:       []CreatePollChoice{{Title: "Dust"}, {Title: "Mirage"}},
+		Duration:      60,
+	})
+	if err := poll.End(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("End before Start = %v", err)
+	}
+	if err := poll.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := poll.Start(ctx); !errors.Is(err, ErrChatVoteStarted) {
+		t.Errorf("second Start = %v", err)
+	}
+	if err := poll.End(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Neither restarts nor closes voting again
+	if err := poll.Start(ctx); !errors.Is(err, ErrChatVoteStarted) {
+		t.Errorf("Start after End = %v", err)
+	}
+	if err := poll.End(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("second End = %v", err)
+	}
+	if s := poll.Snapshot().Status; s != PollStatusTerminated {
+		t.Errorf("status = %s", s)
+	}
+}
+
+func TestChatPrediction_StartOnce(t *testing.T) {
+	ctx := context.Background()
+	newPrediction := func() *ChatPrediction {
+		return NewChatPrediction(nil, &CreatePredictionParams{
+			BroadcasterID:    "1234",
+			Outcomes:         []CreatePredictionOutcome{{Title: "Yes"}, {Title: "No"}},
+			PredictionWindow: 60,
+		})
+	}
+
+	// Resolving or canceling before Start does nothing
+	p := newPrediction()
+	if err := p.Resolve(ctx, "Yes"); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("Resolve before Start = %v", err)
+	}
+	if err := p.Cancel(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("Cancel before Start = %v", err)
+	}
+	if s := p.Snapshot().Status; s != "" {
+		t.Errorf("status before Start = %s", s)
+	}
+	if err := p.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Start(ctx); !errors.Is(err, ErrChatVoteStarted) {
+		t.Errorf("second Start = %v", err)
+	}
+
+	// Start, Cancel, Start, Cancel
+	if err := p.Cancel(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Start(ctx); !errors.Is(err, ErrChatVoteStarted) {
+		t.Errorf("Start after Cancel = %v", err)
+	}
+	if err := p.Cancel(ctx); !errors.Is(err, ErrChatVoteNotActive) {
+		t.Errorf("second Cancel = %v", err)
+	}
+	if p.HandleMessage(&ChatMessage{RoomID: "1234", UserID: "u1", Message: "1"}) {
+		t.Error("vote counted after Cancel")
+	}
+	if s := p.Snapshot().Status; s != PredictionStatusCanceled {
+		t.Errorf("status = %s", s)
+	}
+}
@@ -0,0 +1,165 @@
+package helix
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxIDsPerRequest is the number of IDs and logins lookup endpoints accept
+// per request.
+const maxIDsPerRequest = 100
+
+// lookupParam is one list query parameter of a lookup endpoint, such as the
+// id or login of Get Users, and how to find a value of it in the results.
+type lookupParam[T any] struct {
+	name     string
+	values   []string
+	key      func(*T) string // Returns the item's value for this parameter
+	foldCase bool            // Match values case-insensitively (logins, names)
+}
+
+// lookupRequest describes a request to a lookup endpoint.
+type lookupRequest[T any] struct {
+	endpoint  string
+	query     url.Values // Other query parameters, sent with every chunk
+	params    []lookupParam[T]
+	paginated bool // Chunks ask for a full page and ignore the cursor
+}
+
+// lookupValue is one requested value of a lookup parameter.
+type lookupValue struct {
+	param int
+	value string
+	key   string // value, lowercased if the parameter folds case
+}
+
+// lookup performs a request to an endpoint that accepts at most 100 IDs and
+// logins. Duplicate values are sent once. Larger requests are split into
+// chunks that run concurrently under the rate limiter, and their results are
+// merged in input order, followed by any results that don't match a
+// requested value. Pagination of a chunked request is not returned.
+//
+// Requested values with no result are reported in Response.NotFound, unless
+// a response has another page: the missing values may be on it.
+func lookup[T any](ctx context.Context, c *Client, req lookupRequest[T]) (*Response[T], error) {
+	var values []lookupValue
+	seen := make(map[lookupValue]bool)
+	for i, p := range req.params {
+		for _, v := range p.values {
+			lv := lookupValue{param: i, key: v}
+			if p.foldCase {
+				lv.key = strings.ToLower(v)
+			}
+			if seen[lv] {
+				continue
+			}
+			seen[lv] = true
+			lv.value = v
+			values = append(values, lv)
+		}
+	}
+
+	query := func(chunk []lookupValue) url.Values {
+		q := url.Values{}
+		for k, v := range req.query {
+			q[k] = slices.Clone(v)
+		}
+		for _, v := range chunk {
+			q.Add(req.params[v.param].name, v.value)
+		}
+		return q
+	}
+
+	if len(values) <= maxIDsPerRequest {
+		var resp Response[T]
+		if err := c.get(ctx, req.endpoint, query(values), &resp); err != nil {
+			return nil, err
+		}
+		if !hasNextPage(resp.Pagination) {
+			_, resp.NotFound = matchLookup(req.params, values, resp.Data)
+		}
+		return &resp, nil
+	}
+
+	chunks := slices.Collect(slices.Chunk(values, maxIDsPerRequest))
+	labels := make([]string, len(chunks))
+	for i := range chunks {
+		labels[i] = "chunk " + strconv.Itoa(i+1)
+	}
+	report := runBulk(ctx, c, labels, nil, nil, func(ctx context.Context, i int) (*Response[T], error) {
+		q := query(chunks[i])
+		if req.paginated {
+			q.Del("after")
+			q.Del("before")
+			q.Set("first", strconv.Itoa(maxIDsPerRequest))
+		}
+		var resp Response[T]
+		if err := c.get(ctx, req.endpoint, q, &resp); err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	})
+	if err := report.Err(); err != nil {
+		return nil, err
+	}
+
+	var data []T
+	complete := true
+	for _, res := range report.Results {
+		data = append(data, res.Data.Data...)
+		complete = complete && !hasNextPage(res.Data.Pagination)
+	}
+	resp := &Response[T]{}
+	var notFound []string
+	resp.Data, notFound = matchLookup(req.params, values, data)
+	if complete {
+		resp.NotFound = notFound
+	}
+	return resp, nil
+}
+
+// hasNextPage reports whether a response has another page of results.
+func hasNextPage(p *Pagination) bool {
+	return p != nil && p.Cursor != ""
+}
+
+// matchLookup orders items by the requested value they match, followed by
+// items that match none, and returns the requested values with no items.
+func matchLookup[T any](params []lookupParam[T], values []lookupValue, items []T) ([]T, []string) {
+	matches := make(map[lookupValue][]int)
+	for i := range items {
+		for p, param := range params {
+			key := param.key(&items[i])
+			if param.foldCase {
+				key = strings.ToLower(key)
+			}
+			lv := lookupValue{param: p, key: key}
+			matches[lv] = append(matches[lv], i)
+		}
+	}
+
+	ordered := make([]T, 0, len(items))
+	used := make([]bool, len(items))
+	var notFound []string
+	for _, v := range values {
+		found := matches[lookupValue{param: v.param, key: v.key}]
+		if len(found) == 0 {
+			notFound = append(notFound, v.value)
+		}
+		for _, i := range found {
+			if !used[i] {
+				used[i] = true
+				ordered = append(ordered, items[i])
+			}
+		}
+	}
+	for i := range items {
+		if !used[i] {
+			ordered = append(ordered, items[i])
+		}
+	}
+	return ordered, notFound
+}
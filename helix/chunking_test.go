@@ -0,0 +1,158 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetUsers_Chunked(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		q := r.URL.Query()
+		if n := len(q["id"]) + len(q["login"]); n > maxIDsPerRequest {
+			t.Errorf("requested %d users", n)
+		}
+		var data []User
+		// Reverse order, and no user 7 or "ghost"
+		for _, id := range slices.Backward(q["id"]) {
+			if id != "7" {
+				data = append(data, User{ID: id, Login: "user" + id})
+			}
+		}
+		for _, login := range q["login"] {
+			if login != "ghost" {
+				data = append(data, User{ID: "l-" + login, Login: login})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: data})
+	}))
+	defer server.Close()
+
+	ids := make([]string, 250)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	ids = append(ids, "3") // duplicate
+	resp, err := client.GetUsers(context.Background(), &GetUsersParams{
+		IDs:    ids,
+		Logins: []string{"Alice", "ghost"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+	if len(resp.Data) != 250 || resp.Data[0].ID != "0" || resp.Data[7].ID != "8" || resp.Data[248].ID != "249" || resp.Data[249].Login != "Alice" {
+		t.Errorf("got %d users, first %+v", len(resp.Data), resp.Data[0])
+	}
+	if !slices.Equal(resp.NotFound, []string{"7", "ghost"}) {
+		t.Errorf("NotFound = %v", resp.NotFound)
+	}
+}
+
+func TestGetUsers_SingleRequestReportsNotFound(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: []User{{ID: "2", Login: "bob"}}})
+	}))
+	defer server.Close()
+
+	resp, err := client.GetUsers(context.Background(), &GetUsersParams{Logins: []string{"alice", "BOB"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(resp.NotFound, []string{"alice"}) {
+		t.Errorf("NotFound = %v", resp.NotFound)
+	}
+}
+
+func TestGetStreams_Chunked(t *testing.T) {
+	var paged atomic.Bool
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("first") != "100" || q.Get("after") != "" || q.Get("game_id") != "g1" {
+			t.Errorf("query = %s", r.URL.RawQuery)
+		}
+		resp := Response[Stream]{Data: []Stream{{UserID: q["user_id"][0]}}}
+		if paged.Load() && q["user_id"][0] == "100" {
+			resp.Pagination = &Pagination{Cursor: "next"}
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	ids := make([]string, 150)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	resp, err := client.GetStreams(context.Background(), &GetStreamsParams{
+		UserIDs:          ids,
+		GameIDs:          []string{"g1"},
+		PaginationParams: &PaginationParams{First: 20, After: "cursor"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].UserID != "0" || resp.Data[1].UserID != "100" || resp.Pagination != nil {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.NotFound) != 148 {
+		t.Errorf("len(NotFound) = %d", len(resp.NotFound))
+	}
+
+	// A chunk with another page may hold the missing users
+	paged.Store(true)
+	resp, err = client.GetStreams(context.Background(), &GetStreamsParams{UserIDs: ids, GameIDs: []string{"g1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 || resp.NotFound != nil {
+		t.Errorf("paged: %d streams, NotFound = %v", len(resp.Data), resp.NotFound)
+	}
+}
+
+func TestGetStreams_PartialPageOmitsNotFound(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Response[Stream]{
+			Data:       []Stream{{UserID: "1"}},
+			Pagination: &Pagination{Cursor: "next"},
+		})
+	}))
+	defer server.Close()
+
+	resp, err := client.GetStreams(context.Background(), &GetStreamsParams{
+		UserIDs:          []string{"1", "2", "3"},
+		PaginationParams: &PaginationParams{First: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.NotFound != nil || resp.Pagination == nil {
+		t.Errorf("resp = %+v", resp)
+	}
+}
+
+func TestGetUserChatColor_ChunkError(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query()["user_id"][0] == "100" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response[UserChatColor]{})
+	}))
+	defer server.Close()
+
+	ids := make([]string, 101)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+	if _, err := client.GetUserChatColor(context.Background(), ids); err == nil {
+		t.Error("expected error from failed chunk")
+	}
+}
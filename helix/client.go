@@ -173,6 +173,10 @@ type Response[T any] struct {
 	Total        *int        `json:"total,omitempty"`
 	TotalCost    *int        `json:"total_cost,omitempty"`
 	MaxTotalCost *int        `json:"max_total_cost,omitempty"`
+
+	// NotFound lists the requested IDs and logins with no result, for
+	// lookup endpoints such as GetUsers and GetStreams.
+	NotFound []string `json:"-"`
 }
 
 // Pagination contains pagination information.
@@ -0,0 +1,513 @@
+package helix
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default ClipArchiver settings
+const (
+	clipArchiveMetadataFile       = "clips.jsonl"
+	defaultClipArchiveConcurrency = 4
+	defaultClipWindowCap          = 1000 // Get Clips stops paginating around this many results
+	maxClipDownloadIDs            = 10
+)
+
+// clipsEpoch is when Twitch launched clips; archives without a start time
+// begin here.
+var clipsEpoch = time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC)
+
+// ClipFetcher downloads clip media. *http.Client satisfies it.
+type ClipFetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// ArchivedClip is one line of an archive's clips.jsonl metadata file.
+type ArchivedClip struct {
+	Clip
+	File       string    `json:"file,omitempty"`   // Media file name in the archive directory, empty if not downloaded
+	SHA256     string    `json:"sha256,omitempty"` // Hex SHA-256 of the media file
+	Size       int64     `json:"size,omitempty"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
+// ClipArchiveError is a clip that failed to download.
+type ClipArchiveError struct {
+	ClipID string
+	Err    error
+}
+
+// ClipArchiveReport summarizes an archive run.
+type ClipArchiveReport struct {
+	From       time.Time
+	To         time.Time
+	Clips      int   // New clips found
+	Downloaded int   // Media files downloaded, including retries of earlier failures
+	Bytes      int64 // Bytes written to media files
+	Truncated  int   // One-second windows that still hit the result cap
+	Failed     []ClipArchiveError
+}
+
+// Err returns the download errors joined, or nil.
+func (r *ClipArchiveReport) Err() error {
+	errs := make([]error, len(r.Failed))
+	for i, f := range r.Failed {
+		errs[i] = fmt.Errorf("clip %s: %w", f.ClipID, f.Err)
+	}
+	return errors.Join(errs...)
+}
+
+// ClipArchiver copies a broadcaster's clips into a directory: metadata as
+// JSON Lines in clips.jsonl and media as <clip ID>.mp4.
+//
+// Get Clips returns at most about 1000 clips per query, so the archiver
+// walks the time range in windows and splits any window that hits the cap
+// in half until every window is complete.
+//
+// clips.jsonl is append-only and the last line for a clip wins. Clips whose
+// download failed are recorded without a file and retried on the next run.
+// A run without a start time resumes from the newest archived clip.
+type ClipArchiver struct {
+	client        *Client
+	broadcasterID string
+	editorID      string
+	dir           string
+
+	fetcher      ClipFetcher
+	concurrency  int
+	metadataOnly bool
+	onClip       func(*ArchivedClip)
+	windowCap    int
+	now          func() time.Time
+
+	mu sync.Mutex // Serializes writes to clips.jsonl
+}
+
+// ClipArchiverOption configures a ClipArchiver.
+type ClipArchiverOption func(*ClipArchiver)
+
+// WithClipArchiveFetcher sets the client used to download media
+// (default http.DefaultClient).
+func WithClipArchiveFetcher(f ClipFetcher) ClipArchiverOption {
+	return func(a *ClipArchiver) {
+		a.fetcher = f
+	}
+}
+
+// WithClipArchiveConcurrency sets how many media files are downloaded at
+// once (default 4).
+func WithClipArchiveConcurrency(n int) ClipArchiverOption {
+	return func(a *ClipArchiver) {
+		if n > 0 {
+			a.concurrency = n
+		}
+	}
+}
+
+// WithClipArchiveEditor sets the editor ID sent to Get Clips Download. It
+// must be the token's user. Defaults to the broadcaster ID.
+func WithClipArchiveEditor(editorID string) ClipArchiverOption {
+	return func(a *ClipArchiver) {
+		a.editorID = editorID
+	}
+}
+
+// WithClipArchiveMetadataOnly records clip metadata without downloading
+// media, which needs only an app access token.
+func WithClipArchiveMetadataOnly() ClipArchiverOption {
+	return func(a *ClipArchiver) {
+		a.metadataOnly = true
+	}
+}
+
+// WithClipArchiveHandler sets a function called after each clip is recorded.
+func WithClipArchiveHandler(fn func(*ArchivedClip)) ClipArchiverOption {
+	return func(a *ClipArchiver) {
+		a.onClip = fn
+	}
+}
+
+// NewClipArchiver creates an archiver for a broadcaster's clips in dir. The
+// directory is created on the first run.
+func NewClipArchiver(client *Client, broadcasterID, dir string, opts ...ClipArchiverOption) *ClipArchiver {
+	a := &ClipArchiver{
+		client:        client,
+		broadcasterID: broadcasterID,
+		editorID:      broadcasterID,
+		dir:           dir,
+		fetcher:       http.DefaultClient,
+		concurrency:   defaultClipArchiveConcurrency,
+		windowCap:     defaultClipWindowCap,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Run archives clips created between from and to. A zero from resumes from
+// the newest archived clip, or starts at the launch of clips for a new
+// archive; a zero to means now. Clips already archived are skipped, and
+// earlier failed downloads are retried.
+//
+// The returned error covers listing clips and writing metadata; per-clip
+// download errors are in the report.
+// Requires: editor:manage:clips or channel:manage:clips scope to download media.
+func (a *ClipArchiver) Run(ctx context.Context, from, to time.Time) (*ClipArchiveReport, error) {
+	if err := os.MkdirAll(a.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating clip archive: %w", err)
+	}
+	archived, err := a.Clips()
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() {
+		from = clipsEpoch
+		for _, c := range archived {
+			from = later(from, c.CreatedAt.Truncate(time.Second))
+		}
+	}
+	if to.IsZero() {
+		to = a.now()
+	}
+	report := &ClipArchiveReport{From: from, To: to}
+
+	// Earlier failures first, then new clips oldest first
+	var pending []Clip
+	if !a.metadataOnly {
+		for _, c := range archived {
+			if c.File == "" {
+				pending = append(pending, c.Clip)
+			}
+		}
+		slices.SortFunc(pending, func(x, y Clip) int { return x.CreatedAt.Compare(y.CreatedAt) })
+	}
+	err = a.walk(ctx, from, to, report, func(clips []Clip) {
+		for _, c := range clips {
+			if _, ok := archived[c.ID]; !ok {
+				pending = append(pending, c)
+				report.Clips++
+			}
+		}
+	})
+	if err != nil {
+		return report, err
+	}
+
+	f, err := os.OpenFile(filepath.Join(a.dir, clipArchiveMetadataFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return report, fmt.Errorf("opening clip metadata: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if a.metadataOnly {
+		for _, c := range pending {
+			if err := a.record(f, &ArchivedClip{Clip: c}); err != nil {
+				return report, err
+			}
+		}
+		return report, nil
+	}
+	for batch := range slices.Chunk(pending, maxClipDownloadIDs) {
+		if err := a.archiveBatch(ctx, f, batch, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// walk lists the clips created in [from, to), calling fn with each complete
+// window's clips in creation order. Windows are visited oldest first.
+func (a *ClipArchiver) walk(ctx context.Context, from, to time.Time, report *ClipArchiveReport, fn func([]Clip)) error {
+	if !to.After(from) {
+		return nil
+	}
+	clips, complete, err := a.listWindow(ctx, from, to)
+	if err != nil {
+		return err
+	}
+	mid := from.Add(to.Sub(from) / 2).Truncate(time.Second)
+	if !complete && mid.After(from) {
+		if err := a.walk(ctx, from, mid, report, fn); err != nil {
+			return err
+		}
+		return a.walk(ctx, mid, to, report, fn)
+	}
+	if !complete {
+		report.Truncated++
+	}
+	slices.SortStableFunc(clips, func(x, y Clip) int { return x.CreatedAt.Compare(y.CreatedAt) })
+	fn(clips)
+	return nil
+}
+
+// listWindow pages through the clips of one window. It stops early and
+// reports the window incomplete once the result cap is reached.
+func (a *ClipArchiver) listWindow(ctx context.Context, from, to time.Time) ([]Clip, bool, error) {
+	params := &GetClipsParams{
+		BroadcasterID:    a.broadcasterID,
+		StartedAt:        from,
+		EndedAt:          to,
+		PaginationParams: &PaginationParams{First: 100},
+	}
+	var clips []Clip
+	seen := make(map[string]bool)
+	total := 0
+	for {
+		resp, err := a.client.GetClips(ctx, params)
+		if err != nil {
+			return nil, false, fmt.Errorf("listing clips from %s to %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+		}
+		total += len(resp.Data)
+		for _, c := range resp.Data {
+			// Window bounds may be inclusive; keep each clip in one window
+			if !seen[c.ID] && !c.CreatedAt.Before(from) && c.CreatedAt.Before(to) {
+				seen[c.ID] = true
+				clips = append(clips, c)
+			}
+		}
+		if total >= a.windowCap {
+			return clips, false, nil
+		}
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+			return clips, true, nil
+		}
+		params.After = resp.Pagination.Cursor
+	}
+}
+
+// archiveBatch resolves download URLs for up to 10 clips, downloads them,
+// and records their metadata.
+func (a *ClipArchiver) archiveBatch(ctx context.Context, f *os.File, batch []Clip, report *ClipArchiveReport) error {
+	ids := make([]string, len(batch))
+	for i, c := range batch {
+		ids[i] = c.ID
+	}
+	if err := a.client.WaitForRateLimit(ctx); err != nil {
+		return err
+	}
+	resp, err := a.client.GetClipsDownload(ctx, &GetClipsDownloadParams{EditorID: a.editorID, BroadcasterID: a.broadcasterID, ClipIDs: ids})
+	if err != nil {
+		return fmt.Errorf("getting clip downloads: %w", err)
+	}
+	urls := make(map[string]string, len(resp.Data))
+	for _, d := range resp.Data {
+		urls[d.ClipID] = cmp.Or(d.LandscapeDownloadURL, d.PortraitDownloadURL)
+	}
+
+	records := make([]ArchivedClip, len(batch))
+	errs := make([]error, len(batch))
+	sem := make(chan struct{}, a.concurrency)
+	var wg sync.WaitGroup
+	for i, c := range batch {
+		records[i].Clip = c
+		wg.Go(func() {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			errs[i] = a.download(ctx, urls[c.ID], &records[i])
+		})
+	}
+	wg.Wait()
+
+	for i := range records {
+		if errs[i] != nil {
+			report.Failed = append(report.Failed, ClipArchiveError{ClipID: records[i].ID, Err: errs[i]})
+		} else {
+			report.Downloaded++
+			report.Bytes += records[i].Size
+		}
+		if err := a.record(f, &records[i]); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// download fetches a clip's media into the archive. A partial file left by
+// an interrupted download is resumed with a Range request.
+func (a *ClipArchiver) download(ctx context.Context, rawURL string, rec *ArchivedClip) error {
+	if rawURL == "" {
+		return errors.New("no download URL")
+	}
+	name := rec.ID + ".mp4"
+	if filepath.Base(name) != name || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid clip ID %q", rec.ID)
+	}
+	path := filepath.Join(a.dir, name)
+	part := path + ".part"
+
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	offset, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := a.fetcher.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var written int64
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return fmt.Errorf("unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is already complete
+	case resp.StatusCode == http.StatusOK:
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("download failed: %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+		written, err = io.Copy(f, resp.Body)
+		if err != nil {
+			return fmt.Errorf("downloading: %w", err)
+		}
+		if resp.ContentLength >= 0 && written != resp.ContentLength {
+			return fmt.Errorf("download incomplete: got %d of %d bytes", written, resp.ContentLength)
+		}
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(part, path); err != nil {
+		return err
+	}
+	rec.File = name
+	rec.SHA256 = hex.EncodeToString(h.Sum(nil))
+	rec.Size = size
+	return nil
+}
+
+// record appends a clip's metadata line.
+func (a *ClipArchiver) record(f *os.File, rec *ArchivedClip) error {
+	rec.ArchivedAt = a.now().UTC()
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encoding clip metadata: %w", err)
+	}
+	a.mu.Lock()
+	_, err = f.Write(append(line, '\n'))
+	a.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("writing clip metadata: %w", err)
+	}
+	if a.onClip != nil {
+		a.onClip(rec)
+	}
+	return nil
+}
+
+// Clips reads the archive's metadata, keyed by clip ID. Lines that fail to
+// parse are skipped. An archive that doesn't exist yet is empty.
+func (a *ClipArchiver) Clips() (map[string]*ArchivedClip, error) {
+	clips := make(map[string]*ArchivedClip)
+	f, err := os.Open(filepath.Join(a.dir, clipArchiveMetadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return clips, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening clip metadata: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var c ArchivedClip
+		if err := json.Unmarshal(scanner.Bytes(), &c); err != nil || c.ID == "" {
+			continue
+		}
+		clips[c.ID] = &c
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading clip metadata: %w", err)
+	}
+	return clips, nil
+}
+
+// Verify checks every archived media file against its recorded size and
+// checksum, and returns the IDs of clips whose file is missing or differs,
+// sorted.
+func (a *ClipArchiver) Verify(ctx context.Context) ([]string, error) {
+	clips, err := a.Clips()
+	if err != nil {
+		return nil, err
+	}
+	var bad []string
+	for id, c := range clips {
+		if c.File == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if sum, size, err := fileSHA256(filepath.Join(a.dir, c.File)); err != nil || sum != c.SHA256 || size != c.Size {
+			bad = append(bad, id)
+		}
+	}
+	slices.Sort(bad)
+	return bad, nil
+}
+
+// fileSHA256 returns a file's hex SHA-256 and size.
+func fileSHA256(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
@@ -0,0 +1,173 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type clipFetcherFunc func(req *http.Request) (*http.Response, error)
+
+func (f clipFetcherFunc) Do(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestClipArchiver(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var clips []Clip
+	for i := range 5 {
+		clips = append(clips, Clip{ID: fmt.Sprintf("c%d", i), BroadcasterID: "1234", CreatedAt: base.Add(time.Duration(i) * time.Hour), ViewCount: i})
+	}
+	media := func(id string) []byte { return bytes.Repeat([]byte("media-"+id), 100) }
+	failures := map[string]int{"c4": 1}
+	var queries int
+
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/clips":
+			queries++
+			from, _ := time.Parse(time.RFC3339, q.Get("started_at"))
+			to, _ := time.Parse(time.RFC3339, q.Get("ended_at"))
+			var matched []Clip
+			for _, c := range slices.Backward(clips) { // Most viewed first
+				if !c.CreatedAt.Before(from) && !c.CreatedAt.After(to) { // Inclusive bounds
+					matched = append(matched, c)
+				}
+			}
+			offset, _ := strconv.Atoi(q.Get("after"))
+			end := min(offset+2, len(matched))
+			resp := Response[Clip]{Data: matched[offset:end]}
+			if end < len(matched) {
+				resp.Pagination = &Pagination{Cursor: strconv.Itoa(end)}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/clips/downloads":
+			if len(q["clip_id"]) > 10 || q.Get("editor_id") != "1234" {
+				t.Errorf("download query = %v", q)
+			}
+			var data []ClipDownload
+			for _, id := range q["clip_id"] {
+				data = append(data, ClipDownload{ClipID: id, LandscapeDownloadURL: "http://" + r.Host + "/media/" + id})
+			}
+			_ = json.NewEncoder(w).Encode(Response[ClipDownload]{Data: data})
+		case strings.HasPrefix(r.URL.Path, "/media/"):
+			id := strings.TrimPrefix(r.URL.Path, "/media/")
+			if failures[id] > 0 {
+				failures[id]--
+				http.Error(w, "unavailable", http.StatusServiceUnavailable)
+				return
+			}
+			http.ServeContent(w, r, id+".mp4", base, bytes.NewReader(media(id)))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var ranges []string
+	fetcher := clipFetcherFunc(func(req *http.Request) (*http.Response, error) {
+		if h := req.Header.Get("Range"); h != "" {
+			mu.Lock()
+			ranges = append(ranges, strings.TrimPrefix(req.URL.Path, "/media/")+" "+h)
+			mu.Unlock()
+		}
+		return http.DefaultClient.Do(req)
+	})
+
+	dir := t.TempDir()
+	archiver := NewClipArchiver(client, "1234", dir, WithClipArchiveFetcher(fetcher))
+	archiver.windowCap = 3
+	now := base.Add(6 * time.Hour)
+	archiver.now = func() time.Time { return now }
+
+	// An interrupted download of c2
+	if err := os.WriteFile(filepath.Join(dir, "c2.mp4.part"), media("c2")[:7], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	report, err := archiver.Run(ctx, base, base.Add(5*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Clips != 5 || report.Downloaded != 4 || report.Truncated != 0 || len(report.Failed) != 1 || report.Failed[0].ClipID != "c4" {
+		t.Errorf("report = %+v", report)
+	}
+	if queries < 3 {
+		t.Errorf("windows were not split: %d queries", queries)
+	}
+	if !slices.Equal(ranges, []string{"c2 bytes=7-"}) {
+		t.Errorf("range requests = %v", ranges)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "clips.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for line := range strings.Lines(string(data)) {
+		var c ArchivedClip
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, c.ID)
+	}
+	if !slices.Equal(ids, []string{"c0", "c1", "c2", "c3", "c4"}) {
+		t.Errorf("metadata order = %v", ids)
+	}
+	archived, err := archiver.Clips()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(media("c2"))
+	if c := archived["c2"]; c.File != "c2.mp4" || c.SHA256 != hex.EncodeToString(sum[:]) || c.Size != int64(len(media("c2"))) {
+		t.Errorf("c2 = %+v", c)
+	}
+	if c := archived["c4"]; c.File != "" {
+		t.Errorf("failed clip has file %q", c.File)
+	}
+
+	// Incremental run: resumes from c4, retries it, and picks up a new clip
+	mu.Lock()
+	clips = append(clips, Clip{ID: "c5", BroadcasterID: "1234", CreatedAt: base.Add(5*time.Hour + 30*time.Minute)})
+	queries = 0
+	mu.Unlock()
+	report, err = archiver.Run(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.From.Equal(base.Add(4*time.Hour)) || report.Clips != 1 || report.Downloaded != 2 || report.Err() != nil {
+		t.Errorf("incremental report = %+v", report)
+	}
+	if queries != 1 {
+		t.Errorf("incremental queries = %d", queries)
+	}
+	archived, _ = archiver.Clips()
+	if len(archived) != 6 || archived["c4"].File != "c4.mp4" || archived["c5"].File != "c5.mp4" {
+		t.Errorf("archived = %v", archived)
+	}
+
+	if bad, err := archiver.Verify(ctx); err != nil || len(bad) != 0 {
+		t.Errorf("Verify() = %v, %v", bad, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "c0.mp4"), []byte("corrupt"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if bad, _ := archiver.Verify(ctx); !slices.Equal(bad, []string{"c0"}) {
+		t.Errorf("Verify() = %v", bad)
+	}
+}
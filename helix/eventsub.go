@@ -465,6 +465,15 @@ func BroadcasterModeratorCondition(broadcasterID, moderatorID string) map[string
 	}
 }
 
+// BroadcasterUserCondition returns a condition with broadcaster_user_id and user_id,
+// as used by the channel.chat.* subscription types.
+func BroadcasterUserCondition(broadcasterID, userID string) map[string]string {
+	return map[string]string{
+		"broadcaster_user_id": broadcasterID,
+		"user_id":             userID,
+	}
+}
+
 // UserCondition returns a condition with user_id.
 func UserCondition(userID string) map[string]string {
 	return map[string]string{"user_id": userID}
@@ -110,6 +110,7 @@ type EventSubWebSocketClient struct {
 	onReconnect    func(reconnectURL string)
 	onError        func(error)
 	onKeepalive    func()
+	onDisconnect   func(error)
 
 	// State
 	mu           sync.RWMutex
@@ -173,6 +174,15 @@ func WithWSKeepaliveHandler(fn func()) EventSubWSOption {
 	}
 }
 
+// WithWSDisconnectHandler sets the handler called when the connection drops
+// without Close or Reconnect, for example when Twitch closes the session or
+// keepalives stop. The session's subscriptions are gone at that point.
+func WithWSDisconnectHandler(fn func(error)) EventSubWSOption {
+	return func(c *EventSubWebSocketClient) {
+		c.onDisconnect = fn
+	}
+}
+
 // NewEventSubWebSocketClient creates a new EventSub WebSocket client.
 func NewEventSubWebSocketClient(opts ...EventSubWSOption) *EventSubWebSocketClient {
 	c := &EventSubWebSocketClient{
@@ -294,6 +304,13 @@ func (c *EventSubWebSocketClient) waitForWelcome(ctx context.Context) (string, e
 
 // readLoop continuously reads messages from the WebSocket.
 func (c *EventSubWebSocketClient) readLoop() {
+	var dropped error
+	defer func() {
+		// After wg.Done, so the handler may call Close
+		if dropped != nil && c.onDisconnect != nil {
+			c.onDisconnect(dropped)
+		}
+	}()
 	defer c.wg.Done()
 	defer func() {
 		c.mu.Lock()
@@ -331,6 +348,12 @@ func (c *EventSubWebSocketClient) readLoop() {
 
 		_, data, err := conn.ReadMessage()
 		if err != nil {
+			select {
+			case <-stopChan:
+				// Closed by Close or Reconnect
+			default:
+				dropped = err
+			}
 			// Don't report errors for expected connection close scenarios
 			if c.onError != nil && !isExpectedCloseError(err) {
 				c.onError(fmt.Errorf("reading message: %w", err))
@@ -551,13 +574,19 @@ type EventSubWebSocket struct {
 	sessionID string
 	wsURL     string // overrides the default Twitch URL (useful for testing)
 
-	mu       sync.RWMutex
-	handlers map[string]func(json.RawMessage)
+	mu            sync.RWMutex
+	handlers      map[string]func(json.RawMessage)
+	subscriptions map[string]string // subscription ID -> event type
 
 	// User-provided handlers
 	onRevocation func(eventType string, reason string)
 	onReconnect  func()
+	onDisconnect func(error)
 	onError      func(error)
+
+	// onSubscriptionRevoked receives the whole revoked subscription, for
+	// callers that need to know which condition was revoked.
+	onSubscriptionRevoked func(*EventSubSubscription)
 }
 
 // EventSubWebSocketOption configures the high-level EventSub WebSocket manager.
@@ -578,6 +607,15 @@ func WithEventSubReconnectHandler(fn func()) EventSubWebSocketOption {
 	}
 }
 
+// WithEventSubDisconnectHandler sets the handler called when the connection
+// drops unexpectedly. Subscriptions end with the session, so they have to be
+// created again after a new Connect.
+func WithEventSubDisconnectHandler(fn func(error)) EventSubWebSocketOption {
+	return func(e *EventSubWebSocket) {
+		e.onDisconnect = fn
+	}
+}
+
 // WithEventSubErrorHandler sets the handler for WebSocket errors.
 func WithEventSubErrorHandler(fn func(error)) EventSubWebSocketOption {
 	return func(e *EventSubWebSocket) {
@@ -600,8 +638,9 @@ func NewEventSubWebSocket(helixClient *Client, opts ...EventSubWebSocketOption)
 	}
 
 	e := &EventSubWebSocket{
-		client:   helixClient,
-		handlers: make(map[string]func(json.RawMessage)),
+		client:        helixClient,
+		handlers:      make(map[string]func(json.RawMessage)),
+		subscriptions: make(map[string]string),
 	}
 	for _, opt := range opts {
 		opt(e)
@@ -613,10 +652,14 @@ func NewEventSubWebSocket(helixClient *Client, opts ...EventSubWebSocketOption)
 // If already connected, the existing connection is closed first.
 func (e *EventSubWebSocket) Connect(ctx context.Context) error {
 	// Close existing connection if any
-	if e.ws != nil {
-		_ = e.ws.Close()
-		e.ws = nil
-		e.sessionID = ""
+	e.mu.Lock()
+	old := e.ws
+	e.ws = nil
+	e.sessionID = ""
+	clear(e.subscriptions)
+	e.mu.Unlock()
+	if old != nil {
+		_ = old.Close()
 	}
 
 	wsOpts := []EventSubWSOption{
@@ -629,12 +672,26 @@ func (e *EventSubWebSocket) Connect(ctx context.Context) error {
 			}
 		}),
 		WithWSRevocationHandler(func(sub *EventSubSubscription) {
-			// Remove handler for revoked subscription
+			// Remove the handler unless other subscriptions of the same
+			// type still deliver to it
 			e.mu.Lock()
-			delete(e.handlers, sub.Type)
+			delete(e.subscriptions, sub.ID)
+			remaining := false
+			for _, eventType := range e.subscriptions {
+				if eventType == sub.Type {
+					remaining = true
+					break
+				}
+			}
+			if !remaining {
+				delete(e.handlers, sub.Type)
+			}
 			e.mu.Unlock()
 
 			// Notify user if handler is set
+			if e.onSubscriptionRevoked != nil {
+				e.onSubscriptionRevoked(sub)
+			}
 			if e.onRevocation != nil {
 				e.onRevocation(sub.Type, sub.Status)
 			}
@@ -652,17 +709,37 @@ func (e *EventSubWebSocket) Connect(ctx context.Context) error {
 	if e.wsURL != "" {
 		wsOpts = append(wsOpts, WithWSURL(e.wsURL))
 	}
-	e.ws = NewEventSubWebSocketClient(wsOpts...)
+	ws := NewEventSubWebSocketClient(wsOpts...)
+	ws.onDisconnect = func(err error) { e.handleDisconnect(ws, err) }
 
-	sessionID, err := e.ws.Connect(ctx)
+	sessionID, err := ws.Connect(ctx)
 	if err != nil {
-		e.ws = nil
 		return err
 	}
+	e.mu.Lock()
+	e.ws = ws
 	e.sessionID = sessionID
+	e.mu.Unlock()
 	return nil
 }
 
+// handleDisconnect forgets the dropped session and its subscriptions.
+func (e *EventSubWebSocket) handleDisconnect(ws *EventSubWebSocketClient, err error) {
+	e.mu.Lock()
+	if e.ws != ws {
+		// A new connection has already replaced this one
+		e.mu.Unlock()
+		return
+	}
+	e.sessionID = ""
+	clear(e.subscriptions)
+	e.mu.Unlock()
+
+	if e.onDisconnect != nil {
+		e.onDisconnect(err)
+	}
+}
+
 // handleReconnect handles the reconnect process when Twitch sends a reconnect message.
 func (e *EventSubWebSocket) handleReconnect(reconnectURL string) {
 	// Copy ws under lock to avoid race with Close/Connect
@@ -701,36 +778,77 @@ func (e *EventSubWebSocket) handleReconnect(reconnectURL string) {
 // Subscribe creates a subscription for the given event type.
 // Returns an error if not connected.
 func (e *EventSubWebSocket) Subscribe(ctx context.Context, eventType, version string, condition map[string]string, handler func(json.RawMessage)) error {
-	if e.sessionID == "" {
-		return errors.New("not connected: call Connect first")
+	_, err := e.CreateSubscription(ctx, eventType, version, condition, handler)
+	return err
+}
+
+// CreateSubscription is like Subscribe but returns the created subscription,
+// so its ID can later be passed to Unsubscribe.
+// Handlers are registered per event type: subscribing to the same type for
+// several conditions replaces the handler, which then receives the events of
+// every subscription of that type. A revocation only removes the handler once
+// no other subscription of its type is left.
+func (e *EventSubWebSocket) CreateSubscription(ctx context.Context, eventType, version string, condition map[string]string, handler func(json.RawMessage)) (*EventSubSubscription, error) {
+	e.mu.RLock()
+	sessionID := e.sessionID
+	e.mu.RUnlock()
+	if sessionID == "" {
+		return nil, errors.New("not connected: call Connect first")
 	}
 
 	// Create subscription via API first
-	_, err := e.client.CreateEventSubSubscription(ctx, &CreateEventSubSubscriptionParams{
+	sub, err := e.client.CreateEventSubSubscription(ctx, &CreateEventSubSubscriptionParams{
 		Type:      eventType,
 		Version:   version,
 		Condition: condition,
 		Transport: CreateEventSubTransport{
 			Method:    "websocket",
-			SessionID: e.sessionID,
+			SessionID: sessionID,
 		},
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Only register handler after successful subscription
 	e.mu.Lock()
 	e.handlers[eventType] = handler
+	if sub != nil {
+		e.subscriptions[sub.ID] = eventType
+	}
 	e.mu.Unlock()
 
+	return sub, nil
+}
+
+// Unsubscribe deletes a subscription created on this session.
+// The handler for the subscription's event type is left registered, since
+// other subscriptions of the same type may still deliver to it.
+func (e *EventSubWebSocket) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	if err := e.client.DeleteEventSubSubscription(ctx, subscriptionID); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	delete(e.subscriptions, subscriptionID)
+	e.mu.Unlock()
 	return nil
 }
 
+// IsConnected returns whether the WebSocket connection is established.
+func (e *EventSubWebSocket) IsConnected() bool {
+	e.mu.RLock()
+	ws := e.ws
+	e.mu.RUnlock()
+	return ws != nil && ws.IsConnected()
+}
+
 // Close closes the WebSocket connection.
 func (e *EventSubWebSocket) Close() error {
-	if e.ws != nil {
-		return e.ws.Close()
+	e.mu.RLock()
+	ws := e.ws
+	e.mu.RUnlock()
+	if ws != nil {
+		return ws.Close()
 	}
 	return nil
 }
@@ -15,31 +15,27 @@ type Game struct {
 
 // GetGamesParams contains parameters for GetGames.
 type GetGamesParams struct {
-	IDs     []string // Game IDs (max 100)
-	Names   []string // Game names (max 100)
-	IGDBIDs []string // IGDB IDs (max 100)
+	IDs     []string // Game IDs
+	Names   []string // Game names
+	IGDBIDs []string // IGDB IDs
 }
 
 // GetGames gets information about one or more games.
+// Duplicate IDs and names are sent once. More than 100 are split into
+// concurrent requests, and the ones that don't exist are listed in
+// Response.NotFound.
 func (c *Client) GetGames(ctx context.Context, params *GetGamesParams) (*Response[Game], error) {
-	q := url.Values{}
-	if params != nil {
-		for _, id := range params.IDs {
-			q.Add("id", id)
-		}
-		for _, name := range params.Names {
-			q.Add("name", name)
-		}
-		for _, igdbID := range params.IGDBIDs {
-			q.Add("igdb_id", igdbID)
-		}
+	if params == nil {
+		params = &GetGamesParams{}
 	}
-
-	var resp Response[Game]
-	if err := c.get(ctx, "/games", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return lookup(ctx, c, lookupRequest[Game]{
+		endpoint: "/games",
+		params: []lookupParam[Game]{
+			{name: "id", values: params.IDs, key: func(g *Game) string { return g.ID }},
+			{name: "name", values: params.Names, key: func(g *Game) string { return g.Name }, foldCase: true},
+			{name: "igdb_id", values: params.IGDBIDs, key: func(g *Game) string { return g.IGDBId }},
+		},
+	})
 }
 
 // GetTopGamesParams contains parameters for GetTopGames.
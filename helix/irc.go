@@ -62,7 +62,10 @@ type IRCClient struct {
 	token string
 
 	// Channel tracking
-	channels map[string]bool
+	channels    map[string]bool
+	states      map[string]*channelTracker // per-channel state, see ChannelState
+	maxChatters int
+	stateMu     sync.RWMutex
 
 	// Handlers
 	onMessage         func(*ChatMessage)
@@ -130,6 +133,7 @@ func NewIRCClientE(nick, token string, opts ...IRCOption) (*IRCClient, error) {
 		nick:           strings.ToLower(nick),
 		token:          token,
 		channels:       make(map[string]bool),
+		states:         make(map[string]*channelTracker),
 		autoReconnect:  true,
 		reconnectDelay: 5 * time.Second,
 		capabilities: []string{
@@ -528,8 +532,10 @@ func (c *IRCClient) handleMessage(raw string) {
 		}
 
 	case ircPRIVMSG:
+		chatMsg := parseChatMessage(msg)
+		c.trackChatter(chatMsg.Channel, chatMsg.User)
 		if c.onMessage != nil {
-			c.onMessage(parseChatMessage(msg))
+			c.onMessage(chatMsg)
 		}
 
 	case ircWHISPER:
@@ -548,6 +554,7 @@ func (c *IRCClient) handleMessage(raw string) {
 		}
 
 	case ircROOMSTATE:
+		c.trackRoomState(msg)
 		if c.onRoomState != nil {
 			c.onRoomState(parseRoomState(msg))
 		}
@@ -572,27 +579,31 @@ func (c *IRCClient) handleMessage(raw string) {
 		}
 
 	case ircUSERSTATE:
+		state := parseUserState(msg)
+		c.trackUserState(state)
 		if c.onUserState != nil {
-			c.onUserState(parseUserState(msg))
+			c.onUserState(state)
 		}
 
 	case ircJOIN:
+		channel := ""
+		if len(msg.Params) > 0 {
+			channel = parseChannel(msg.Params[0])
+		}
+		user := parseUserFromPrefix(msg.Prefix)
+		c.trackChatter(channel, user)
 		if c.onJoin != nil {
-			channel := ""
-			if len(msg.Params) > 0 {
-				channel = parseChannel(msg.Params[0])
-			}
-			user := parseUserFromPrefix(msg.Prefix)
 			c.onJoin(channel, user)
 		}
 
 	case ircPART:
+		channel := ""
+		if len(msg.Params) > 0 {
+			channel = parseChannel(msg.Params[0])
+		}
+		user := parseUserFromPrefix(msg.Prefix)
+		c.untrackChatter(channel, user)
 		if c.onPart != nil {
-			channel := ""
-			if len(msg.Params) > 0 {
-				channel = parseChannel(msg.Params[0])
-			}
-			user := parseUserFromPrefix(msg.Prefix)
 			c.onPart(channel, user)
 		}
 
@@ -726,6 +737,7 @@ func (c *IRCClient) Join(channels ...string) error {
 	for _, ch := range channels {
 		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
 		c.channels[ch] = true
+		c.trackJoinedChannel(ch)
 	}
 	c.mu.Unlock()
 
@@ -750,6 +762,7 @@ func (c *IRCClient) Part(channels ...string) error {
 	for _, ch := range channels {
 		ch = sanitizeIRCMessage(strings.ToLower(strings.TrimPrefix(ch, "#")))
 		delete(c.channels, ch)
+		c.untrackChannel(ch)
 	}
 	c.mu.Unlock()
 
@@ -0,0 +1,283 @@
+package helix
+
+import "strings"
+
+// SubPlan is a subscription plan as sent in msg-param-sub-plan.
+type SubPlan string
+
+// Subscription plans
+const (
+	SubPlanPrime SubPlan = "Prime"
+	SubPlanTier1 SubPlan = "1000"
+	SubPlanTier2 SubPlan = "2000"
+	SubPlanTier3 SubPlan = "3000"
+)
+
+// Tier returns the plan's tier (1-3; Prime counts as Tier 1), or 0 if unknown.
+func (p SubPlan) Tier() int {
+	switch p {
+	case SubPlanPrime, SubPlanTier1:
+		return 1
+	case SubPlanTier2:
+		return 2
+	case SubPlanTier3:
+		return 3
+	}
+	return 0
+}
+
+// IsPrime reports whether the plan is Prime Gaming.
+func (p SubPlan) IsPrime() bool {
+	return p == SubPlanPrime
+}
+
+// anonymousGifterLogin is the login Twitch uses for anonymous gifts.
+const anonymousGifterLogin = "ananonymousgifter"
+
+// UserNoticeData is the typed payload of a UserNotice. Use a type switch on
+// UserNotice.Data to get the variant for the notice's Type.
+type UserNoticeData interface {
+	userNoticeData()
+}
+
+// SubNotice is the payload of a "sub" notice.
+type SubNotice struct {
+	Plan               SubPlan
+	PlanName           string
+	CumulativeMonths   int
+	StreakMonths       int // 0 unless ShouldShareStreak
+	ShouldShareStreak  bool
+	MultiMonthDuration int // Months purchased at once
+	MultiMonthTenure   int
+	WasGifted          bool
+	GifterAnonymous    bool
+	GifterID           string
+	GifterLogin        string
+	GifterDisplayName  string
+}
+
+// ResubNotice is the payload of a "resub" notice.
+type ResubNotice struct {
+	SubNotice
+}
+
+// SubGiftNotice is the payload of a "subgift" or "anonsubgift" notice: one
+// gifted subscription to a specific recipient.
+type SubGiftNotice struct {
+	Anonymous            bool
+	Plan                 SubPlan
+	PlanName             string
+	GiftMonths           int // Months gifted at once
+	Months               int // Recipient's cumulative months
+	RecipientID          string
+	RecipientLogin       string
+	RecipientDisplayName string
+	SenderCount          int    // Gifter's total gifts in the channel (0 if hidden)
+	CommunityGiftID      string // Set when the gift is part of a CommunityGiftNotice
+}
+
+// CommunityGiftNotice is the payload of a "submysterygift" notice: a gifter
+// giving several subscriptions to the community at once. It is followed by
+// one SubGiftNotice per recipient with the same CommunityGiftID.
+type CommunityGiftNotice struct {
+	Anonymous       bool
+	Plan            SubPlan
+	MassGiftCount   int
+	SenderCount     int
+	CommunityGiftID string
+}
+
+// GiftPaidUpgradeNotice is the payload of a "giftpaidupgrade" or
+// "anongiftpaidupgrade" notice: a user continuing a gifted subscription.
+type GiftPaidUpgradeNotice struct {
+	Anonymous      bool
+	SenderLogin    string
+	SenderName     string
+	PromoGiftTotal int
+	PromoName      string
+}
+
+// PrimePaidUpgradeNotice is the payload of a "primepaidupgrade" notice.
+type PrimePaidUpgradeNotice struct {
+	Plan SubPlan
+}
+
+// RaidNotice is the payload of a "raid" notice.
+type RaidNotice struct {
+	Login           string
+	DisplayName     string
+	ViewerCount     int
+	ProfileImageURL string
+}
+
+// UnraidNotice is the payload of an "unraid" notice.
+type UnraidNotice struct{}
+
+// RitualNotice is the payload of a "ritual" notice (e.g. "new_chatter").
+type RitualNotice struct {
+	Name string
+}
+
+// BitsBadgeTierNotice is the payload of a "bitsbadgetier" notice.
+type BitsBadgeTierNotice struct {
+	Threshold int
+}
+
+// AnnouncementNotice is the payload of an "announcement" notice.
+type AnnouncementNotice struct {
+	Color string // PRIMARY, BLUE, GREEN, ORANGE or PURPLE
+}
+
+// PayForwardNotice is the payload of a "standardpayforward" or
+// "communitypayforward" notice.
+type PayForwardNotice struct {
+	Community              bool // Paid forward to the community rather than a user
+	PriorGifterAnonymous   bool
+	PriorGifterID          string
+	PriorGifterLogin       string
+	PriorGifterDisplayName string
+	RecipientID            string
+	RecipientLogin         string
+	RecipientDisplayName   string
+}
+
+// CharityDonationNotice is the payload of a "charitydonation" notice.
+type CharityDonationNotice struct {
+	CharityName string
+	Amount      CharityAmount
+}
+
+// ViewerMilestoneNotice is the payload of a "viewermilestone" notice.
+type ViewerMilestoneNotice struct {
+	Category            string // e.g. "watch-streak"
+	Value               int
+	ChannelPointsReward int
+}
+
+func (SubNotice) userNoticeData()              {}
+func (SubGiftNotice) userNoticeData()          {}
+func (CommunityGiftNotice) userNoticeData()    {}
+func (GiftPaidUpgradeNotice) userNoticeData()  {}
+func (PrimePaidUpgradeNotice) userNoticeData() {}
+func (RaidNotice) userNoticeData()             {}
+func (UnraidNotice) userNoticeData()           {}
+func (RitualNotice) userNoticeData()           {}
+func (BitsBadgeTierNotice) userNoticeData()    {}
+func (AnnouncementNotice) userNoticeData()     {}
+func (PayForwardNotice) userNoticeData()       {}
+func (CharityDonationNotice) userNoticeData()  {}
+func (ViewerMilestoneNotice) userNoticeData()  {}
+
+// parseUserNoticeData builds the typed payload for a notice from its
+// MsgParams. Returns nil for unknown notice types.
+func parseUserNoticeData(n *UserNotice) UserNoticeData {
+	p := n.MsgParams
+	anonymous := strings.HasPrefix(n.Type, "anon") || n.User == anonymousGifterLogin
+
+	switch n.Type {
+	case UserNoticeTypeSub, UserNoticeTypeResub:
+		sub := SubNotice{
+			Plan:               SubPlan(p["sub-plan"]),
+			PlanName:           p["sub-plan-name"],
+			CumulativeMonths:   parseInt(p["cumulative-months"]),
+			ShouldShareStreak:  parseParamBool(p["should-share-streak"]),
+			MultiMonthDuration: parseInt(p["multimonth-duration"]),
+			MultiMonthTenure:   parseInt(p["multimonth-tenure"]),
+			WasGifted:          parseParamBool(p["was-gifted"]),
+			GifterAnonymous:    parseParamBool(p["anon-gift"]),
+			GifterID:           p["gifter-id"],
+			GifterLogin:        p["gifter-login"],
+			GifterDisplayName:  p["gifter-name"],
+		}
+		if sub.ShouldShareStreak {
+			sub.StreakMonths = parseInt(p["streak-months"])
+		}
+		if n.Type == UserNoticeTypeResub {
+			return &ResubNotice{SubNotice: sub}
+		}
+		return &sub
+	case UserNoticeTypeSubGift, UserNoticeTypeAnonSubGift:
+		return &SubGiftNotice{
+			Anonymous:            anonymous,
+			Plan:                 SubPlan(p["sub-plan"]),
+			PlanName:             p["sub-plan-name"],
+			GiftMonths:           max(parseInt(p["gift-months"]), 1),
+			Months:               parseInt(p["months"]),
+			RecipientID:          p["recipient-id"],
+			RecipientLogin:       p["recipient-user-name"],
+			RecipientDisplayName: p["recipient-display-name"],
+			SenderCount:          parseInt(p["sender-count"]),
+			CommunityGiftID:      p["community-gift-id"],
+		}
+	case UserNoticeTypeSubMysteryGift, UserNoticeTypeAnonSubMysteryGift:
+		return &CommunityGiftNotice{
+			Anonymous:       anonymous,
+			Plan:            SubPlan(p["sub-plan"]),
+			MassGiftCount:   parseInt(p["mass-gift-count"]),
+			SenderCount:     parseInt(p["sender-count"]),
+			CommunityGiftID: p["community-gift-id"],
+		}
+	case UserNoticeTypeGiftPaidUpgrade, UserNoticeTypeAnonGiftPaidUpgrade:
+		return &GiftPaidUpgradeNotice{
+			Anonymous:      n.Type == UserNoticeTypeAnonGiftPaidUpgrade || p["sender-login"] == "",
+			SenderLogin:    p["sender-login"],
+			SenderName:     p["sender-name"],
+			PromoGiftTotal: parseInt(p["promo-gift-total"]),
+			PromoName:      p["promo-name"],
+		}
+	case UserNoticeTypePrimePaidUpgrade:
+		return &PrimePaidUpgradeNotice{Plan: SubPlan(p["sub-plan"])}
+	case UserNoticeTypeRaid:
+		return &RaidNotice{
+			Login:           p["login"],
+			DisplayName:     p["displayName"],
+			ViewerCount:     parseInt(p["viewerCount"]),
+			ProfileImageURL: p["profileImageURL"],
+		}
+	case UserNoticeTypeUnraid:
+		return &UnraidNotice{}
+	case UserNoticeTypeRitual:
+		return &RitualNotice{Name: p["ritual-name"]}
+	case UserNoticeTypeBitsBadgeTier:
+		return &BitsBadgeTierNotice{Threshold: parseInt(p["threshold"])}
+	case UserNoticeTypeAnnouncement:
+		color := strings.ToUpper(p["color"])
+		if color == "" {
+			color = "PRIMARY"
+		}
+		return &AnnouncementNotice{Color: color}
+	case UserNoticeTypeStandardPayForward, UserNoticeTypeCommunityPayForward:
+		return &PayForwardNotice{
+			Community:              n.Type == UserNoticeTypeCommunityPayForward,
+			PriorGifterAnonymous:   parseParamBool(p["prior-gifter-anonymous"]),
+			PriorGifterID:          p["prior-gifter-id"],
+			PriorGifterLogin:       p["prior-gifter-user-name"],
+			PriorGifterDisplayName: p["prior-gifter-display-name"],
+			RecipientID:            p["recipient-id"],
+			RecipientLogin:         p["recipient-user-name"],
+			RecipientDisplayName:   p["recipient-display-name"],
+		}
+	case UserNoticeTypeCharityDonation:
+		return &CharityDonationNotice{
+			CharityName: p["charity-name"],
+			Amount: CharityAmount{
+				Value:         parseInt(p["donation-amount"]),
+				DecimalPlaces: parseInt(p["exponent"]),
+				Currency:      p["donation-currency"],
+			},
+		}
+	case UserNoticeTypeViewerMilestone:
+		return &ViewerMilestoneNotice{
+			Category:            p["category"],
+			Value:               parseInt(p["value"]),
+			ChannelPointsReward: parseInt(p["copoReward"]),
+		}
+	}
+	return nil
+}
+
+// parseParamBool parses msg-param booleans, which Twitch sends as either
+// "1"/"0" or "true"/"false".
+func parseParamBool(s string) bool {
+	return s == "1" || strings.EqualFold(s, "true")
+}
@@ -0,0 +1,127 @@
+package helix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseUserNotice_TypedData(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want UserNoticeData
+	}{
+		{
+			name: "sub",
+			raw:  "@login=a;msg-id=sub;msg-param-cumulative-months=1;msg-param-should-share-streak=0;msg-param-streak-months=0;msg-param-sub-plan=Prime;msg-param-sub-plan-name=Prime\\sSub;msg-param-multimonth-duration=1 :tmi.twitch.tv USERNOTICE #chan",
+			want: &SubNotice{Plan: SubPlanPrime, PlanName: "Prime Sub", CumulativeMonths: 1, MultiMonthDuration: 1},
+		},
+		{
+			name: "resub with streak",
+			raw:  "@login=a;msg-id=resub;msg-param-cumulative-months=14;msg-param-should-share-streak=1;msg-param-streak-months=6;msg-param-sub-plan=2000 :tmi.twitch.tv USERNOTICE #chan :great stream",
+			want: &ResubNotice{SubNotice{Plan: SubPlanTier2, CumulativeMonths: 14, StreakMonths: 6, ShouldShareStreak: true}},
+		},
+		{
+			name: "resub hidden streak",
+			raw:  "@login=a;msg-id=resub;msg-param-cumulative-months=3;msg-param-should-share-streak=0;msg-param-streak-months=3;msg-param-sub-plan=1000 :tmi.twitch.tv USERNOTICE #chan",
+			want: &ResubNotice{SubNotice{Plan: SubPlanTier1, CumulativeMonths: 3}},
+		},
+		{
+			name: "subgift in community gift",
+			raw:  "@login=gifter;msg-id=subgift;msg-param-community-gift-id=123;msg-param-gift-months=1;msg-param-months=2;msg-param-recipient-display-name=Lucky;msg-param-recipient-id=42;msg-param-recipient-user-name=lucky;msg-param-sub-plan=1000;msg-param-sender-count=0 :tmi.twitch.tv USERNOTICE #chan",
+			want: &SubGiftNotice{Plan: SubPlanTier1, GiftMonths: 1, Months: 2, RecipientID: "42", RecipientLogin: "lucky", RecipientDisplayName: "Lucky", CommunityGiftID: "123"},
+		},
+		{
+			name: "anonymous submysterygift",
+			raw:  "@login=ananonymousgifter;msg-id=submysterygift;msg-param-mass-gift-count=5;msg-param-community-gift-id=123;msg-param-sub-plan=3000 :tmi.twitch.tv USERNOTICE #chan",
+			want: &CommunityGiftNotice{Anonymous: true, Plan: SubPlanTier3, MassGiftCount: 5, CommunityGiftID: "123"},
+		},
+		{
+			name: "raid",
+			raw:  "@login=raider;msg-id=raid;msg-param-displayName=Raider;msg-param-login=raider;msg-param-viewerCount=1234;msg-param-profileImageURL=https://example.com/p.png :tmi.twitch.tv USERNOTICE #chan",
+			want: &RaidNotice{Login: "raider", DisplayName: "Raider", ViewerCount: 1234, ProfileImageURL: "https://example.com/p.png"},
+		},
+		{
+			name: "announcement default color",
+			raw:  "@login=mod;msg-id=announcement :tmi.twitch.tv USERNOTICE #chan :hello",
+			want: &AnnouncementNotice{Color: "PRIMARY"},
+		},
+		{
+			name: "bits badge tier",
+			raw:  "@login=a;msg-id=bitsbadgetier;msg-param-threshold=10000 :tmi.twitch.tv USERNOTICE #chan",
+			want: &BitsBadgeTierNotice{Threshold: 10000},
+		},
+		{
+			name: "ritual",
+			raw:  "@login=a;msg-id=ritual;msg-param-ritual-name=new_chatter :tmi.twitch.tv USERNOTICE #chan",
+			want: &RitualNotice{Name: "new_chatter"},
+		},
+		{
+			name: "community pay forward",
+			raw:  "@login=a;msg-id=communitypayforward;msg-param-prior-gifter-anonymous=false;msg-param-prior-gifter-id=7;msg-param-prior-gifter-user-name=prior :tmi.twitch.tv USERNOTICE #chan",
+			want: &PayForwardNotice{Community: true, PriorGifterID: "7", PriorGifterLogin: "prior"},
+		},
+		{
+			name: "charity donation",
+			raw:  "@login=a;msg-id=charitydonation;msg-param-charity-name=Kind;msg-param-donation-amount=500;msg-param-donation-currency=USD;msg-param-exponent=2 :tmi.twitch.tv USERNOTICE #chan",
+			want: &CharityDonationNotice{CharityName: "Kind", Amount: CharityAmount{Value: 500, DecimalPlaces: 2, Currency: "USD"}},
+		},
+		{
+			name: "unknown",
+			raw:  "@login=a;msg-id=somethingnew :tmi.twitch.tv USERNOTICE #chan",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notice := parseUserNotice(parseIRCMessage(tt.raw))
+			if !reflect.DeepEqual(notice.Data, tt.want) {
+				t.Errorf("Data = %#v, want %#v", notice.Data, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubPlan(t *testing.T) {
+	tests := []struct {
+		plan  SubPlan
+		tier  int
+		prime bool
+	}{
+		{SubPlanPrime, 1, true},
+		{SubPlanTier1, 1, false},
+		{SubPlanTier2, 2, false},
+		{SubPlanTier3, 3, false},
+		{SubPlan(""), 0, false},
+	}
+	for _, tt := range tests {
+		if got := tt.plan.Tier(); got != tt.tier {
+			t.Errorf("%q.Tier() = %d, want %d", tt.plan, got, tt.tier)
+		}
+		if got := tt.plan.IsPrime(); got != tt.prime {
+			t.Errorf("%q.IsPrime() = %v, want %v", tt.plan, got, tt.prime)
+		}
+	}
+}
+
+func TestUserNoticeFromEvent_TypedData(t *testing.T) {
+	event := &ChannelChatNotificationEvent{
+		NoticeType: "resub",
+		Resub: &ChatNotificationResub{
+			CumulativeMonths: 10,
+			StreakMonths:     4,
+			SubTier:          "1000",
+			IsPrime:          true,
+		},
+	}
+	notice := userNoticeFromEvent(event, "", time.Now())
+	resub, ok := notice.Data.(*ResubNotice)
+	if !ok {
+		t.Fatalf("Data = %T, want *ResubNotice", notice.Data)
+	}
+	if resub.Plan != SubPlanPrime || resub.CumulativeMonths != 10 || resub.StreakMonths != 4 {
+		t.Errorf("ResubNotice = %+v", resub)
+	}
+}
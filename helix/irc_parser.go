@@ -196,6 +196,17 @@ func parseEmotes(emoteStr string) []IRCEmote {
 	return emotes
 }
 
+// parseBadgeOrder returns the badge names of the badges tag in order.
+func parseBadgeOrder(badgeStr string) []string {
+	var order []string
+	for part := range strings.SplitSeq(badgeStr, ",") {
+		if name, _, _ := strings.Cut(part, "/"); name != "" {
+			order = append(order, name)
+		}
+	}
+	return order
+}
+
 // parseBadges parses the badges tag into a map.
 // Format: badge/version,badge/version
 func parseBadges(badgeStr string) map[string]string {
@@ -276,11 +287,13 @@ func parseChatMessage(msg *IRCMessage) *ChatMessage {
 	return &ChatMessage{
 		ID:                     msg.Tags["id"],
 		Channel:                channel,
+		RoomID:                 msg.Tags["room-id"],
 		User:                   msg.Tags["login"],
 		UserID:                 msg.Tags["user-id"],
 		Message:                msg.Trailing,
 		Emotes:                 parseEmotes(msg.Tags["emotes"]),
 		Badges:                 badges,
+		BadgeOrder:             parseBadgeOrder(msg.Tags["badges"]),
 		BadgeInfo:              parseBadges(msg.Tags["badge-info"]),
 		Color:                  msg.Tags["color"],
 		DisplayName:            msg.Tags["display-name"],
@@ -317,7 +330,7 @@ func parseUserNotice(msg *IRCMessage) *UserNotice {
 		}
 	}
 
-	return &UserNotice{
+	notice := &UserNotice{
 		Type:          msg.Tags["msg-id"],
 		Channel:       channel,
 		User:          msg.Tags["login"],
@@ -333,6 +346,9 @@ func parseUserNotice(msg *IRCMessage) *UserNotice {
 		Timestamp:     parseTimestamp(msg.Tags["tmi-sent-ts"]),
 		Raw:           msg.Raw,
 	}
+	notice.Data = parseUserNoticeData(notice)
+
+	return notice
 }
 
 // parseRoomState converts an IRCMessage into a RoomState.
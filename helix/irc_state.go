@@ -0,0 +1,254 @@
+package helix
+
+import (
+	"maps"
+	"sort"
+	"time"
+)
+
+// defaultMaxTrackedChatters is the default number of recent chatters kept per channel.
+const defaultMaxTrackedChatters = 1000
+
+// ChannelState is the tracked state of a joined IRC channel: the room's chat
+// modes, merged across partial ROOMSTATE updates, and the bot's own status
+// from USERSTATE.
+type ChannelState struct {
+	Channel string // Channel name
+	RoomID  string // Channel's user ID
+
+	// Room modes (from ROOMSTATE)
+	EmoteOnly     bool
+	FollowersOnly int // -1 = off, 0+ = minutes required
+	R9K           bool
+	Slow          int // Seconds between messages, 0 = off
+	SubsOnly      bool
+
+	// Own status (from USERSTATE)
+	IsMod         bool
+	IsVIP         bool
+	IsBroadcaster bool
+	IsSubscriber  bool
+	Badges        map[string]string
+	EmoteSets     []string
+
+	HasRoomState bool      // A ROOMSTATE has been received since joining
+	HasUserState bool      // A USERSTATE has been received since joining
+	ChatterCount int       // Number of tracked recent chatters
+	UpdatedAt    time.Time // Last ROOMSTATE or USERSTATE update
+}
+
+// IsPrivileged reports whether the bot is a moderator, VIP or the broadcaster
+// in the channel. Privileged users are exempt from slow mode, followers-only
+// and subscribers-only mode, and from link blocking.
+func (s *ChannelState) IsPrivileged() bool {
+	return s.IsMod || s.IsVIP || s.IsBroadcaster
+}
+
+// MessageInterval returns how long the bot must wait between messages
+// because of slow mode (0 if slow mode is off or the bot is privileged).
+func (s *ChannelState) MessageInterval() time.Duration {
+	if s.IsPrivileged() {
+		return 0
+	}
+	return time.Duration(s.Slow) * time.Second
+}
+
+// channelTracker holds the state and recent chatters of a channel.
+type channelTracker struct {
+	state    ChannelState
+	chatters map[string]time.Time // login -> last seen
+}
+
+func newChannelTracker(channel string) *channelTracker {
+	return &channelTracker{
+		state:    ChannelState{Channel: channel, FollowersOnly: -1},
+		chatters: make(map[string]time.Time),
+	}
+}
+
+// WithMaxTrackedChatters sets how many recent chatters are kept per channel
+// (default 1000). The least recently seen chatters are dropped first.
+func WithMaxTrackedChatters(n int) IRCOption {
+	return func(c *IRCClient) {
+		c.maxChatters = n
+	}
+}
+
+// ChannelState returns a snapshot of a joined channel's state, or nil if the
+// channel is not joined.
+func (c *IRCClient) ChannelState(channel string) *ChannelState {
+	channel = normalizeChannelName(channel)
+
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	t, ok := c.states[channel]
+	if !ok {
+		return nil
+	}
+	state := t.state
+	state.Badges = maps.Clone(t.state.Badges)
+	state.EmoteSets = append([]string(nil), t.state.EmoteSets...)
+	state.ChatterCount = len(t.chatters)
+	return &state
+}
+
+// RecentChatters returns the logins seen in a channel (through JOIN or
+// PRIVMSG, and not since PART) within the given duration, most recent first.
+// A duration of 0 returns every tracked chatter.
+func (c *IRCClient) RecentChatters(channel string, within time.Duration) []string {
+	channel = normalizeChannelName(channel)
+
+	c.stateMu.RLock()
+	t, ok := c.states[channel]
+	if !ok {
+		c.stateMu.RUnlock()
+		return nil
+	}
+	cutoff := time.Time{}
+	if within > 0 {
+		cutoff = time.Now().Add(-within)
+	}
+	type seen struct {
+		login string
+		at    time.Time
+	}
+	var list []seen
+	for login, at := range t.chatters {
+		if at.After(cutoff) {
+			list = append(list, seen{login, at})
+		}
+	}
+	c.stateMu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].at.Equal(list[j].at) {
+			return list[i].login < list[j].login
+		}
+		return list[i].at.After(list[j].at)
+	})
+	logins := make([]string, len(list))
+	for i, s := range list {
+		logins[i] = s.login
+	}
+	return logins
+}
+
+// trackJoinedChannel starts tracking a channel's state.
+func (c *IRCClient) trackJoinedChannel(channel string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if c.states == nil {
+		c.states = make(map[string]*channelTracker)
+	}
+	if _, ok := c.states[channel]; !ok {
+		c.states[channel] = newChannelTracker(channel)
+	}
+}
+
+// untrackChannel drops a parted channel's state.
+func (c *IRCClient) untrackChannel(channel string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	delete(c.states, channel)
+}
+
+// trackRoomState merges a ROOMSTATE into the channel state. Twitch sends the
+// full state on join and only the changed tag afterwards, so only tags that
+// are present are applied.
+func (c *IRCClient) trackRoomState(msg *IRCMessage) {
+	if len(msg.Params) == 0 {
+		return
+	}
+	channel := parseChannel(msg.Params[0])
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	t, ok := c.states[channel]
+	if !ok {
+		return
+	}
+	s := &t.state
+	if v, ok := msg.Tags["room-id"]; ok {
+		s.RoomID = v
+	}
+	if v, ok := msg.Tags["emote-only"]; ok {
+		s.EmoteOnly = parseBool(v)
+	}
+	if v, ok := msg.Tags["followers-only"]; ok {
+		s.FollowersOnly = parseInt(v)
+	}
+	if v, ok := msg.Tags["r9k"]; ok {
+		s.R9K = parseBool(v)
+	}
+	if v, ok := msg.Tags["slow"]; ok {
+		s.Slow = parseInt(v)
+	}
+	if v, ok := msg.Tags["subs-only"]; ok {
+		s.SubsOnly = parseBool(v)
+	}
+	s.HasRoomState = true
+	s.UpdatedAt = time.Now()
+}
+
+// trackUserState records the bot's own status in a channel.
+func (c *IRCClient) trackUserState(state *UserState) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	t, ok := c.states[state.Channel]
+	if !ok {
+		return
+	}
+	s := &t.state
+	s.IsMod = state.IsMod || state.Badges[BadgeModerator] != "" || state.Badges[BadgeLeadModerator] != ""
+	s.IsVIP = state.Badges[BadgeVIP] != ""
+	s.IsBroadcaster = state.Badges[BadgeBroadcaster] != ""
+	s.IsSubscriber = state.IsSubscriber
+	s.Badges = maps.Clone(state.Badges)
+	s.EmoteSets = append([]string(nil), state.EmoteSets...)
+	s.HasUserState = true
+	s.UpdatedAt = time.Now()
+}
+
+// trackChatter records a chatter seen in a channel.
+func (c *IRCClient) trackChatter(channel, login string) {
+	if login == "" || login == c.nick {
+		return
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	t, ok := c.states[channel]
+	if !ok {
+		return
+	}
+	t.chatters[login] = time.Now()
+
+	limit := c.maxChatters
+	if limit <= 0 {
+		limit = defaultMaxTrackedChatters
+	}
+	for len(t.chatters) > limit {
+		oldest, oldestAt := "", time.Time{}
+		for l, at := range t.chatters {
+			if oldest == "" || at.Before(oldestAt) {
+				oldest, oldestAt = l, at
+			}
+		}
+		delete(t.chatters, oldest)
+	}
+}
+
+// untrackChatter removes a chatter that left a channel.
+func (c *IRCClient) untrackChatter(channel, login string) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if t, ok := c.states[channel]; ok {
+		delete(t.chatters, login)
+	}
+}
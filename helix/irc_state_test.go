@@ -0,0 +1,125 @@
+package helix
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newStateTestClient(t *testing.T, opts ...IRCOption) *IRCClient {
+	t.Helper()
+	client, err := NewIRCClientE("mybot", "token", opts...)
+	if err != nil {
+		t.Fatalf("NewIRCClientE() error = %v", err)
+	}
+	if err := client.Join("#Dallas"); err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	return client
+}
+
+func TestIRCClient_ChannelState_RoomState(t *testing.T) {
+	client := newStateTestClient(t)
+
+	state := client.ChannelState("dallas")
+	if state == nil {
+		t.Fatal("ChannelState() = nil for joined channel")
+	}
+	if state.HasRoomState || state.FollowersOnly != -1 {
+		t.Errorf("initial state = %+v", state)
+	}
+
+	client.handleMessage("@emote-only=0;followers-only=-1;r9k=0;room-id=12345;slow=0;subs-only=0 :tmi.twitch.tv ROOMSTATE #dallas")
+	// Partial updates only carry the changed mode
+	client.handleMessage("@room-id=12345;slow=30 :tmi.twitch.tv ROOMSTATE #dallas")
+	client.handleMessage("@followers-only=10;room-id=12345 :tmi.twitch.tv ROOMSTATE #dallas")
+	client.handleMessage("@emote-only=1;room-id=12345 :tmi.twitch.tv ROOMSTATE #dallas")
+
+	state = client.ChannelState("#DALLAS")
+	want := ChannelState{Channel: "dallas", RoomID: "12345", EmoteOnly: true, FollowersOnly: 10, Slow: 30, HasRoomState: true}
+	state.UpdatedAt = time.Time{}
+	if !reflect.DeepEqual(*state, want) {
+		t.Errorf("ChannelState() = %+v, want %+v", *state, want)
+	}
+	if state.MessageInterval() != 30*time.Second {
+		t.Errorf("MessageInterval() = %v, want 30s", state.MessageInterval())
+	}
+
+	// Other channels are not tracked
+	client.handleMessage("@room-id=999;slow=5 :tmi.twitch.tv ROOMSTATE #other")
+	if client.ChannelState("other") != nil {
+		t.Error("ChannelState() for unjoined channel should be nil")
+	}
+}
+
+func TestIRCClient_ChannelState_UserState(t *testing.T) {
+	client := newStateTestClient(t)
+
+	var received *UserState
+	client.onUserState = func(s *UserState) { received = s }
+
+	client.handleMessage("@room-id=12345;slow=60 :tmi.twitch.tv ROOMSTATE #dallas")
+	client.handleMessage("@badge-info=;badges=vip/1;color=#0D4200;display-name=MyBot;emote-sets=0,33,50;mod=0;subscriber=0;user-type= :tmi.twitch.tv USERSTATE #dallas")
+
+	if received == nil {
+		t.Error("USERSTATE handler not called")
+	}
+	state := client.ChannelState("dallas")
+	if !state.HasUserState || !state.IsVIP || state.IsMod || !state.IsPrivileged() {
+		t.Errorf("ChannelState() = %+v", state)
+	}
+	if state.MessageInterval() != 0 {
+		t.Errorf("MessageInterval() = %v, want 0 for VIP", state.MessageInterval())
+	}
+	if !reflect.DeepEqual(state.EmoteSets, []string{"0", "33", "50"}) {
+		t.Errorf("EmoteSets = %v", state.EmoteSets)
+	}
+
+	client.handleMessage("@badges=moderator/1;mod=1 :tmi.twitch.tv USERSTATE #dallas")
+	state = client.ChannelState("dallas")
+	if !state.IsMod || state.IsVIP {
+		t.Errorf("after mod USERSTATE: %+v", state)
+	}
+
+	// Snapshots are copies
+	state.Badges["vip"] = "1"
+	if client.ChannelState("dallas").Badges["vip"] != "" {
+		t.Error("ChannelState() returned shared badges map")
+	}
+}
+
+func TestIRCClient_RecentChatters(t *testing.T) {
+	client := newStateTestClient(t, WithMaxTrackedChatters(2))
+
+	client.handleMessage(":alice!alice@alice.tmi.twitch.tv JOIN #dallas")
+	time.Sleep(2 * time.Millisecond)
+	client.handleMessage("@login=bob :bob!bob@bob.tmi.twitch.tv PRIVMSG #dallas :hi")
+	time.Sleep(2 * time.Millisecond)
+	client.handleMessage(":mybot!mybot@mybot.tmi.twitch.tv JOIN #dallas")
+
+	if got := client.RecentChatters("dallas", 0); !reflect.DeepEqual(got, []string{"bob", "alice"}) {
+		t.Errorf("RecentChatters() = %v, want [bob alice]", got)
+	}
+
+	// Over the limit, the least recently seen chatter is dropped
+	time.Sleep(2 * time.Millisecond)
+	client.handleMessage("@login=carol :carol!carol@carol.tmi.twitch.tv PRIVMSG #dallas :hey")
+	if got := client.RecentChatters("dallas", 0); !reflect.DeepEqual(got, []string{"carol", "bob"}) {
+		t.Errorf("RecentChatters() = %v, want [carol bob]", got)
+	}
+
+	client.handleMessage(":bob!bob@bob.tmi.twitch.tv PART #dallas")
+	if got := client.RecentChatters("dallas", time.Minute); !reflect.DeepEqual(got, []string{"carol"}) {
+		t.Errorf("RecentChatters() after PART = %v, want [carol]", got)
+	}
+	if n := client.ChannelState("dallas").ChatterCount; n != 1 {
+		t.Errorf("ChatterCount = %d, want 1", n)
+	}
+
+	if err := client.Part("dallas"); err != nil {
+		t.Fatalf("Part() error = %v", err)
+	}
+	if client.ChannelState("dallas") != nil || client.RecentChatters("dallas", 0) != nil {
+		t.Error("state should be dropped after Part")
+	}
+}
@@ -6,11 +6,13 @@ import "time"
 type ChatMessage struct {
 	ID                     string            // Unique message ID
 	Channel                string            // Channel name (without #)
+	RoomID                 string            // Channel's user ID
 	User                   string            // Username (login)
 	UserID                 string            // User's Twitch ID
 	Message                string            // Message content
 	Emotes                 []IRCEmote        // Emotes used in the message
 	Badges                 map[string]string // User badges (badge-name -> version)
+	BadgeOrder             []string          // Badge names in display order
 	BadgeInfo              map[string]string // Additional badge info (e.g., subscriber months)
 	Color                  string            // User's chat color (#RRGGBB)
 	DisplayName            string            // User's display name
@@ -49,6 +51,7 @@ type UserNotice struct {
 	Message       string            // Optional user message
 	SystemMessage string            // System-generated message
 	MsgParams     map[string]string // Type-specific parameters
+	Data          UserNoticeData    // Typed MsgParams for known types (nil otherwise)
 	Badges        map[string]string // User badges
 	BadgeInfo     map[string]string // Badge info
 	Color         string            // User color
@@ -74,6 +77,9 @@ type UserNotice struct {
 // - msg-param-viewerCount: Raid viewer count
 // - msg-param-displayName: Raid display name
 // - msg-param-login: Raid login
+//
+// For known types the same values are available, already converted, in
+// UserNotice.Data (SubNotice, ResubNotice, SubGiftNotice, RaidNotice, etc.).
 
 // UserNotice types
 const (
@@ -91,6 +97,10 @@ const (
 	UserNoticeTypeCommunityPayForward = "communitypayforward"
 	UserNoticeTypeStandardPayForward  = "standardpayforward"
 	UserNoticeTypeAnnouncement        = "announcement"
+	UserNoticeTypeAnonSubMysteryGift  = "anonsubmysterygift"
+	UserNoticeTypeAnonGiftPaidUpgrade = "anongiftpaidupgrade"
+	UserNoticeTypeCharityDonation     = "charitydonation"
+	UserNoticeTypeViewerMilestone     = "viewermilestone"
 )
 
 // RoomState represents a ROOMSTATE message.
@@ -0,0 +1,243 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditEntryFromModerate(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantAction string
+		wantTarget string
+		wantReason string
+		wantDetail map[string]string
+	}{
+		{
+			name:       "ban",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"ban","ban":{"user_id":"u","user_login":"troll","reason":"spam"}}`,
+			wantAction: "ban", wantTarget: "u", wantReason: "spam",
+		},
+		{
+			name:       "timeout",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"timeout","timeout":{"user_id":"u","user_login":"troll","expires_at":"2026-01-01T00:10:00Z"}}`,
+			wantAction: "timeout", wantTarget: "u",
+		},
+		{
+			name:       "vip",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"vip","vip":{"user_id":"v","user_login":"friend"}}`,
+			wantAction: "vip", wantTarget: "v",
+		},
+		{
+			name:       "delete",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"delete","delete":{"user_id":"u","message_id":"abc","message_body":"bad"}}`,
+			wantAction: "delete", wantTarget: "u",
+			wantDetail: map[string]string{"message_id": "abc", "message_body": "bad"},
+		},
+		{
+			name:       "slow",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"slow","slow":{"wait_time_seconds":30}}`,
+			wantAction: "slow",
+			wantDetail: map[string]string{"wait_time_seconds": "30"},
+		},
+		{
+			name:       "blocked terms",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"add_blocked_term","automod_terms":{"action":"add","list":"blocked","terms":["a","b"]}}`,
+			wantAction: "add_blocked_term",
+			wantDetail: map[string]string{"list": "blocked", "terms": "a; b", "from_automod": "false"},
+		},
+		{
+			name:       "emote only",
+			data:       `{"broadcaster_user_id":"1","moderator_user_id":"m","action":"emoteonly"}`,
+			wantAction: "emoteonly",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event ChannelModerateEvent
+			if err := json.Unmarshal([]byte(tt.data), &event); err != nil {
+				t.Fatal(err)
+			}
+			e := AuditEntryFromModerate(&event, time.Now())
+			if e.Action != tt.wantAction || e.TargetUserID != tt.wantTarget || e.Reason != tt.wantReason || e.ModeratorID != "m" {
+				t.Errorf("entry = %+v", e)
+			}
+			for k, v := range tt.wantDetail {
+				if e.Details[k] != v {
+					t.Errorf("Details[%s] = %q, want %q", k, e.Details[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLog_DedupeAndQuery(t *testing.T) {
+	store := NewMemoryAuditStore()
+	log := NewAuditLog(store)
+	ctx := context.Background()
+
+	events := []struct {
+		eventType string
+		data      string
+	}{
+		{EventSubTypeChannelModerate, `{"broadcaster_user_id":"1","moderator_user_id":"m1","action":"timeout","timeout":{"user_id":"u1","expires_at":"2026-01-01T00:10:00Z"}}`},
+		// The same timeout reported by channel.ban is dropped
+		{EventSubTypeChannelBan, `{"broadcaster_user_id":"1","moderator_user_id":"m1","user_id":"u1","reason":"","banned_at":"2026-01-01T00:00:00Z","ends_at":"2026-01-01T00:10:00Z","is_permanent":false}`},
+		{EventSubTypeChannelBan, `{"broadcaster_user_id":"1","moderator_user_id":"m2","user_id":"u2","reason":"hate","banned_at":"2026-01-01T00:01:00Z","is_permanent":true}`},
+		{EventSubTypeChannelWarningSend, `{"broadcaster_user_id":"1","moderator_user_id":"m1","user_id":"u3","reason":"be nice","chat_rules_cited":["Rule 1"]}`},
+		{EventSubTypeChannelUnban, `{"broadcaster_user_id":"1","moderator_user_id":"m2","user_id":"u2"}`},
+		{"channel.follow", `{}`},
+	}
+	for _, ev := range events {
+		if err := log.HandleEvent(ctx, ev.eventType, json.RawMessage(ev.data)); err != nil {
+			t.Fatalf("HandleEvent(%s) error = %v", ev.eventType, err)
+		}
+	}
+
+	all, err := log.Query(ctx, AuditQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(all), all)
+	}
+
+	byMod, _ := log.Query(ctx, AuditQuery{ModeratorID: "m2"})
+	if len(byMod) != 2 || byMod[0].Action != AuditActionBan || byMod[1].Action != AuditActionUnban {
+		t.Errorf("moderator m2 entries = %+v", byMod)
+	}
+
+	byTarget, _ := log.Query(ctx, AuditQuery{TargetUserID: "u3", Actions: []string{AuditActionWarn}})
+	if len(byTarget) != 1 || byTarget[0].Details["chat_rules_cited"] != "Rule 1" {
+		t.Errorf("u3 warnings = %+v", byTarget)
+	}
+
+	ranged, _ := log.Query(ctx, AuditQuery{
+		Since: time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC),
+		Until: time.Date(2026, 1, 1, 0, 2, 0, 0, time.UTC),
+	})
+	if len(ranged) != 1 || ranged[0].TargetUserID != "u2" {
+		t.Errorf("time range entries = %+v", ranged)
+	}
+
+	limited, _ := log.Query(ctx, AuditQuery{Limit: 1})
+	if len(limited) != 1 {
+		t.Errorf("limited entries = %d, want 1", len(limited))
+	}
+}
+
+func TestJSONLAuditStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := context.Background()
+
+	store, err := NewJSONLAuditStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, action := range []string{AuditActionBan, AuditActionDelete, AuditActionBan} {
+		err := store.Append(ctx, &AuditEntry{
+			Time:          base.Add(time.Duration(i) * time.Minute),
+			BroadcasterID: "1",
+			ModeratorID:   "m",
+			Action:        action,
+			TargetUserID:  "u",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and append to the existing file
+	store, err = NewJSONLAuditStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.Append(ctx, &AuditEntry{Time: base.Add(time.Hour), Action: AuditActionWarn}); err != nil {
+		t.Fatal(err)
+	}
+
+	bans, err := store.Query(ctx, AuditQuery{Actions: []string{AuditActionBan}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bans) != 2 || !bans[1].Time.Equal(base.Add(2*time.Minute)) {
+		t.Errorf("bans = %+v", bans)
+	}
+	all, _ := store.Query(ctx, AuditQuery{})
+	if len(all) != 4 {
+		t.Errorf("all = %d entries, want 4", len(all))
+	}
+}
+
+func TestWriteAuditCSV(t *testing.T) {
+	expires := time.Date(2026, 3, 1, 12, 10, 0, 0, time.UTC)
+	entries := []AuditEntry{{
+		Time:            time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		BroadcasterID:   "1",
+		ModeratorLogin:  "mod",
+		Action:          AuditActionTimeout,
+		TargetUserLogin: "troll",
+		Reason:          "spam, again",
+		ExpiresAt:       &expires,
+		Details:         map[string]string{"b": "2", "a": "1"},
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteAuditCSV(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || len(records[1]) != len(auditCSVHeader) {
+		t.Fatalf("records = %v", records)
+	}
+	row := records[1]
+	if row[0] != "2026-03-01T12:00:00Z" || row[5] != "timeout" || row[8] != "spam, again" || row[9] != "2026-03-01T12:10:00Z" || row[11] != "a=1; b=2" {
+		t.Errorf("row = %v", row)
+	}
+}
+
+func TestWriteAuditCSV_Formulas(t *testing.T) {
+	entries := []AuditEntry{{
+		Action:          AuditActionDelete,
+		TargetUserLogin: "@troll",
+		Reason:          "+1 spam",
+		Details:         map[string]string{"message_body": "=HYPERLINK(\"http://evil\",\"click\")"},
+	}, {
+		Action: AuditActionBan,
+		Reason: "-rep",
+	}, {
+		Action: AuditActionBan,
+		Reason: "\tlead tab",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteAuditCSV(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := records[1]
+	if row[7] != "'@troll" || row[8] != "'+1 spam" || row[11] != `message_body==HYPERLINK("http://evil","click")` {
+		t.Errorf("row = %q", row)
+	}
+	if records[2][8] != "'-rep" || records[3][8] != "'\tlead tab" {
+		t.Errorf("reasons = %q, %q", records[2][8], records[3][8])
+	}
+}
@@ -0,0 +1,256 @@
+package helix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// BulkStatus is the outcome of one item in a bulk operation.
+type BulkStatus string
+
+// Bulk operation outcomes
+const (
+	BulkSucceeded BulkStatus = "succeeded"
+	BulkSkipped   BulkStatus = "skipped" // Already in the requested state, or a duplicate in the input
+	BulkFailed    BulkStatus = "failed"
+)
+
+// BulkOptions configures bulk moderation operations.
+type BulkOptions struct {
+	// MaxConcurrent limits concurrent requests (default 10)
+	MaxConcurrent int
+	// OnProgress is called after each item completes. Calls are serialized.
+	OnProgress func(BulkProgress)
+}
+
+// BulkProgress reports the progress of a bulk operation.
+type BulkProgress struct {
+	Done      int
+	Total     int
+	Succeeded int
+	Skipped   int
+	Failed    int
+	ID        string     // Item that just completed
+	Status    BulkStatus // Its outcome
+	Err       error      // Its error, if any
+}
+
+// BulkItemResult is the result for one item of a bulk operation.
+type BulkItemResult[T any] struct {
+	Index    int        // Position in the input
+	ID       string     // User ID, message ID or term text
+	Status   BulkStatus // Outcome
+	Data     T          // Response data on success
+	Err      error      // Error for failed (and API-skipped) items
+	APIError *APIError  // Err as an *APIError, when it is one
+}
+
+// BulkReport is the structured result of a bulk operation.
+type BulkReport[T any] struct {
+	Results   []BulkItemResult[T] // Every item, in input order
+	Succeeded []BulkItemResult[T]
+	Skipped   []BulkItemResult[T]
+	Failed    []BulkItemResult[T]
+}
+
+// FailedIDs returns the IDs of the failed items, in input order.
+func (r *BulkReport[T]) FailedIDs() []string {
+	ids := make([]string, len(r.Failed))
+	for i, f := range r.Failed {
+		ids[i] = f.ID
+	}
+	return ids
+}
+
+// Err returns nil if no item failed, or an error summarizing the failures.
+// The error wraps the individual item errors.
+func (r *BulkReport[T]) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	errs := make([]error, len(r.Failed))
+	for i, f := range r.Failed {
+		errs[i] = fmt.Errorf("%s: %w", f.ID, f.Err)
+	}
+	return fmt.Errorf("%d of %d items failed: %w", len(r.Failed), len(r.Results), errors.Join(errs...))
+}
+
+// BanUsers bans or times out many users, e.g. during a hate raid. Users that
+// are already banned are reported as skipped.
+// Requires: moderator:manage:banned_users scope.
+func (c *Client) BanUsers(ctx context.Context, broadcasterID, moderatorID string, users []BanUserData, opts *BulkOptions) *BulkReport[*BanUserResponse] {
+	ids := make([]string, len(users))
+	for i, u := range users {
+		ids[i] = u.UserID
+	}
+	return runBulk(ctx, c, ids, opts, isAlreadyBannedError, func(ctx context.Context, i int) (*BanUserResponse, error) {
+		return c.BanUser(ctx, &BanUserParams{
+			BroadcasterID: broadcasterID,
+			ModeratorID:   moderatorID,
+			Data:          users[i],
+		})
+	})
+}
+
+// UnbanUsers removes bans and timeouts from many users. Users that are not
+// banned are reported as skipped.
+// Requires: moderator:manage:banned_users scope.
+func (c *Client) UnbanUsers(ctx context.Context, broadcasterID, moderatorID string, userIDs []string, opts *BulkOptions) *BulkReport[struct{}] {
+	return runBulk(ctx, c, userIDs, opts, isNotBannedError, func(ctx context.Context, i int) (struct{}, error) {
+		return struct{}{}, c.UnbanUser(ctx, broadcasterID, moderatorID, userIDs[i])
+	})
+}
+
+// AddBlockedTerms adds many blocked terms. Twitch returns the existing term
+// when a term is already blocked, so re-adding is safe.
+// Requires: moderator:manage:blocked_terms scope.
+func (c *Client) AddBlockedTerms(ctx context.Context, broadcasterID, moderatorID string, terms []string, opts *BulkOptions) *BulkReport[*BlockedTerm] {
+	return runBulk(ctx, c, terms, opts, nil, func(ctx context.Context, i int) (*BlockedTerm, error) {
+		return c.AddBlockedTerm(ctx, &AddBlockedTermParams{
+			BroadcasterID: broadcasterID,
+			ModeratorID:   moderatorID,
+			Text:          terms[i],
+		})
+	})
+}
+
+// DeleteChatMessagesByID deletes many chat messages. Messages that no longer
+// exist (already deleted, or older than 6 hours) are reported as skipped.
+// Requires: moderator:manage:chat_messages scope.
+func (c *Client) DeleteChatMessagesByID(ctx context.Context, broadcasterID, moderatorID string, messageIDs []string, opts *BulkOptions) *BulkReport[struct{}] {
+	return runBulk(ctx, c, messageIDs, opts, isNotFoundError, func(ctx context.Context, i int) (struct{}, error) {
+		if messageIDs[i] == "" {
+			// An empty ID would clear the whole chat
+			return struct{}{}, errors.New("empty message ID")
+		}
+		return struct{}{}, c.DeleteChatMessages(ctx, &DeleteChatMessagesParams{
+			BroadcasterID: broadcasterID,
+			ModeratorID:   moderatorID,
+			MessageID:     messageIDs[i],
+		})
+	})
+}
+
+// isAlreadyBannedError reports whether a Ban User error means the user is
+// already banned.
+func isAlreadyBannedError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Message), "already banned")
+}
+
+// isNotBannedError reports whether an Unban User error means the user is not banned.
+func isNotBannedError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Message), "not banned")
+}
+
+// isNotFoundError reports whether err is a 404 API error.
+func isNotFoundError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// runBulk runs do for each ID with bounded concurrency, waiting for the rate
+// limit before each request. Duplicate IDs are skipped without a request, and
+// errors matched by skip are reported as skipped rather than failed.
+func runBulk[T any](ctx context.Context, c *Client, ids []string, opts *BulkOptions, skip func(error) bool, do func(ctx context.Context, i int) (T, error)) *BulkReport[T] {
+	maxConcurrent := 10
+	var onProgress func(BulkProgress)
+	if opts != nil {
+		if opts.MaxConcurrent > 0 {
+			maxConcurrent = opts.MaxConcurrent
+		}
+		onProgress = opts.OnProgress
+	}
+
+	report := &BulkReport[T]{Results: make([]BulkItemResult[T], len(ids))}
+	progress := BulkProgress{Total: len(ids)}
+	var mu sync.Mutex
+
+	complete := func(res BulkItemResult[T]) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		report.Results[res.Index] = res
+		progress.Done++
+		switch res.Status {
+		case BulkSucceeded:
+			progress.Succeeded++
+		case BulkSkipped:
+			progress.Skipped++
+		case BulkFailed:
+			progress.Failed++
+		}
+		if onProgress != nil {
+			p := progress
+			p.ID, p.Status, p.Err = res.ID, res.Status, res.Err
+			onProgress(p)
+		}
+	}
+
+	sem := make(chan struct{}, maxConcurrent)
+	var wg sync.WaitGroup
+	seen := make(map[string]bool, len(ids))
+
+	for i, id := range ids {
+		if seen[id] {
+			complete(BulkItemResult[T]{Index: i, ID: id, Status: BulkSkipped})
+			continue
+		}
+		seen[id] = true
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			complete(BulkItemResult[T]{Index: i, ID: id, Status: BulkFailed, Err: ctx.Err()})
+			continue
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			res := BulkItemResult[T]{Index: i, ID: id}
+			err := ctx.Err()
+			if err == nil {
+				err = c.WaitForRateLimit(ctx)
+			}
+			if err == nil {
+				res.Data, err = do(ctx, i)
+			}
+
+			switch {
+			case err == nil:
+				res.Status = BulkSucceeded
+			case skip != nil && skip(err):
+				res.Status = BulkSkipped
+				res.Err = err
+			default:
+				res.Status = BulkFailed
+				res.Err = err
+			}
+			if err != nil {
+				errors.As(err, &res.APIError)
+			}
+			complete(res)
+		})
+	}
+	wg.Wait()
+
+	for _, res := range report.Results {
+		switch res.Status {
+		case BulkSucceeded:
+			report.Succeeded = append(report.Succeeded, res)
+		case BulkSkipped:
+			report.Skipped = append(report.Skipped, res)
+		case BulkFailed:
+			report.Failed = append(report.Failed, res)
+		}
+	}
+	return report
+}
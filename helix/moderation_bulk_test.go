@@ -0,0 +1,153 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClient_BanUsers(t *testing.T) {
+	var calls int32
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var body struct {
+			Data BanUserData `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch body.Data.UserID {
+		case "banned":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field is already banned."}`))
+		case "mod":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field may not be banned."}`))
+		default:
+			resp := Response[BanUserResponse]{Data: []BanUserResponse{{BroadcasterID: "1", UserID: body.Data.UserID}}}
+			_ = json.NewEncoder(w).Encode(resp)
+		}
+	})
+	defer server.Close()
+
+	var mu sync.Mutex
+	var progress []BulkProgress
+	users := []BanUserData{{UserID: "a"}, {UserID: "banned"}, {UserID: "mod"}, {UserID: "b"}, {UserID: "a"}}
+	report := client.BanUsers(context.Background(), "1", "2", users, &BulkOptions{
+		MaxConcurrent: 2,
+		OnProgress: func(p BulkProgress) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+	})
+
+	if calls != 4 {
+		t.Errorf("requests = %d, want 4 (duplicate skipped)", calls)
+	}
+	if len(report.Results) != 5 || len(report.Succeeded) != 2 || len(report.Skipped) != 2 || len(report.Failed) != 1 {
+		t.Fatalf("report = %d results, %d succeeded, %d skipped, %d failed",
+			len(report.Results), len(report.Succeeded), len(report.Skipped), len(report.Failed))
+	}
+	if report.Results[0].Data == nil || report.Results[0].Data.UserID != "a" {
+		t.Errorf("result 0 data = %+v", report.Results[0].Data)
+	}
+	if report.Results[1].Status != BulkSkipped || report.Results[1].APIError == nil {
+		t.Errorf("already banned result = %+v", report.Results[1])
+	}
+	failed := report.Failed[0]
+	if failed.ID != "mod" || failed.APIError == nil || failed.APIError.StatusCode != http.StatusBadRequest {
+		t.Errorf("failed result = %+v", failed)
+	}
+	if ids := report.FailedIDs(); len(ids) != 1 || ids[0] != "mod" {
+		t.Errorf("FailedIDs() = %v", ids)
+	}
+	if report.Err() == nil {
+		t.Error("Err() = nil, want error")
+	}
+
+	if len(progress) != 5 {
+		t.Fatalf("progress callbacks = %d, want 5", len(progress))
+	}
+	last := progress[len(progress)-1]
+	if last.Done != 5 || last.Total != 5 || last.Succeeded != 2 || last.Skipped != 2 || last.Failed != 1 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestClient_UnbanUsers(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("user_id") == "clean" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"The user specified in the user_id field is not banned."}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	report := client.UnbanUsers(context.Background(), "1", "2", []string{"a", "clean"}, nil)
+	if len(report.Succeeded) != 1 || len(report.Skipped) != 1 || len(report.Failed) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+	if err := report.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestClient_AddBlockedTerms(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		resp := Response[BlockedTerm]{Data: []BlockedTerm{{ID: "id-" + body.Text, Text: body.Text}}}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	defer server.Close()
+
+	report := client.AddBlockedTerms(context.Background(), "1", "2", []string{"foo", "bar"}, nil)
+	if len(report.Succeeded) != 2 {
+		t.Fatalf("succeeded = %d, want 2", len(report.Succeeded))
+	}
+	if report.Results[1].Data == nil || report.Results[1].Data.ID != "id-bar" {
+		t.Errorf("result 1 data = %+v", report.Results[1].Data)
+	}
+}
+
+func TestClient_DeleteChatMessagesByID(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("message_id") == "gone" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"Not Found","status":404,"message":"The ID in message_id was not found."}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	report := client.DeleteChatMessagesByID(context.Background(), "1", "2", []string{"m1", "gone", ""}, nil)
+	if len(report.Succeeded) != 1 || len(report.Skipped) != 1 || len(report.Failed) != 1 {
+		t.Errorf("report = %d succeeded, %d skipped, %d failed", len(report.Succeeded), len(report.Skipped), len(report.Failed))
+	}
+	if report.Failed[0].Index != 2 {
+		t.Errorf("failed index = %d, want 2 (empty ID)", report.Failed[0].Index)
+	}
+}
+
+func TestClient_BanUsers_ContextCanceled(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report := client.BanUsers(ctx, "1", "2", []BanUserData{{UserID: "a"}, {UserID: "b"}}, nil)
+	if len(report.Failed) != 2 {
+		t.Errorf("failed = %d, want 2", len(report.Failed))
+	}
+}
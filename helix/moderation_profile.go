@@ -0,0 +1,353 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ModerationProfile is a blocked-term list and AutoMod baseline that can be
+// exported from one channel, shared as JSON, and synced to others.
+type ModerationProfile struct {
+	BlockedTerms []string        `json:"blocked_terms"`
+	AutoMod      *AutoModProfile `json:"automod,omitempty"` // Nil leaves AutoMod alone
+}
+
+// AutoModProfile is a set of AutoMod levels (0-4). Nil levels are left
+// unchanged. When OverallLevel is set, Twitch derives the individual levels
+// from it and they are ignored.
+type AutoModProfile struct {
+	OverallLevel            *int `json:"overall_level,omitempty"`
+	Disability              *int `json:"disability,omitempty"`
+	Aggression              *int `json:"aggression,omitempty"`
+	SexualitySexOrGender    *int `json:"sexuality_sex_or_gender,omitempty"`
+	Misogyny                *int `json:"misogyny,omitempty"`
+	Bullying                *int `json:"bullying,omitempty"`
+	Swearing                *int `json:"swearing,omitempty"`
+	RaceEthnicityOrReligion *int `json:"race_ethnicity_or_religion,omitempty"`
+	SexBasedTerms           *int `json:"sex_based_terms,omitempty"`
+}
+
+// AutoModProfileFromSettings converts a channel's AutoMod settings into a
+// profile. If the channel uses an overall level, only that is kept.
+func AutoModProfileFromSettings(s *AutoModSettings) *AutoModProfile {
+	if s.OverallLevel != nil {
+		level := *s.OverallLevel
+		return &AutoModProfile{OverallLevel: &level}
+	}
+	// Copy the settings so the profile doesn't alias them
+	c := *s
+	return &AutoModProfile{
+		Disability:              &c.Disability,
+		Aggression:              &c.Aggression,
+		SexualitySexOrGender:    &c.SexualitySexOrGender,
+		Misogyny:                &c.Misogyny,
+		Bullying:                &c.Bullying,
+		Swearing:                &c.Swearing,
+		RaceEthnicityOrReligion: &c.RaceEthnicityOrReligion,
+		SexBasedTerms:           &c.SexBasedTerms,
+	}
+}
+
+// autoModLevel is one AutoMod setting, for diffing.
+type autoModLevel struct {
+	name    string
+	profile *int
+	current int
+	set     func(p *UpdateAutoModSettingsParams, v *int)
+}
+
+// levels lists the individual settings of a profile against current settings.
+func (p *AutoModProfile) levels(s *AutoModSettings) []autoModLevel {
+	return []autoModLevel{
+		{"disability", p.Disability, s.Disability, func(u *UpdateAutoModSettingsParams, v *int) { u.Disability = v }},
+		{"aggression", p.Aggression, s.Aggression, func(u *UpdateAutoModSettingsParams, v *int) { u.Aggression = v }},
+		{"sexuality_sex_or_gender", p.SexualitySexOrGender, s.SexualitySexOrGender, func(u *UpdateAutoModSettingsParams, v *int) { u.SexualitySexOrGender = v }},
+		{"misogyny", p.Misogyny, s.Misogyny, func(u *UpdateAutoModSettingsParams, v *int) { u.Misogyny = v }},
+		{"bullying", p.Bullying, s.Bullying, func(u *UpdateAutoModSettingsParams, v *int) { u.Bullying = v }},
+		{"swearing", p.Swearing, s.Swearing, func(u *UpdateAutoModSettingsParams, v *int) { u.Swearing = v }},
+		{"race_ethnicity_or_religion", p.RaceEthnicityOrReligion, s.RaceEthnicityOrReligion, func(u *UpdateAutoModSettingsParams, v *int) { u.RaceEthnicityOrReligion = v }},
+		{"sex_based_terms", p.SexBasedTerms, s.SexBasedTerms, func(u *UpdateAutoModSettingsParams, v *int) { u.SexBasedTerms = v }},
+	}
+}
+
+// ReadModerationProfile decodes a JSON moderation profile. An unknown AutoMod
+// key is an error, since dropping it would silently keep that level as is.
+func ReadModerationProfile(r io.Reader) (*ModerationProfile, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var p ModerationProfile
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("decoding moderation profile: %w", err)
+	}
+	return &p, nil
+}
+
+// WriteModerationProfile encodes a moderation profile as indented JSON.
+func WriteModerationProfile(w io.Writer, p *ModerationProfile) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(p)
+}
+
+// GetAllBlockedTerms pages through all of a channel's blocked terms.
+// Requires: moderator:read:blocked_terms scope.
+func (c *Client) GetAllBlockedTerms(ctx context.Context, broadcasterID, moderatorID string) ([]BlockedTerm, error) {
+	var terms []BlockedTerm
+	cursor := ""
+	for {
+		resp, err := c.GetBlockedTerms(ctx, &GetBlockedTermsParams{
+			BroadcasterID:    broadcasterID,
+			ModeratorID:      moderatorID,
+			PaginationParams: &PaginationParams{First: 100, After: cursor},
+		})
+		if err != nil {
+			return terms, err
+		}
+		terms = append(terms, resp.Data...)
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+			return terms, nil
+		}
+		cursor = resp.Pagination.Cursor
+	}
+}
+
+// ExportModerationProfile exports a channel's permanent blocked terms and
+// AutoMod settings. Temporary terms (those with an expiry) are left out.
+// Requires: moderator:read:blocked_terms and moderator:read:automod_settings scopes.
+func (c *Client) ExportModerationProfile(ctx context.Context, broadcasterID, moderatorID string) (*ModerationProfile, error) {
+	terms, err := c.GetAllBlockedTerms(ctx, broadcasterID, moderatorID)
+	if err != nil {
+		return nil, fmt.Errorf("getting blocked terms: %w", err)
+	}
+	settings, err := c.GetAutoModSettings(ctx, broadcasterID, moderatorID)
+	if err != nil {
+		return nil, fmt.Errorf("getting AutoMod settings: %w", err)
+	}
+
+	p := &ModerationProfile{BlockedTerms: []string{}}
+	for _, t := range terms {
+		if !t.ExpiresAt.Valid {
+			p.BlockedTerms = append(p.BlockedTerms, t.Text)
+		}
+	}
+	slices.Sort(p.BlockedTerms)
+	if settings != nil {
+		p.AutoMod = AutoModProfileFromSettings(settings)
+	}
+	return p, nil
+}
+
+// AutoModChange is an AutoMod setting that differs from the profile.
+type AutoModChange struct {
+	Setting string
+	Old     string // "null" for an unset overall level
+	New     string
+}
+
+// ModerationDiff lists how a channel differs from a moderation profile, and
+// the outcome of applying the profile.
+type ModerationDiff struct {
+	BroadcasterID string
+	AddTerms      []string
+	RemoveTerms   []BlockedTerm // Only with RemoveExtraTerms
+	AutoMod       []AutoModChange
+	autoModUpdate *UpdateAutoModSettingsParams
+
+	Applied bool  // Every change was made
+	Err     error // Error reading or updating the channel
+}
+
+// HasChanges reports whether the channel differs from the profile.
+func (d *ModerationDiff) HasChanges() bool {
+	return len(d.AddTerms) > 0 || len(d.RemoveTerms) > 0 || len(d.AutoMod) > 0
+}
+
+// String formats the diff for display.
+func (d *ModerationDiff) String() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "%s:", d.BroadcasterID)
+	if d.Err != nil {
+		fmt.Fprintf(&b, " error: %v\n", d.Err)
+		return b.String()
+	}
+	if !d.HasChanges() {
+		b.WriteString(" in sync\n")
+		return b.String()
+	}
+	b.WriteString("\n")
+	for _, t := range d.AddTerms {
+		fmt.Fprintf(&b, "  + term %q\n", t)
+	}
+	for _, t := range d.RemoveTerms {
+		fmt.Fprintf(&b, "  - term %q\n", t.Text)
+	}
+	for _, c := range d.AutoMod {
+		fmt.Fprintf(&b, "  ~ automod %s: %s -> %s\n", c.Setting, c.Old, c.New)
+	}
+	return b.String()
+}
+
+// ModerationSyncOptions configures SyncModerationProfile.
+type ModerationSyncOptions struct {
+	// DryRun reports the differences without changing anything.
+	DryRun bool
+	// RemoveExtraTerms removes permanent blocked terms that aren't in the
+	// profile. Temporary terms are never removed.
+	RemoveExtraTerms bool
+	// Bulk configures the concurrency of blocked term additions and removals.
+	Bulk *BulkOptions
+}
+
+// SyncModerationProfile applies a moderation profile to each channel, acting
+// as moderatorID, who must be a moderator in all of them. Blocked terms are
+// compared case-insensitively. The returned diffs, one per channel in order,
+// report what differed and any per-channel error; the error joins them.
+// Requires: moderator:manage:blocked_terms and moderator:manage:automod_settings scopes.
+func (c *Client) SyncModerationProfile(ctx context.Context, profile *ModerationProfile, moderatorID string, broadcasterIDs []string, opts *ModerationSyncOptions) ([]*ModerationDiff, error) {
+	if opts == nil {
+		opts = &ModerationSyncOptions{}
+	}
+	diffs := make([]*ModerationDiff, 0, len(broadcasterIDs))
+	var errs []error
+	for _, broadcasterID := range broadcasterIDs {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		diff := c.DiffModerationProfile(ctx, profile, broadcasterID, moderatorID, opts.RemoveExtraTerms)
+		if diff.Err == nil && !opts.DryRun {
+			diff.Err = c.applyModerationDiff(ctx, diff, moderatorID, opts.Bulk)
+			diff.Applied = diff.Err == nil
+		}
+		if diff.Err != nil {
+			errs = append(errs, fmt.Errorf("channel %s: %w", broadcasterID, diff.Err))
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs, errors.Join(errs...)
+}
+
+// DiffModerationProfile compares a channel's blocked terms and AutoMod
+// settings against a profile. Errors are reported in the diff's Err.
+func (c *Client) DiffModerationProfile(ctx context.Context, profile *ModerationProfile, broadcasterID, moderatorID string, removeExtra bool) *ModerationDiff {
+	diff := &ModerationDiff{BroadcasterID: broadcasterID}
+
+	terms, err := c.GetAllBlockedTerms(ctx, broadcasterID, moderatorID)
+	if err != nil {
+		diff.Err = fmt.Errorf("getting blocked terms: %w", err)
+		return diff
+	}
+	existing := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		existing[normalizeBlockedTerm(t.Text)] = true
+	}
+	wanted := make(map[string]bool, len(profile.BlockedTerms))
+	for _, t := range profile.BlockedTerms {
+		key := normalizeBlockedTerm(t)
+		if key == "" || wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		if !existing[key] {
+			diff.AddTerms = append(diff.AddTerms, strings.TrimSpace(t))
+		}
+	}
+	if removeExtra {
+		for _, t := range terms {
+			if !t.ExpiresAt.Valid && !wanted[normalizeBlockedTerm(t.Text)] {
+				diff.RemoveTerms = append(diff.RemoveTerms, t)
+			}
+		}
+	}
+
+	if profile.AutoMod != nil {
+		settings, err := c.GetAutoModSettings(ctx, broadcasterID, moderatorID)
+		if err != nil {
+			diff.Err = fmt.Errorf("getting AutoMod settings: %w", err)
+			return diff
+		}
+		if settings != nil {
+			diff.AutoMod, diff.autoModUpdate = diffAutoMod(profile.AutoMod, settings)
+		}
+	}
+	return diff
+}
+
+// diffAutoMod compares AutoMod settings against a profile and returns the
+// changes and the update that applies them, or nil if nothing changed.
+func diffAutoMod(p *AutoModProfile, s *AutoModSettings) ([]AutoModChange, *UpdateAutoModSettingsParams) {
+	var changes []AutoModChange
+	update := &UpdateAutoModSettingsParams{}
+
+	if p.OverallLevel != nil {
+		if s.OverallLevel == nil || *s.OverallLevel != *p.OverallLevel {
+			old := "null"
+			if s.OverallLevel != nil {
+				old = strconv.Itoa(*s.OverallLevel)
+			}
+			changes = append(changes, AutoModChange{Setting: "overall_level", Old: old, New: strconv.Itoa(*p.OverallLevel)})
+		}
+		level := *p.OverallLevel
+		update.OverallLevel = &level
+	} else {
+		// The update replaces all settings, so unchanged ones are sent too
+		for _, l := range p.levels(s) {
+			v := l.current
+			if l.profile != nil {
+				v = *l.profile
+				if v != l.current || s.OverallLevel != nil {
+					changes = append(changes, AutoModChange{Setting: l.name, Old: strconv.Itoa(l.current), New: strconv.Itoa(v)})
+				}
+			}
+			l.set(update, &v)
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return changes, update
+}
+
+// applyModerationDiff makes a channel match the profile.
+func (c *Client) applyModerationDiff(ctx context.Context, diff *ModerationDiff, moderatorID string, bulk *BulkOptions) error {
+	var errs []error
+	if len(diff.AddTerms) > 0 {
+		if err := c.AddBlockedTerms(ctx, diff.BroadcasterID, moderatorID, diff.AddTerms, bulk).Err(); err != nil {
+			errs = append(errs, fmt.Errorf("adding blocked terms: %w", err))
+		}
+	}
+	if len(diff.RemoveTerms) > 0 {
+		ids := make([]string, len(diff.RemoveTerms))
+		for i, t := range diff.RemoveTerms {
+			ids[i] = t.ID
+		}
+		// Terms already removed are skipped
+		report := runBulk(ctx, c, ids, bulk, isNotFoundError, func(ctx context.Context, i int) (struct{}, error) {
+			return struct{}{}, c.RemoveBlockedTerm(ctx, diff.BroadcasterID, moderatorID, ids[i])
+		})
+		if err := report.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("removing blocked terms: %w", err))
+		}
+	}
+	if diff.autoModUpdate != nil {
+		update := *diff.autoModUpdate
+		update.BroadcasterID = diff.BroadcasterID
+		update.ModeratorID = moderatorID
+		if _, err := c.UpdateAutoModSettings(ctx, &update); err != nil {
+			errs = append(errs, fmt.Errorf("updating AutoMod settings: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeBlockedTerm returns the form used to compare blocked terms.
+func normalizeBlockedTerm(term string) string {
+	return strings.ToLower(strings.TrimSpace(term))
+}
@@ -0,0 +1,192 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// moderationProfileHandler serves blocked terms (two pages) and AutoMod
+// settings per channel, and records changes.
+func moderationProfileHandler(t *testing.T, terms map[string][]BlockedTerm, automod map[string]AutoModSettings, calls *[]string) http.HandlerFunc {
+	var mu sync.Mutex
+	record := func(s string) {
+		mu.Lock()
+		*calls = append(*calls, s)
+		mu.Unlock()
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		b := q.Get("broadcaster_id")
+		switch {
+		case r.URL.Path == "/moderation/blocked_terms" && r.Method == http.MethodGet:
+			all := terms[b]
+			resp := Response[BlockedTerm]{}
+			if q.Get("after") == "" && len(all) > 1 {
+				resp.Data = all[:1]
+				resp.Pagination = &Pagination{Cursor: "next"}
+			} else if q.Get("after") != "" {
+				resp.Data = all[1:]
+			} else {
+				resp.Data = all
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/moderation/blocked_terms" && r.Method == http.MethodPost:
+			var body AddBlockedTermParams
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			record(b + " add " + body.Text)
+			_ = json.NewEncoder(w).Encode(Response[BlockedTerm]{Data: []BlockedTerm{{Text: body.Text}}})
+		case r.URL.Path == "/moderation/blocked_terms" && r.Method == http.MethodDelete:
+			record(b + " remove " + q.Get("id"))
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/moderation/automod/settings" && r.Method == http.MethodGet:
+			s := automod[b]
+			s.BroadcasterID = b
+			_ = json.NewEncoder(w).Encode(Response[AutoModSettings]{Data: []AutoModSettings{s}})
+		case r.URL.Path == "/moderation/automod/settings" && r.Method == http.MethodPut:
+			var body bytes.Buffer
+			_, _ = body.ReadFrom(r.Body)
+			record(b + " automod " + strings.TrimSpace(body.String()))
+			_ = json.NewEncoder(w).Encode(Response[AutoModSettings]{Data: []AutoModSettings{automod[b]}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestExportModerationProfile(t *testing.T) {
+	terms := map[string][]BlockedTerm{"1": {
+		{ID: "t1", Text: "spam"},
+		{ID: "t2", Text: "temp", ExpiresAt: NewNullableTime(time.Now().Add(time.Hour))},
+		{ID: "t3", Text: "badword"},
+	}}
+	level := 2
+	automod := map[string]AutoModSettings{"1": {OverallLevel: &level}}
+	var calls []string
+	client, server := newTestClient(moderationProfileHandler(t, terms, automod, &calls))
+	defer server.Close()
+
+	p, err := client.ExportModerationProfile(context.Background(), "1", "mod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(p.BlockedTerms, []string{"badword", "spam"}) || p.AutoMod == nil || *p.AutoMod.OverallLevel != 2 || p.AutoMod.Swearing != nil {
+		t.Errorf("profile = %+v, automod = %+v", p, p.AutoMod)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteModerationProfile(&buf, p); err != nil {
+		t.Fatal(err)
+	}
+	round, err := ReadModerationProfile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(round.BlockedTerms, p.BlockedTerms) || *round.AutoMod.OverallLevel != 2 {
+		t.Errorf("round trip = %+v", round)
+	}
+	if _, err := ReadModerationProfile(strings.NewReader(`{"blocked_term":["x"]}`)); err == nil {
+		t.Error("unknown field accepted")
+	}
+}
+
+func TestSyncModerationProfile(t *testing.T) {
+	terms := map[string][]BlockedTerm{
+		"1": {{ID: "a1", Text: "SPAM"}, {ID: "a2", Text: "extra"}, {ID: "a3", Text: "temp", ExpiresAt: NewNullableTime(time.Now().Add(time.Hour))}},
+		"2": {{ID: "b1", Text: "spam"}, {ID: "b2", Text: "scam"}},
+	}
+	automod := map[string]AutoModSettings{
+		"1": {Swearing: 1, Bullying: 2},
+		"2": {Swearing: 3, Bullying: 2},
+	}
+	swearing := 3
+	profile := &ModerationProfile{
+		BlockedTerms: []string{"spam", "scam", " Scam "},
+		AutoMod:      &AutoModProfile{Swearing: &swearing},
+	}
+	ctx := context.Background()
+
+	var calls []string
+	client, server := newTestClient(moderationProfileHandler(t, terms, automod, &calls))
+	defer server.Close()
+
+	diffs, err := client.SyncModerationProfile(ctx, profile, "mod", []string{"1", "2"}, &ModerationSyncOptions{DryRun: true, RemoveExtraTerms: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("dry run made changes: %v", calls)
+	}
+	want := `1:
+  + term "scam"
+  - term "extra"
+  ~ automod swearing: 1 -> 3
+`
+	if got := diffs[0].String(); got != want {
+		t.Errorf("diff 1 =\n%s\nwant\n%s", got, want)
+	}
+	if diffs[1].HasChanges() || diffs[1].String() != "2: in sync\n" {
+		t.Errorf("diff 2 = %s", diffs[1])
+	}
+
+	diffs, err = client.SyncModerationProfile(ctx, profile, "mod", []string{"1", "2"}, &ModerationSyncOptions{RemoveExtraTerms: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diffs[0].Applied {
+		t.Error("diff not applied")
+	}
+	wantCalls := []string{
+		"1 add scam",
+		"1 remove a2",
+		`1 automod {"disability":0,"aggression":0,"sexuality_sex_or_gender":0,"misogyny":0,"bullying":2,"swearing":3,"race_ethnicity_or_religion":0,"sex_based_terms":0}`,
+	}
+	if !slices.Equal(calls, wantCalls) {
+		t.Errorf("calls = %q", calls)
+	}
+}
+
+func TestSyncModerationProfile_FailedRemoval(t *testing.T) {
+	var mu sync.Mutex
+	var removed []string
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(Response[BlockedTerm]{Data: []BlockedTerm{{ID: "t1", Text: "gone"}, {ID: "t2", Text: "stuck"}, {ID: "t3", Text: "old"}}})
+		case http.MethodDelete:
+			id := r.URL.Query().Get("id")
+			mu.Lock()
+			removed = append(removed, id)
+			mu.Unlock()
+			switch id {
+			case "t1":
+				w.WriteHeader(http.StatusNotFound)
+			case "t2":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				w.WriteHeader(http.StatusNoContent)
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	diffs, err := client.SyncModerationProfile(context.Background(), &ModerationProfile{}, "mod", []string{"1"}, &ModerationSyncOptions{RemoveExtraTerms: true})
+	if err == nil || !strings.Contains(err.Error(), "removing blocked terms") {
+		t.Fatalf("err = %v", err)
+	}
+	if diffs[0].Applied || diffs[0].Err == nil {
+		t.Errorf("diff = %+v", diffs[0])
+	}
+	slices.Sort(removed)
+	if !slices.Equal(removed, []string{"t1", "t2", "t3"}) {
+		t.Errorf("removed = %v", removed)
+	}
+}
@@ -0,0 +1,478 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// RuleActionType is the moderation action taken when a chat rule matches.
+type RuleActionType string
+
+// Rule action types
+const (
+	RuleActionDelete  RuleActionType = "delete"  // Delete the message
+	RuleActionTimeout RuleActionType = "timeout" // Time the user out for Duration seconds
+	RuleActionBan     RuleActionType = "ban"     // Ban the user permanently
+	RuleActionWarn    RuleActionType = "warn"    // Warn the user
+	RuleActionNone    RuleActionType = "none"    // Only report the match
+)
+
+// RuleAction is the action taken when a chat rule matches.
+type RuleAction struct {
+	Type     RuleActionType
+	Duration int    // Timeout length in seconds (RuleActionTimeout only)
+	Reason   string // Ban, timeout or warning reason shown to the user
+}
+
+// RuleExemption selects chatters a rule does not apply to. The broadcaster is
+// always exempt.
+type RuleExemption int
+
+// Rule exemptions, combined with |
+const (
+	ExemptModerators RuleExemption = 1 << iota
+	ExemptVIPs
+	ExemptSubscribers
+
+	// ExemptPrivileged exempts moderators and VIPs.
+	ExemptPrivileged = ExemptModerators | ExemptVIPs
+)
+
+// RuleMatcher checks a chat message against a rule. Match returns a short
+// description of what matched, and whether the message matched.
+type RuleMatcher interface {
+	Match(msg *ChatMessage) (string, bool)
+}
+
+// RuleMatcherFunc adapts a function to RuleMatcher.
+type RuleMatcherFunc func(msg *ChatMessage) (string, bool)
+
+// Match calls f(msg).
+func (f RuleMatcherFunc) Match(msg *ChatMessage) (string, bool) {
+	return f(msg)
+}
+
+// ChatRule is a named moderation rule: a matcher, the action to take and the
+// chatters it does not apply to.
+type ChatRule struct {
+	Name    string
+	Matcher RuleMatcher
+	Action  RuleAction
+	Exempt  RuleExemption
+}
+
+// exempts reports whether the rule does not apply to the message's sender.
+func (r *ChatRule) exempts(msg *ChatMessage) bool {
+	switch {
+	case msg.IsBroadcaster:
+		return true
+	case r.Exempt&ExemptModerators != 0 && msg.IsMod:
+		return true
+	case r.Exempt&ExemptVIPs != 0 && msg.IsVIP:
+		return true
+	case r.Exempt&ExemptSubscribers != 0 && msg.IsSubscriber:
+		return true
+	}
+	return false
+}
+
+// RuleResult describes a rule that matched a message.
+type RuleResult struct {
+	Rule    string       // Name of the matching rule
+	Action  RuleAction   // Action for the rule
+	Detail  string       // What matched
+	Message *ChatMessage // The message
+	DryRun  bool         // The action was logged but not taken
+	Err     error        // Error taking the action
+}
+
+// RuleEngine evaluates chat messages against an ordered list of rules and
+// takes the action of the first rule that matches. Rules should be added from
+// most to least severe.
+type RuleEngine struct {
+	client      *Client
+	moderatorID string
+	dryRun      bool
+	logger      func(format string, args ...any)
+	onAction    func(*RuleResult)
+
+	mu    sync.RWMutex
+	rules []*ChatRule
+}
+
+// RuleEngineOption configures a RuleEngine.
+type RuleEngineOption func(*RuleEngine)
+
+// WithRuleEngineDryRun makes the engine log matches instead of taking actions.
+func WithRuleEngineDryRun(dryRun bool) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.dryRun = dryRun
+	}
+}
+
+// WithRuleEngineLogger sets the logger used to report matches.
+func WithRuleEngineLogger(logger func(format string, args ...any)) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.logger = logger
+	}
+}
+
+// WithRuleEngineActionHandler sets a handler called for every match, after
+// its action was taken (or skipped in dry-run mode).
+func WithRuleEngineActionHandler(fn func(*RuleResult)) RuleEngineOption {
+	return func(e *RuleEngine) {
+		e.onAction = fn
+	}
+}
+
+// NewRuleEngine creates a rule engine that moderates as moderatorID. The
+// moderator's token needs the moderator:manage:chat_messages,
+// moderator:manage:banned_users and moderator:manage:warnings scopes for the
+// actions used.
+func NewRuleEngine(client *Client, moderatorID string, opts ...RuleEngineOption) *RuleEngine {
+	e := &RuleEngine{
+		client:      client,
+		moderatorID: moderatorID,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// AddRule appends a rule. Rules are evaluated in the order they were added.
+func (e *RuleEngine) AddRule(rule ChatRule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append(e.rules, &rule)
+}
+
+// RemoveRule removes the rules with the given name.
+func (e *RuleEngine) RemoveRule(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Copy rather than filter in place: Evaluate may be iterating the old slice
+	var rules []*ChatRule
+	for _, r := range e.rules {
+		if r.Name != name {
+			rules = append(rules, r)
+		}
+	}
+	e.rules = rules
+}
+
+// Evaluate returns the first rule that matches the message, or nil. It takes
+// no action.
+func (e *RuleEngine) Evaluate(msg *ChatMessage) *RuleResult {
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	for _, r := range rules {
+		if r.exempts(msg) {
+			continue
+		}
+		if detail, ok := r.Matcher.Match(msg); ok {
+			return &RuleResult{
+				Rule:    r.Name,
+				Action:  r.Action,
+				Detail:  detail,
+				Message: msg,
+				DryRun:  e.dryRun,
+			}
+		}
+	}
+	return nil
+}
+
+// Handle evaluates the message and takes the action of the first matching
+// rule. It returns nil if no rule matched. In dry-run mode the action is only
+// logged. The returned error is also stored in RuleResult.Err.
+func (e *RuleEngine) Handle(ctx context.Context, msg *ChatMessage) (*RuleResult, error) {
+	result := e.Evaluate(msg)
+	if result == nil {
+		return nil, nil
+	}
+
+	if e.dryRun {
+		e.logf("[dry run] rule %q matched %s (%s): would %s", result.Rule, msg.User, result.Detail, result.Action.Type)
+	} else {
+		result.Err = e.apply(ctx, msg, result.Action)
+		if result.Err != nil {
+			e.logf("rule %q matched %s (%s): %s failed: %v", result.Rule, msg.User, result.Detail, result.Action.Type, result.Err)
+		} else {
+			e.logf("rule %q matched %s (%s): %s", result.Rule, msg.User, result.Detail, result.Action.Type)
+		}
+	}
+
+	if e.onAction != nil {
+		e.onAction(result)
+	}
+	return result, result.Err
+}
+
+// apply takes a rule action against a message's sender.
+func (e *RuleEngine) apply(ctx context.Context, msg *ChatMessage, action RuleAction) error {
+	switch action.Type {
+	case RuleActionDelete:
+		if msg.ID == "" {
+			return fmt.Errorf("message has no ID")
+		}
+		return e.client.DeleteChatMessages(ctx, &DeleteChatMessagesParams{
+			BroadcasterID: msg.RoomID,
+			ModeratorID:   e.moderatorID,
+			MessageID:     msg.ID,
+		})
+	case RuleActionTimeout, RuleActionBan:
+		data := BanUserData{UserID: msg.UserID, Reason: action.Reason}
+		if action.Type == RuleActionTimeout {
+			data.Duration = max(action.Duration, 1)
+		}
+		_, err := e.client.BanUser(ctx, &BanUserParams{
+			BroadcasterID: msg.RoomID,
+			ModeratorID:   e.moderatorID,
+			Data:          data,
+		})
+		return err
+	case RuleActionWarn:
+		return e.client.WarnChatUser(ctx, &WarnChatUserParams{
+			BroadcasterID: msg.RoomID,
+			ModeratorID:   e.moderatorID,
+			Data:          WarnChatUserData{UserID: msg.UserID, Reason: action.Reason},
+		})
+	case RuleActionNone, "":
+		return nil
+	}
+	return fmt.Errorf("unknown rule action %q", action.Type)
+}
+
+func (e *RuleEngine) logf(format string, args ...any) {
+	if e.logger != nil {
+		e.logger(format, args...)
+	}
+}
+
+// Built-in matchers
+
+// MatchRegexp matches messages matching any of the patterns.
+func MatchRegexp(patterns ...*regexp.Regexp) RuleMatcher {
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		for _, p := range patterns {
+			if m := p.FindString(msg.Message); m != "" {
+				return fmt.Sprintf("pattern %q matched %q", p.String(), m), true
+			}
+		}
+		return "", false
+	})
+}
+
+// MatchBlockedTerms matches messages containing any of the terms as whole
+// words, ignoring case.
+func MatchBlockedTerms(terms ...string) RuleMatcher {
+	patterns := make([]*regexp.Regexp, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			patterns = append(patterns, regexp.MustCompile(`(?i)(?:^|\W)`+regexp.QuoteMeta(term)+`(?:\W|$)`))
+		}
+	}
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		for i, p := range patterns {
+			if p.MatchString(msg.Message) {
+				return fmt.Sprintf("blocked term %q", strings.TrimSpace(terms[i])), true
+			}
+		}
+		return "", false
+	})
+}
+
+// MatchLinks matches messages containing links to domains not on the
+// allowlist. Allowed domains also allow their subdomains.
+func MatchLinks(allowedDomains ...string) RuleMatcher {
+	allowed := make([]string, len(allowedDomains))
+	for i, d := range allowedDomains {
+		allowed[i] = strings.ToLower(strings.TrimPrefix(d, "."))
+	}
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		for w := range strings.FieldsSeq(msg.Message) {
+			link, ok := parseChatLink(strings.TrimRight(w, ".,!?;:)'\""))
+			if !ok {
+				continue
+			}
+			u, err := url.Parse(link)
+			if err != nil {
+				continue
+			}
+			host := strings.ToLower(u.Hostname())
+			if !domainAllowed(host, allowed) {
+				return fmt.Sprintf("link to %s", host), true
+			}
+		}
+		return "", false
+	})
+}
+
+// domainAllowed reports whether host is one of the domains or a subdomain of one.
+func domainAllowed(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchCaps matches messages of at least minLetters letters where the ratio
+// of uppercase letters is at least ratio (0-1). Emotes are not counted.
+func MatchCaps(minLetters int, ratio float64) RuleMatcher {
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		letters, upper := 0, 0
+		for _, r := range textWithoutEmotes(msg) {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters < minLetters || letters == 0 {
+			return "", false
+		}
+		if got := float64(upper) / float64(letters); got >= ratio {
+			return fmt.Sprintf("%.0f%% caps", got*100), true
+		}
+		return "", false
+	})
+}
+
+// MatchEmoteSpam matches messages with more than maxEmotes emotes.
+func MatchEmoteSpam(maxEmotes int) RuleMatcher {
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		if n := len(msg.Emotes); n > maxEmotes {
+			return fmt.Sprintf("%d emotes", n), true
+		}
+		return "", false
+	})
+}
+
+// MatchSymbols matches messages of at least minLength characters (excluding
+// spaces) where the ratio of symbols and punctuation is at least ratio (0-1).
+func MatchSymbols(minLength int, ratio float64) RuleMatcher {
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		total, symbols := 0, 0
+		for _, r := range textWithoutEmotes(msg) {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			total++
+			if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) {
+				symbols++
+			}
+		}
+		if total < minLength || total == 0 {
+			return "", false
+		}
+		if got := float64(symbols) / float64(total); got >= ratio {
+			return fmt.Sprintf("%.0f%% symbols", got*100), true
+		}
+		return "", false
+	})
+}
+
+// MatchZalgo matches messages where any character carries more than
+// maxMarks combining marks (the stacked diacritics of "zalgo" text).
+func MatchZalgo(maxMarks int) RuleMatcher {
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		run, most := 0, 0
+		for _, r := range msg.Message {
+			if unicode.In(r, unicode.Mn, unicode.Me) {
+				run++
+				most = max(most, run)
+			} else {
+				run = 0
+			}
+		}
+		if most > maxMarks {
+			return fmt.Sprintf("%d stacked combining marks", most), true
+		}
+		return "", false
+	})
+}
+
+// MatchRepeated matches a chatter sending the same message (ignoring case and
+// spacing) count or more times within the window. The matcher keeps per-user
+// history, so use one instance per engine.
+func MatchRepeated(count int, window time.Duration) RuleMatcher {
+	type sent struct {
+		text string
+		at   time.Time
+	}
+	var mu sync.Mutex
+	history := make(map[string][]sent) // channel/user -> recent messages
+	lastPrune := time.Now()
+
+	return RuleMatcherFunc(func(msg *ChatMessage) (string, bool) {
+		now := time.Now()
+		key := msg.RoomID + "/" + msg.UserID
+		text := strings.ToLower(strings.Join(strings.Fields(msg.Message), " "))
+		cutoff := now.Add(-window)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Drop idle chatters now and then so the history doesn't grow unbounded
+		if now.Sub(lastPrune) > window {
+			for k, msgs := range history {
+				if len(msgs) == 0 || msgs[len(msgs)-1].at.Before(cutoff) {
+					delete(history, k)
+				}
+			}
+			lastPrune = now
+		}
+
+		var kept []sent
+		same := 1
+		for _, s := range history[key] {
+			if s.at.After(cutoff) {
+				kept = append(kept, s)
+				if s.text == text {
+					same++
+				}
+			}
+		}
+		history[key] = append(kept, sent{text: text, at: now})
+
+		if same >= count {
+			return fmt.Sprintf("repeated %d times", same), true
+		}
+		return "", false
+	})
+}
+
+// textWithoutEmotes returns the message text with emote names removed.
+func textWithoutEmotes(msg *ChatMessage) string {
+	if len(msg.Emotes) == 0 {
+		return msg.Message
+	}
+	runes := []rune(msg.Message)
+	skip := make([]bool, len(runes))
+	for _, e := range msg.Emotes {
+		for i := max(e.Start, 0); i <= e.End && i < len(runes); i++ {
+			skip[i] = true
+		}
+	}
+	var b strings.Builder
+	b.Grow(len(msg.Message))
+	for i, r := range runes {
+		if !skip[i] {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
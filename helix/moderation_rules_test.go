@@ -0,0 +1,184 @@
+package helix
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRuleMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher RuleMatcher
+		msg     *ChatMessage
+		want    bool
+	}{
+		{"regexp match", MatchRegexp(regexp.MustCompile(`(?i)free\s+followers`)), &ChatMessage{Message: "get FREE followers now"}, true},
+		{"regexp miss", MatchRegexp(regexp.MustCompile(`(?i)free\s+followers`)), &ChatMessage{Message: "hello"}, false},
+		{"blocked term word", MatchBlockedTerms("badword"), &ChatMessage{Message: "you BADWORD!"}, true},
+		{"blocked term inside word", MatchBlockedTerms("ass"), &ChatMessage{Message: "classic"}, false},
+		{"link not allowed", MatchLinks("twitch.tv"), &ChatMessage{Message: "see spam.example.com/x"}, true},
+		{"link allowed subdomain", MatchLinks("twitch.tv"), &ChatMessage{Message: "clip at https://clips.twitch.tv/abc."}, false},
+		{"no link", MatchLinks(), &ChatMessage{Message: "3.5 stars, see you at 9.30"}, false},
+		{"caps", MatchCaps(10, 0.7), &ChatMessage{Message: "THIS IS SO LOUD right"}, true},
+		{"caps too short", MatchCaps(10, 0.7), &ChatMessage{Message: "LOL"}, false},
+		{"caps ignores emotes", MatchCaps(5, 0.7), &ChatMessage{Message: "LUL LUL LUL hello there", Emotes: []IRCEmote{{Start: 0, End: 2}, {Start: 4, End: 6}, {Start: 8, End: 10}}}, false},
+		{"emote spam", MatchEmoteSpam(3), &ChatMessage{Emotes: make([]IRCEmote, 4)}, true},
+		{"emote ok", MatchEmoteSpam(3), &ChatMessage{Emotes: make([]IRCEmote, 3)}, false},
+		{"symbols", MatchSymbols(8, 0.5), &ChatMessage{Message: "!!!@@@### hi"}, true},
+		{"symbols normal", MatchSymbols(8, 0.5), &ChatMessage{Message: "hello there, friend!"}, false},
+		{"zalgo", MatchZalgo(3), &ChatMessage{Message: "h̀́̂̃̄i"}, true},
+		{"accents ok", MatchZalgo(3), &ChatMessage{Message: "café näive"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail, got := tt.matcher.Match(tt.msg)
+			if got != tt.want {
+				t.Errorf("Match() = %v (%q), want %v", got, detail, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRepeated(t *testing.T) {
+	m := MatchRepeated(3, time.Minute)
+	msg := func(user, text string) *ChatMessage {
+		return &ChatMessage{RoomID: "1", UserID: user, Message: text}
+	}
+
+	if _, ok := m.Match(msg("a", "buy now")); ok {
+		t.Fatal("first message matched")
+	}
+	if _, ok := m.Match(msg("b", "buy now")); ok {
+		t.Fatal("other user's message counted")
+	}
+	if _, ok := m.Match(msg("a", "BUY   now")); ok {
+		t.Fatal("second message matched")
+	}
+	if _, ok := m.Match(msg("a", "buy now")); !ok {
+		t.Fatal("third repeat not matched")
+	}
+}
+
+func TestRuleEngine_Handle(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, fmt.Sprintf("%s %s %s %s", r.Method, r.URL.Path, r.URL.RawQuery, body))
+		mu.Unlock()
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"data":[{}]}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	var results []*RuleResult
+	engine := NewRuleEngine(client, "99", WithRuleEngineActionHandler(func(r *RuleResult) {
+		results = append(results, r)
+	}))
+	engine.AddRule(ChatRule{
+		Name:    "slurs",
+		Matcher: MatchBlockedTerms("slur"),
+		Action:  RuleAction{Type: RuleActionTimeout, Duration: 600, Reason: "language"},
+	})
+	engine.AddRule(ChatRule{
+		Name:    "links",
+		Matcher: MatchLinks("twitch.tv"),
+		Action:  RuleAction{Type: RuleActionDelete},
+		Exempt:  ExemptPrivileged | ExemptSubscribers,
+	})
+	engine.AddRule(ChatRule{
+		Name:    "caps",
+		Matcher: MatchCaps(5, 0.8),
+		Action:  RuleAction{Type: RuleActionWarn, Reason: "no caps please"},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		msg      *ChatMessage
+		wantRule string
+		wantReq  string
+	}{
+		{&ChatMessage{ID: "m1", RoomID: "1", UserID: "u1", Message: "a slur here"}, "slurs", "POST /moderation/bans"},
+		{&ChatMessage{ID: "m2", RoomID: "1", UserID: "u2", Message: "go to evil.com"}, "links", "DELETE /moderation/chat"},
+		{&ChatMessage{ID: "m3", RoomID: "1", UserID: "u3", Message: "go to evil.com", IsSubscriber: true}, "", ""},
+		{&ChatMessage{ID: "m4", RoomID: "1", UserID: "u4", Message: "STOP SHOUTING"}, "caps", "POST /moderation/warnings"},
+		{&ChatMessage{ID: "m5", RoomID: "1", UserID: "u5", Message: "A SLUR", IsBroadcaster: true}, "", ""},
+	}
+	for _, tt := range tests {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		result, err := engine.Handle(ctx, tt.msg)
+		if err != nil {
+			t.Fatalf("Handle(%s) error = %v", tt.msg.ID, err)
+		}
+		if tt.wantRule == "" {
+			if result != nil || len(requests) != 0 {
+				t.Errorf("Handle(%s) = %+v, requests %v; want no match", tt.msg.ID, result, requests)
+			}
+			continue
+		}
+		if result == nil || result.Rule != tt.wantRule {
+			t.Fatalf("Handle(%s) = %+v, want rule %s", tt.msg.ID, result, tt.wantRule)
+		}
+		if len(requests) != 1 || !strings.HasPrefix(requests[0], tt.wantReq) {
+			t.Errorf("Handle(%s) requests = %v, want %s", tt.msg.ID, requests, tt.wantReq)
+		}
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("action handler called %d times, want 3", len(results))
+	}
+	if results[0].Action.Duration != 600 {
+		t.Errorf("timeout duration = %d", results[0].Action.Duration)
+	}
+
+	engine.RemoveRule("caps")
+	if r := engine.Evaluate(&ChatMessage{RoomID: "1", UserID: "u4", Message: "STOP SHOUTING"}); r != nil {
+		t.Errorf("Evaluate() after RemoveRule = %+v", r)
+	}
+}
+
+func TestRuleEngine_DryRun(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+	defer server.Close()
+
+	var logs []string
+	engine := NewRuleEngine(client, "99",
+		WithRuleEngineDryRun(true),
+		WithRuleEngineLogger(func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		}),
+	)
+	engine.AddRule(ChatRule{
+		Name:    "spam",
+		Matcher: MatchRegexp(regexp.MustCompile(`spam`)),
+		Action:  RuleAction{Type: RuleActionBan},
+	})
+
+	result, err := engine.Handle(context.Background(), &ChatMessage{RoomID: "1", UserID: "u", User: "spammer", Message: "spam spam"})
+	if err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+	if result == nil || !result.DryRun {
+		t.Fatalf("Handle() = %+v, want dry-run result", result)
+	}
+	if len(logs) != 1 || !strings.Contains(logs[0], "would ban") {
+		t.Errorf("logs = %v", logs)
+	}
+}
@@ -0,0 +1,502 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+// Unban request statuses
+const (
+	UnbanRequestStatusPending      = "pending"
+	UnbanRequestStatusApproved     = "approved"
+	UnbanRequestStatusDenied       = "denied"
+	UnbanRequestStatusAcknowledged = "acknowledged"
+	UnbanRequestStatusCanceled     = "canceled"
+)
+
+// defaultUnbanClaimTTL is how long a reviewer's claim on a request lasts.
+const defaultUnbanClaimTTL = 15 * time.Minute
+
+// maxUnbanResolutionText is the longest resolution text Twitch accepts.
+const maxUnbanResolutionText = 500
+
+// ErrUnbanRequestNotFound is returned for requests that are not pending in the queue.
+var ErrUnbanRequestNotFound = errors.New("unban request not found in queue")
+
+// UnbanRequestClaimedError is returned when another reviewer has claimed, or
+// is resolving, an unban request.
+type UnbanRequestClaimedError struct {
+	RequestID string
+	ClaimedBy string
+}
+
+func (e *UnbanRequestClaimedError) Error() string {
+	return fmt.Sprintf("unban request %s is claimed by %s", e.RequestID, e.ClaimedBy)
+}
+
+// PendingUnbanRequest is a pending unban request with the ban it appeals and
+// its review claim.
+type PendingUnbanRequest struct {
+	UnbanRequest
+	Ban       *BannedUser // Original ban; nil if it could not be found
+	ClaimedBy string      // Reviewer holding the request ("" if unclaimed)
+	ClaimedAt time.Time
+}
+
+// UnbanResolutionData is the data passed to resolution message templates.
+type UnbanResolutionData struct {
+	*PendingUnbanRequest
+	Reviewer  string
+	Approved  bool
+	BanReason string // Ban.Reason, or "" if the ban is unknown
+}
+
+// unbanQueueEntry is a queued request and whether it is being resolved.
+type unbanQueueEntry struct {
+	req       PendingUnbanRequest
+	resolving bool
+}
+
+// UnbanQueue keeps the pending unban requests of a channel for review. It is
+// seeded with Load and kept current by channel.unban_request.create and
+// channel.unban_request.resolve events. Each request is enriched with the
+// original ban's reason and moderator.
+//
+// Reviewers claim a request before resolving it, so two moderators sharing
+// the queue cannot resolve the same request at once. All API calls are made
+// as the queue's moderator; reviewers are only names for claims and templates.
+type UnbanQueue struct {
+	client        *Client
+	broadcasterID string
+	moderatorID   string
+	claimTTL      time.Duration
+	onAdd         func(PendingUnbanRequest)
+	onRemove      func(id, status string)
+	onError       func(error)
+
+	mu        sync.Mutex
+	entries   map[string]*unbanQueueEntry
+	templates map[string]*template.Template
+	loads     int                 // Load calls in progress
+	touched   map[string]struct{} // IDs added or removed during a Load
+}
+
+// UnbanQueueOption configures an UnbanQueue.
+type UnbanQueueOption func(*UnbanQueue)
+
+// WithUnbanClaimTTL sets how long a claim lasts before another reviewer may
+// take the request (default 15m).
+func WithUnbanClaimTTL(d time.Duration) UnbanQueueOption {
+	return func(q *UnbanQueue) {
+		q.claimTTL = d
+	}
+}
+
+// WithUnbanQueueAddHandler sets the handler called when a request enters the queue.
+func WithUnbanQueueAddHandler(fn func(PendingUnbanRequest)) UnbanQueueOption {
+	return func(q *UnbanQueue) {
+		q.onAdd = fn
+	}
+}
+
+// WithUnbanQueueRemoveHandler sets the handler called when a request leaves
+// the queue, with its final status.
+func WithUnbanQueueRemoveHandler(fn func(id, status string)) UnbanQueueOption {
+	return func(q *UnbanQueue) {
+		q.onRemove = fn
+	}
+}
+
+// WithUnbanQueueErrorHandler sets the handler for errors from events received
+// through Subscribe.
+func WithUnbanQueueErrorHandler(fn func(error)) UnbanQueueOption {
+	return func(q *UnbanQueue) {
+		q.onError = fn
+	}
+}
+
+// NewUnbanQueue creates an empty unban request queue for a channel. The
+// moderator's token needs the moderator:manage:unban_requests and
+// moderator:read:banned_users (or moderator:manage:banned_users) scopes.
+func NewUnbanQueue(client *Client, broadcasterID, moderatorID string, opts ...UnbanQueueOption) *UnbanQueue {
+	q := &UnbanQueue{
+		client:        client,
+		broadcasterID: broadcasterID,
+		moderatorID:   moderatorID,
+		claimTTL:      defaultUnbanClaimTTL,
+		entries:       make(map[string]*unbanQueueEntry),
+		templates:     make(map[string]*template.Template),
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	return q
+}
+
+// Load fetches every pending unban request and replaces the queue with them.
+// Claims on requests that are still pending are kept, and requests created or
+// resolved by events while Load runs keep their state from the event. If the
+// bans cannot be looked up, the queue is still loaded and the error is
+// returned.
+func (q *UnbanQueue) Load(ctx context.Context) error {
+	q.mu.Lock()
+	q.loads++
+	if q.touched == nil {
+		q.touched = make(map[string]struct{})
+	}
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		if q.loads--; q.loads == 0 {
+			q.touched = nil
+		}
+		q.mu.Unlock()
+	}()
+
+	var requests []UnbanRequest
+	cursor := ""
+	for {
+		resp, err := q.client.GetUnbanRequests(ctx, &GetUnbanRequestsParams{
+			BroadcasterID:    q.broadcasterID,
+			ModeratorID:      q.moderatorID,
+			Status:           UnbanRequestStatusPending,
+			PaginationParams: &PaginationParams{First: 100, After: cursor},
+		})
+		if err != nil {
+			return fmt.Errorf("getting unban requests: %w", err)
+		}
+		requests = append(requests, resp.Data...)
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+			break
+		}
+		cursor = resp.Pagination.Cursor
+	}
+
+	userIDs := make([]string, len(requests))
+	for i, r := range requests {
+		userIDs[i] = r.UserID
+	}
+	// A failed ban lookup still loads the queue, without the bans
+	bans, banErr := q.fetchBans(ctx, userIDs)
+
+	q.mu.Lock()
+	old := q.entries
+	q.entries = make(map[string]*unbanQueueEntry, len(requests))
+	// Events handled since Load started are newer than the fetched pages
+	for id := range q.touched {
+		if e, ok := old[id]; ok {
+			q.entries[id] = e
+		}
+	}
+	var added []PendingUnbanRequest
+	for _, r := range requests {
+		if _, ok := q.touched[r.ID]; ok {
+			continue
+		}
+		e := &unbanQueueEntry{req: PendingUnbanRequest{UnbanRequest: r, Ban: bans[r.UserID]}}
+		if prev, ok := old[r.ID]; ok {
+			e.req.ClaimedBy, e.req.ClaimedAt = prev.req.ClaimedBy, prev.req.ClaimedAt
+			e.resolving = prev.resolving
+		} else {
+			added = append(added, e.req)
+		}
+		q.entries[r.ID] = e
+	}
+	q.mu.Unlock()
+
+	if q.onAdd != nil {
+		for _, r := range added {
+			q.onAdd(r)
+		}
+	}
+	return banErr
+}
+
+// Subscribe subscribes to channel.unban_request.create and
+// channel.unban_request.resolve on a connected EventSubWebSocket. Events that
+// fail to process are reported to the error handler and dropped; call Load to
+// resynchronize.
+func (q *UnbanQueue) Subscribe(ctx context.Context, ws *EventSubWebSocket) error {
+	for _, eventType := range []string{EventSubTypeChannelUnbanRequestCreate, EventSubTypeChannelUnbanRequestResolve} {
+		handler := func(data json.RawMessage) {
+			if err := q.HandleEvent(context.Background(), eventType, data); err != nil && q.onError != nil {
+				q.onError(err)
+			}
+		}
+		if err := ws.Subscribe(ctx, eventType, GetEventSubVersion(eventType),
+			BroadcasterModeratorCondition(q.broadcasterID, q.moderatorID), handler); err != nil {
+			return fmt.Errorf("subscribing to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// HandleEvent processes a raw channel.unban_request.create or
+// channel.unban_request.resolve event. Other event types are ignored.
+func (q *UnbanQueue) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	switch eventType {
+	case EventSubTypeChannelUnbanRequestCreate:
+		event, err := ParseWSEvent[ChannelUnbanRequestCreateEvent](data)
+		if err != nil {
+			return err
+		}
+		return q.HandleCreate(ctx, event)
+	case EventSubTypeChannelUnbanRequestResolve:
+		event, err := ParseWSEvent[ChannelUnbanRequestResolveEvent](data)
+		if err != nil {
+			return err
+		}
+		q.HandleResolve(event)
+	}
+	return nil
+}
+
+// HandleCreate adds a new unban request to the queue and looks up its ban.
+// The request is queued even if the ban lookup fails; the error is returned.
+func (q *UnbanQueue) HandleCreate(ctx context.Context, event *ChannelUnbanRequestCreateEvent) error {
+	if event.BroadcasterUserID != q.broadcasterID {
+		return nil
+	}
+	req := PendingUnbanRequest{UnbanRequest: UnbanRequest{
+		ID:               event.ID,
+		BroadcasterID:    event.BroadcasterUserID,
+		BroadcasterLogin: event.BroadcasterUserLogin,
+		BroadcasterName:  event.BroadcasterUserName,
+		UserID:           event.UserID,
+		UserLogin:        event.UserLogin,
+		UserName:         event.UserName,
+		Text:             event.Text,
+		Status:           UnbanRequestStatusPending,
+		CreatedAt:        event.CreatedAt.Format(time.RFC3339),
+	}}
+
+	bans, err := q.fetchBans(ctx, []string{event.UserID})
+	req.Ban = bans[event.UserID]
+
+	q.mu.Lock()
+	_, exists := q.entries[event.ID]
+	if !exists {
+		q.entries[event.ID] = &unbanQueueEntry{req: req}
+		q.touchLocked(event.ID)
+	}
+	q.mu.Unlock()
+
+	if !exists && q.onAdd != nil {
+		q.onAdd(req)
+	}
+	return err
+}
+
+// HandleResolve removes a resolved (or canceled) request from the queue.
+func (q *UnbanQueue) HandleResolve(event *ChannelUnbanRequestResolveEvent) {
+	q.remove(event.ID, event.Status)
+}
+
+// Pending returns the queued requests, oldest first.
+func (q *UnbanQueue) Pending() []PendingUnbanRequest {
+	q.mu.Lock()
+	out := make([]PendingUnbanRequest, 0, len(q.entries))
+	for _, e := range q.entries {
+		out = append(out, e.req)
+	}
+	q.mu.Unlock()
+
+	slices.SortFunc(out, func(a, b PendingUnbanRequest) int {
+		if c := strings.Compare(a.CreatedAt, b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out
+}
+
+// Get returns a queued request.
+func (q *UnbanQueue) Get(id string) (PendingUnbanRequest, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, ok := q.entries[id]
+	if !ok {
+		return PendingUnbanRequest{}, false
+	}
+	return e.req, true
+}
+
+// Claim reserves a request for a reviewer. It fails with an
+// *UnbanRequestClaimedError if another reviewer holds an unexpired claim or
+// is resolving it. Claiming a request again renews the claim.
+func (q *UnbanQueue) Claim(id, reviewer string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	_, err := q.claimLocked(id, reviewer)
+	return err
+}
+
+// Release drops a reviewer's claim on a request.
+func (q *UnbanQueue) Release(id, reviewer string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if e, ok := q.entries[id]; ok && e.req.ClaimedBy == reviewer && !e.resolving {
+		e.req.ClaimedBy = ""
+		e.req.ClaimedAt = time.Time{}
+	}
+}
+
+// claimLocked claims a request for reviewer. q.mu must be held.
+func (q *UnbanQueue) claimLocked(id, reviewer string) (*unbanQueueEntry, error) {
+	e, ok := q.entries[id]
+	if !ok {
+		return nil, ErrUnbanRequestNotFound
+	}
+	held := e.req.ClaimedBy != "" && e.req.ClaimedBy != reviewer
+	if e.resolving || (held && time.Since(e.req.ClaimedAt) < q.claimTTL) {
+		return nil, &UnbanRequestClaimedError{RequestID: id, ClaimedBy: e.req.ClaimedBy}
+	}
+	e.req.ClaimedBy = reviewer
+	e.req.ClaimedAt = time.Now()
+	return e, nil
+}
+
+// AddTemplate registers a resolution message template, written with
+// text/template and executed with UnbanResolutionData. For example:
+//
+//	Hi {{.UserName}}, your ban for "{{.BanReason}}" has been lifted.
+func (q *UnbanQueue) AddTemplate(name, text string) error {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return fmt.Errorf("parsing unban template %q: %w", name, err)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.templates[name] = tmpl
+	return nil
+}
+
+// Resolve approves or denies a request as reviewer, with an optional
+// resolution message of at most 500 characters. The reviewer must hold the
+// claim or the request must be unclaimed; it is claimed for the duration of
+// the call.
+func (q *UnbanQueue) Resolve(ctx context.Context, id, reviewer string, approve bool, text string) (*UnbanRequest, error) {
+	q.mu.Lock()
+	e, err := q.claimLocked(id, reviewer)
+	if err != nil {
+		q.mu.Unlock()
+		return nil, err
+	}
+	e.resolving = true
+	q.mu.Unlock()
+
+	return q.resolve(ctx, id, approve, text)
+}
+
+// ResolveWithTemplate is like Resolve, with the resolution message rendered
+// from a template added with AddTemplate. A rendered message longer than 500
+// characters is an error, not truncated.
+func (q *UnbanQueue) ResolveWithTemplate(ctx context.Context, id, reviewer string, approve bool, templateName string) (*UnbanRequest, error) {
+	q.mu.Lock()
+	tmpl, ok := q.templates[templateName]
+	if !ok {
+		q.mu.Unlock()
+		return nil, fmt.Errorf("unknown unban template %q", templateName)
+	}
+	e, err := q.claimLocked(id, reviewer)
+	if err != nil {
+		q.mu.Unlock()
+		return nil, err
+	}
+	e.resolving = true
+	req := e.req
+	q.mu.Unlock()
+
+	data := UnbanResolutionData{PendingUnbanRequest: &req, Reviewer: reviewer, Approved: approve}
+	if req.Ban != nil {
+		data.BanReason = req.Ban.Reason
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		q.finishResolving(id)
+		return nil, fmt.Errorf("executing unban template %q: %w", templateName, err)
+	}
+	return q.resolve(ctx, id, approve, b.String())
+}
+
+// resolve calls Resolve Unban Request for a request marked as resolving.
+func (q *UnbanQueue) resolve(ctx context.Context, id string, approve bool, text string) (*UnbanRequest, error) {
+	status := UnbanRequestStatusDenied
+	if approve {
+		status = UnbanRequestStatusApproved
+	}
+	if n := utf8.RuneCountInString(text); n > maxUnbanResolutionText {
+		q.finishResolving(id)
+		return nil, fmt.Errorf("unban resolution text is %d characters, max %d", n, maxUnbanResolutionText)
+	}
+
+	resolved, err := q.client.ResolveUnbanRequest(ctx, &ResolveUnbanRequestParams{
+		BroadcasterID:  q.broadcasterID,
+		ModeratorID:    q.moderatorID,
+		UnbanRequestID: id,
+		Status:         status,
+		ResolutionText: text,
+	})
+	if err != nil {
+		q.finishResolving(id)
+		return nil, err
+	}
+	q.remove(id, status)
+	return resolved, nil
+}
+
+// finishResolving clears the resolving flag after a failed resolution, keeping the claim.
+func (q *UnbanQueue) finishResolving(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if e, ok := q.entries[id]; ok {
+		e.resolving = false
+	}
+}
+
+// remove drops a request from the queue.
+func (q *UnbanQueue) remove(id, status string) {
+	q.mu.Lock()
+	_, ok := q.entries[id]
+	delete(q.entries, id)
+	q.touchLocked(id)
+	q.mu.Unlock()
+
+	if ok && q.onRemove != nil {
+		q.onRemove(id, status)
+	}
+}
+
+// touchLocked records that a request changed while a Load is running, so
+// Load keeps its current state. q.mu must be held.
+func (q *UnbanQueue) touchLocked(id string) {
+	if q.loads > 0 {
+		q.touched[id] = struct{}{}
+	}
+}
+
+// fetchBans looks up the current bans of users, 100 at a time.
+func (q *UnbanQueue) fetchBans(ctx context.Context, userIDs []string) (map[string]*BannedUser, error) {
+	bans := make(map[string]*BannedUser, len(userIDs))
+	for chunk := range slices.Chunk(userIDs, 100) {
+		resp, err := q.client.GetBannedUsers(ctx, &GetBannedUsersParams{
+			BroadcasterID: q.broadcasterID,
+			UserIDs:       chunk,
+		})
+		if err != nil {
+			return bans, fmt.Errorf("getting banned users: %w", err)
+		}
+		for i := range resp.Data {
+			bans[resp.Data[i].UserID] = &resp.Data[i]
+		}
+	}
+	return bans, nil
+}
@@ -0,0 +1,250 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func unbanQueueHandler(t *testing.T, resolved *sync.Map, resolveCalls *int32, block chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/moderation/unban_requests" && r.Method == http.MethodGet:
+			if q.Get("status") != "pending" {
+				t.Errorf("status = %q, want pending", q.Get("status"))
+			}
+			resp := Response[UnbanRequest]{}
+			if q.Get("after") == "" {
+				resp.Data = []UnbanRequest{{ID: "r2", UserID: "u2", UserName: "Two", Status: "pending", CreatedAt: "2026-01-02T00:00:00Z"}}
+				resp.Pagination = &Pagination{Cursor: "page2"}
+			} else {
+				resp.Data = []UnbanRequest{{ID: "r1", UserID: "u1", UserName: "One", Status: "pending", CreatedAt: "2026-01-01T00:00:00Z"}}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/moderation/banned":
+			var bans []BannedUser
+			for _, id := range q["user_id"] {
+				bans = append(bans, BannedUser{UserID: id, Reason: "reason for " + id, ModeratorLogin: "mod"})
+			}
+			_ = json.NewEncoder(w).Encode(Response[BannedUser]{Data: bans})
+		case r.URL.Path == "/moderation/unban_requests" && r.Method == http.MethodPatch:
+			atomic.AddInt32(resolveCalls, 1)
+			if block != nil {
+				<-block
+			}
+			resolved.Store(q.Get("unban_request_id"), q.Get("status")+"|"+q.Get("resolution_text"))
+			_ = json.NewEncoder(w).Encode(Response[UnbanRequest]{Data: []UnbanRequest{{ID: q.Get("unban_request_id"), Status: q.Get("status")}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestUnbanQueue_LoadAndEvents(t *testing.T) {
+	var resolved sync.Map
+	var calls int32
+	client, server := newTestClient(unbanQueueHandler(t, &resolved, &calls, nil))
+	defer server.Close()
+
+	var removed []string
+	queue := NewUnbanQueue(client, "1234", "mod1", WithUnbanQueueRemoveHandler(func(id, status string) {
+		removed = append(removed, id+":"+status)
+	}))
+	ctx := context.Background()
+
+	if err := queue.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	pending := queue.Pending()
+	if len(pending) != 2 || pending[0].ID != "r1" || pending[1].ID != "r2" {
+		t.Fatalf("Pending() = %+v", pending)
+	}
+	if pending[0].Ban == nil || pending[0].Ban.Reason != "reason for u1" {
+		t.Errorf("r1 ban = %+v", pending[0].Ban)
+	}
+
+	create := `{"id":"r3","broadcaster_user_id":"1234","broadcaster_user_login":"chan","user_id":"u3","user_login":"three","user_name":"Three","text":"sorry","created_at":"2026-01-03T00:00:00Z"}`
+	if err := queue.HandleEvent(ctx, EventSubTypeChannelUnbanRequestCreate, json.RawMessage(create)); err != nil {
+		t.Fatalf("HandleEvent(create) error = %v", err)
+	}
+	r3, ok := queue.Get("r3")
+	if !ok || r3.Text != "sorry" || r3.Ban == nil || r3.Ban.Reason != "reason for u3" {
+		t.Errorf("r3 = %+v, %v", r3, ok)
+	}
+
+	resolve := `{"id":"r2","broadcaster_user_id":"1234","moderator_user_id":"mod2","user_id":"u2","status":"canceled"}`
+	if err := queue.HandleEvent(ctx, EventSubTypeChannelUnbanRequestResolve, json.RawMessage(resolve)); err != nil {
+		t.Fatalf("HandleEvent(resolve) error = %v", err)
+	}
+	if _, ok := queue.Get("r2"); ok {
+		t.Error("r2 still queued after resolve event")
+	}
+	if len(removed) != 1 || removed[0] != "r2:canceled" {
+		t.Errorf("removed = %v", removed)
+	}
+}
+
+func TestUnbanQueue_ClaimAndTemplate(t *testing.T) {
+	var resolved sync.Map
+	var calls int32
+	client, server := newTestClient(unbanQueueHandler(t, &resolved, &calls, nil))
+	defer server.Close()
+
+	queue := NewUnbanQueue(client, "1234", "mod1")
+	ctx := context.Background()
+	if err := queue.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := queue.Claim("r1", "alice"); err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	var claimed *UnbanRequestClaimedError
+	if err := queue.Claim("r1", "bob"); !errors.As(err, &claimed) || claimed.ClaimedBy != "alice" {
+		t.Fatalf("Claim() by bob error = %v", err)
+	}
+	if _, err := queue.Resolve(ctx, "r1", "bob", true, ""); !errors.As(err, &claimed) {
+		t.Fatalf("Resolve() by bob error = %v", err)
+	}
+	if err := queue.Claim("missing", "bob"); !errors.Is(err, ErrUnbanRequestNotFound) {
+		t.Errorf("Claim(missing) error = %v", err)
+	}
+
+	// Text over Twitch's limit is rejected without a call, keeping the claim
+	if _, err := queue.Resolve(ctx, "r1", "alice", false, strings.Repeat("é", 501)); err == nil || atomic.LoadInt32(&calls) != 0 {
+		t.Fatalf("Resolve() with long text error = %v, calls = %d", err, calls)
+	}
+	if err := queue.Claim("r1", "bob"); !errors.As(err, &claimed) {
+		t.Fatalf("Claim() by bob after failed resolve error = %v", err)
+	}
+
+	if err := queue.AddTemplate("deny", `Hi {{.UserName}}, your ban for "{{.BanReason}}" stands. - {{.Reviewer}}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queue.ResolveWithTemplate(ctx, "r1", "alice", false, "deny"); err != nil {
+		t.Fatalf("ResolveWithTemplate() error = %v", err)
+	}
+	got, _ := resolved.Load("r1")
+	if got != `denied|Hi One, your ban for "reason for u1" stands. - alice` {
+		t.Errorf("resolution = %v", got)
+	}
+	if _, ok := queue.Get("r1"); ok {
+		t.Error("r1 still queued after resolution")
+	}
+
+	// Released claims can be taken by others
+	if err := queue.Claim("r2", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	queue.Release("r2", "alice")
+	if err := queue.Claim("r2", "bob"); err != nil {
+		t.Errorf("Claim() after Release error = %v", err)
+	}
+}
+
+func TestUnbanQueue_ExpiredClaim(t *testing.T) {
+	var resolved sync.Map
+	var calls int32
+	client, server := newTestClient(unbanQueueHandler(t, &resolved, &calls, nil))
+	defer server.Close()
+
+	queue := NewUnbanQueue(client, "1234", "mod1", WithUnbanClaimTTL(10*time.Millisecond))
+	if err := queue.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := queue.Claim("r1", "alice"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := queue.Claim("r1", "bob"); err != nil {
+		t.Errorf("Claim() after expiry error = %v", err)
+	}
+}
+
+func TestUnbanQueue_ConcurrentResolve(t *testing.T) {
+	var resolved sync.Map
+	var calls int32
+	block := make(chan struct{})
+	client, server := newTestClient(unbanQueueHandler(t, &resolved, &calls, block))
+	defer server.Close()
+
+	queue := NewUnbanQueue(client, "1234", "mod1")
+	ctx := context.Background()
+	if err := queue.Load(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// alice starts resolving an unclaimed request; bob must be refused while
+	// the call is in flight
+	done := make(chan error, 1)
+	go func() {
+		_, err := queue.Resolve(ctx, "r1", "alice", true, "welcome back")
+		done <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	var claimed *UnbanRequestClaimedError
+	if _, err := queue.Resolve(ctx, "r1", "bob", false, ""); !errors.As(err, &claimed) {
+		t.Errorf("concurrent Resolve() error = %v, want claimed", err)
+	}
+	close(block)
+	if err := <-done; err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("resolve calls = %d, want 1", calls)
+	}
+}
+
+func TestUnbanQueue_EventsDuringLoad(t *testing.T) {
+	var resolved sync.Map
+	var calls int32
+	inner := unbanQueueHandler(t, &resolved, &calls, nil)
+	entered, release := make(chan struct{}), make(chan struct{})
+	var once sync.Once
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moderation/unban_requests" && r.Method == http.MethodGet {
+			// Hold the first page until the events below are handled
+			once.Do(func() {
+				close(entered)
+				<-release
+			})
+		}
+		inner(w, r)
+	}))
+	defer server.Close()
+
+	queue := NewUnbanQueue(client, "1234", "mod1")
+	ctx := context.Background()
+	loaded := make(chan error, 1)
+	go func() { loaded <- queue.Load(ctx) }()
+	<-entered
+
+	create := `{"id":"r3","broadcaster_user_id":"1234","broadcaster_user_login":"chan","user_id":"u3","user_login":"three","user_name":"Three","text":"sorry","created_at":"2026-01-03T00:00:00Z"}`
+	if err := queue.HandleEvent(ctx, EventSubTypeChannelUnbanRequestCreate, json.RawMessage(create)); err != nil {
+		t.Fatalf("HandleEvent(create) error = %v", err)
+	}
+	resolve := `{"id":"r1","broadcaster_user_id":"1234","moderator_user_id":"mod1","user_id":"u1","status":"approved"}`
+	if err := queue.HandleEvent(ctx, EventSubTypeChannelUnbanRequestResolve, json.RawMessage(resolve)); err != nil {
+		t.Fatalf("HandleEvent(resolve) error = %v", err)
+	}
+	close(release)
+	if err := <-loaded; err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	// r1 was resolved and r3 created after the pages were requested
+	pending := queue.Pending()
+	if len(pending) != 2 || pending[0].ID != "r2" || pending[1].ID != "r3" {
+		t.Errorf("Pending() = %+v", pending)
+	}
+}
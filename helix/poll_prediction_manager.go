@@ -0,0 +1,637 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Poll statuses
+const (
+	PollStatusActive     = "ACTIVE"
+	PollStatusCompleted  = "COMPLETED"
+	PollStatusTerminated = "TERMINATED"
+	PollStatusArchived   = "ARCHIVED"
+)
+
+// Prediction statuses
+const (
+	PredictionStatusActive   = "ACTIVE"
+	PredictionStatusLocked   = "LOCKED"
+	PredictionStatusResolved = "RESOLVED"
+	PredictionStatusCanceled = "CANCELED"
+)
+
+// pollPredictionRefreshDelay is how long after a poll's duration or a
+// prediction's window the manager refreshes it, giving Twitch time to close it.
+const pollPredictionRefreshDelay = 5 * time.Second
+
+// ErrPredictionOutcomeNotFound is returned by LivePrediction.Resolve when no
+// outcome has the given title.
+var ErrPredictionOutcomeNotFound = errors.New("prediction outcome not found")
+
+// pollPredictionEventTypes are the EventSub types a PollPredictionManager
+// subscribes to.
+var pollPredictionEventTypes = []string{
+	EventSubTypeChannelPollBegin,
+	EventSubTypeChannelPollProgress,
+	EventSubTypeChannelPollEnd,
+	EventSubTypeChannelPredictionBegin,
+	EventSubTypeChannelPredictionProgress,
+	EventSubTypeChannelPredictionLock,
+	EventSubTypeChannelPredictionEnd,
+}
+
+// PollPredictionManager creates polls and predictions and tracks them until
+// they end. It keeps a live snapshot of each one from channel.poll.* and
+// channel.prediction.* events, and from the responses of its own API calls.
+// It also works without EventSub, minus the live tallies: once a poll's
+// duration or a prediction's window has passed, the manager fetches its state,
+// so polls that ended on their own stop being tracked.
+//
+// Call Close on shutdown to terminate running polls and cancel unresolved
+// predictions, refunding the points wagered on them.
+type PollPredictionManager struct {
+	client        *Client
+	broadcasterID string
+	onError       func(error)
+
+	mu          sync.Mutex
+	polls       map[string]*LivePoll
+	predictions map[string]*LivePrediction
+}
+
+// PollPredictionManagerOption configures a PollPredictionManager.
+type PollPredictionManagerOption func(*PollPredictionManager)
+
+// WithPollPredictionErrorHandler sets the handler for errors from EventSub
+// notifications and automatic prediction locks.
+func WithPollPredictionErrorHandler(fn func(error)) PollPredictionManagerOption {
+	return func(m *PollPredictionManager) {
+		m.onError = fn
+	}
+}
+
+// NewPollPredictionManager creates a poll and prediction manager for a
+// channel. The broadcaster's token needs channel:manage:polls and
+// channel:manage:predictions.
+func NewPollPredictionManager(client *Client, broadcasterID string, opts ...PollPredictionManagerOption) *PollPredictionManager {
+	m := &PollPredictionManager{
+		client:        client,
+		broadcasterID: broadcasterID,
+		polls:         make(map[string]*LivePoll),
+		predictions:   make(map[string]*LivePrediction),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Subscribe subscribes to the poll and prediction begin, progress, lock, and
+// end events for the channel on a connected EventSubWebSocket.
+func (m *PollPredictionManager) Subscribe(ctx context.Context, ws *EventSubWebSocket) error {
+	for _, eventType := range pollPredictionEventTypes {
+		err := ws.Subscribe(ctx, eventType, GetEventSubVersion(eventType), BroadcasterCondition(m.broadcasterID), func(data json.RawMessage) {
+			if err := m.HandleEvent(context.Background(), eventType, data); err != nil {
+				m.handleError(err)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("subscribing to %s: %w", eventType, err)
+		}
+	}
+	return nil
+}
+
+// HandleEvent processes a raw channel.poll.* or channel.prediction.* event.
+// Events for polls and predictions the manager didn't start, and other event
+// types, are ignored.
+func (m *PollPredictionManager) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	switch eventType {
+	case EventSubTypeChannelPollBegin, EventSubTypeChannelPollProgress:
+		event, err := ParseWSEvent[ChannelPollBeginEvent](data)
+		if err != nil {
+			return err
+		}
+		if lp := m.livePoll(event.ID); lp != nil {
+			m.updatePoll(lp, func(p *Poll) {
+				p.Choices = pollChoicesFromEvent(event.Choices)
+			})
+		}
+	case EventSubTypeChannelPollEnd:
+		event, err := ParseWSEvent[ChannelPollEndEvent](data)
+		if err != nil {
+			return err
+		}
+		if lp := m.livePoll(event.ID); lp != nil {
+			m.updatePoll(lp, func(p *Poll) {
+				p.Choices = pollChoicesFromEvent(event.Choices)
+				p.Status = strings.ToUpper(event.Status)
+				p.EndedAt = NewNullableTime(event.EndedAt)
+			})
+		}
+	case EventSubTypeChannelPredictionBegin, EventSubTypeChannelPredictionProgress, EventSubTypeChannelPredictionLock:
+		event, err := ParseWSEvent[ChannelPredictionBeginEvent](data)
+		if err != nil {
+			return err
+		}
+		if lp := m.livePrediction(event.ID); lp != nil {
+			m.updatePrediction(lp, func(p *Prediction) {
+				p.Outcomes = predictionOutcomesFromEvent(event.Outcomes)
+				if eventType == EventSubTypeChannelPredictionLock {
+					p.Status = PredictionStatusLocked
+					p.LockedAt = NewNullableTime(time.Now())
+				}
+			})
+		}
+	case EventSubTypeChannelPredictionEnd:
+		event, err := ParseWSEvent[ChannelPredictionEndEvent](data)
+		if err != nil {
+			return err
+		}
+		if lp := m.livePrediction(event.ID); lp != nil {
+			m.updatePrediction(lp, func(p *Prediction) {
+				p.Outcomes = predictionOutcomesFromEvent(event.Outcomes)
+				p.Status = strings.ToUpper(event.Status)
+				p.WinningOutcomeID = event.WinningOutcomeID
+				p.EndedAt = NewNullableTime(event.EndedAt)
+			})
+		}
+	}
+	return nil
+}
+
+// StartPoll creates a poll and tracks it. params.BroadcasterID defaults to
+// the manager's channel.
+func (m *PollPredictionManager) StartPoll(ctx context.Context, params *CreatePollParams) (*LivePoll, error) {
+	p := *params
+	if p.BroadcasterID == "" {
+		p.BroadcasterID = m.broadcasterID
+	}
+	poll, err := m.client.CreatePoll(ctx, &p)
+	if err != nil {
+		return nil, err
+	}
+	if poll == nil {
+		return nil, errors.New("create poll returned no data")
+	}
+
+	lp := &LivePoll{manager: m, state: newLiveState(poll.ID, *poll, clonePoll)}
+	m.mu.Lock()
+	m.polls[poll.ID] = lp
+	if poll.Duration > 0 {
+		lp.refreshTimer = time.AfterFunc(time.Duration(poll.Duration)*time.Second+pollPredictionRefreshDelay, func() {
+			if lp.Snapshot().Status != PollStatusActive {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := m.refreshPoll(ctx, lp); err != nil {
+				m.handleError(fmt.Errorf("refreshing poll %s: %w", lp.ID(), err))
+			}
+		})
+	}
+	m.mu.Unlock()
+	return lp, nil
+}
+
+// StartPrediction creates a prediction and tracks it. params.BroadcasterID
+// defaults to the manager's channel. If lockAfter is positive, the prediction
+// is locked once it has been open that long, even if its prediction window
+// is longer.
+func (m *PollPredictionManager) StartPrediction(ctx context.Context, params *CreatePredictionParams, lockAfter time.Duration) (*LivePrediction, error) {
+	p := *params
+	if p.BroadcasterID == "" {
+		p.BroadcasterID = m.broadcasterID
+	}
+	prediction, err := m.client.CreatePrediction(ctx, &p)
+	if err != nil {
+		return nil, err
+	}
+	if prediction == nil {
+		return nil, errors.New("create prediction returned no data")
+	}
+
+	lp := &LivePrediction{manager: m, state: newLiveState(prediction.ID, *prediction, clonePrediction)}
+	m.mu.Lock()
+	m.predictions[prediction.ID] = lp
+	if prediction.PredictionWindow > 0 {
+		lp.refreshTimer = time.AfterFunc(time.Duration(prediction.PredictionWindow)*time.Second+pollPredictionRefreshDelay, func() {
+			if lp.Snapshot().Status != PredictionStatusActive {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := m.refreshPrediction(ctx, lp); err != nil {
+				m.handleError(fmt.Errorf("refreshing prediction %s: %w", lp.ID(), err))
+			}
+		})
+	}
+	if lockAfter > 0 {
+		lp.lockTimer = time.AfterFunc(lockAfter, func() {
+			if lp.Snapshot().Status != PredictionStatusActive {
+				return
+			}
+			if err := lp.Lock(context.Background()); err != nil {
+				m.handleError(fmt.Errorf("locking prediction %s: %w", lp.ID(), err))
+			}
+		})
+	}
+	m.mu.Unlock()
+	return lp, nil
+}
+
+// Polls returns the polls the manager is tracking.
+func (m *PollPredictionManager) Polls() []*LivePoll {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	polls := make([]*LivePoll, 0, len(m.polls))
+	for _, lp := range m.polls {
+		polls = append(polls, lp)
+	}
+	return polls
+}
+
+// Predictions returns the predictions the manager is tracking.
+func (m *PollPredictionManager) Predictions() []*LivePrediction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	predictions := make([]*LivePrediction, 0, len(m.predictions))
+	for _, lp := range m.predictions {
+		predictions = append(predictions, lp)
+	}
+	return predictions
+}
+
+// Close terminates every running poll and cancels every unresolved
+// prediction, refunding its points. Polls and predictions that turn out to
+// have ended already are not errors. Use a context that is not already
+// canceled, such as one with a short timeout created during shutdown.
+func (m *PollPredictionManager) Close(ctx context.Context) error {
+	var errs []error
+	for _, lp := range m.Polls() {
+		if err := lp.End(ctx); err != nil {
+			if m.refreshPoll(ctx, lp) == nil && lp.Snapshot().Status != PollStatusActive {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("ending poll %s: %w", lp.ID(), err))
+		}
+	}
+	for _, lp := range m.Predictions() {
+		if err := lp.Cancel(ctx); err != nil {
+			if m.refreshPrediction(ctx, lp) == nil {
+				if status := lp.Snapshot().Status; status == PredictionStatusResolved || status == PredictionStatusCanceled {
+					continue
+				}
+			}
+			errs = append(errs, fmt.Errorf("canceling prediction %s: %w", lp.ID(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// refreshPoll updates a poll from Get Polls.
+func (m *PollPredictionManager) refreshPoll(ctx context.Context, lp *LivePoll) error {
+	resp, err := m.client.GetPolls(ctx, &GetPollsParams{BroadcasterID: lp.Snapshot().BroadcasterID, IDs: []string{lp.ID()}})
+	if err != nil {
+		return err
+	}
+	if len(resp.Data) == 0 {
+		return fmt.Errorf("poll %s not found", lp.ID())
+	}
+	m.updatePoll(lp, func(p *Poll) { *p = resp.Data[0] })
+	return nil
+}
+
+// refreshPrediction updates a prediction from Get Predictions.
+func (m *PollPredictionManager) refreshPrediction(ctx context.Context, lp *LivePrediction) error {
+	resp, err := m.client.GetPredictions(ctx, &GetPredictionsParams{BroadcasterID: lp.Snapshot().BroadcasterID, IDs: []string{lp.ID()}})
+	if err != nil {
+		return err
+	}
+	if len(resp.Data) == 0 {
+		return fmt.Errorf("prediction %s not found", lp.ID())
+	}
+	m.updatePrediction(lp, func(p *Prediction) { *p = resp.Data[0] })
+	return nil
+}
+
+// livePoll returns a tracked poll.
+func (m *PollPredictionManager) livePoll(id string) *LivePoll {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.polls[id]
+}
+
+// livePrediction returns a tracked prediction.
+func (m *PollPredictionManager) livePrediction(id string) *LivePrediction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.predictions[id]
+}
+
+// updatePoll applies a change to a poll, and stops tracking it once it ends.
+func (m *PollPredictionManager) updatePoll(lp *LivePoll, fn func(*Poll)) {
+	if lp.state.update(func(p *Poll) bool {
+		fn(p)
+		return p.Status != PollStatusActive
+	}) {
+		m.mu.Lock()
+		delete(m.polls, lp.ID())
+		if lp.refreshTimer != nil {
+			lp.refreshTimer.Stop()
+		}
+		m.mu.Unlock()
+	}
+}
+
+// updatePrediction applies a change to a prediction, and stops tracking it
+// once it is resolved or canceled.
+func (m *PollPredictionManager) updatePrediction(lp *LivePrediction, fn func(*Prediction)) {
+	if lp.state.update(func(p *Prediction) bool {
+		fn(p)
+		return p.Status == PredictionStatusResolved || p.Status == PredictionStatusCanceled
+	}) {
+		m.mu.Lock()
+		delete(m.predictions, lp.ID())
+		if lp.lockTimer != nil {
+			lp.lockTimer.Stop()
+		}
+		if lp.refreshTimer != nil {
+			lp.refreshTimer.Stop()
+		}
+		m.mu.Unlock()
+	}
+}
+
+// handleError reports an error to the error handler, if set.
+func (m *PollPredictionManager) handleError(err error) {
+	if m.onError != nil {
+		m.onError(err)
+	}
+}
+
+// LivePoll is a poll tracked by a PollPredictionManager.
+type LivePoll struct {
+	manager      *PollPredictionManager
+	state        *liveState[Poll]
+	refreshTimer *time.Timer // Guarded by manager.mu
+}
+
+// ID returns the poll ID.
+func (lp *LivePoll) ID() string {
+	return lp.state.id
+}
+
+// Snapshot returns the latest state of the poll.
+func (lp *LivePoll) Snapshot() Poll {
+	return lp.state.get()
+}
+
+// Updates returns a channel that receives a snapshot after each change. Only
+// the latest snapshot is kept if the receiver falls behind. The channel is
+// closed after the final snapshot once the poll ends.
+func (lp *LivePoll) Updates() <-chan Poll {
+	return lp.state.updates
+}
+
+// Done returns a channel that is closed when the poll ends.
+func (lp *LivePoll) Done() <-chan struct{} {
+	return lp.state.done
+}
+
+// Wait blocks until the poll ends and returns its final state.
+func (lp *LivePoll) Wait(ctx context.Context) (Poll, error) {
+	return lp.state.wait(ctx)
+}
+
+// End ends the poll early, keeping the results visible (TERMINATED).
+func (lp *LivePoll) End(ctx context.Context) error {
+	return lp.end(ctx, PollStatusTerminated)
+}
+
+// Archive ends the poll early and hides the results (ARCHIVED).
+func (lp *LivePoll) Archive(ctx context.Context) error {
+	return lp.end(ctx, PollStatusArchived)
+}
+
+func (lp *LivePoll) end(ctx context.Context, status string) error {
+	poll, err := lp.manager.client.EndPoll(ctx, &EndPollParams{
+		BroadcasterID: lp.Snapshot().BroadcasterID,
+		ID:            lp.ID(),
+		Status:        status,
+	})
+	if err != nil {
+		return err
+	}
+	if poll != nil {
+		lp.manager.updatePoll(lp, func(p *Poll) { *p = *poll })
+	}
+	return nil
+}
+
+// LivePrediction is a prediction tracked by a PollPredictionManager.
+type LivePrediction struct {
+	manager      *PollPredictionManager
+	state        *liveState[Prediction]
+	lockTimer    *time.Timer // Guarded by manager.mu
+	refreshTimer *time.Timer // Guarded by manager.mu
+}
+
+// ID returns the prediction ID.
+func (lp *LivePrediction) ID() string {
+	return lp.state.id
+}
+
+// Snapshot returns the latest state of the prediction.
+func (lp *LivePrediction) Snapshot() Prediction {
+	return lp.state.get()
+}
+
+// Updates returns a channel that receives a snapshot after each change. Only
+// the latest snapshot is kept if the receiver falls behind. The channel is
+// closed after the final snapshot once the prediction is resolved or canceled.
+func (lp *LivePrediction) Updates() <-chan Prediction {
+	return lp.state.updates
+}
+
+// Done returns a channel that is closed when the prediction is resolved or
+// canceled.
+func (lp *LivePrediction) Done() <-chan struct{} {
+	return lp.state.done
+}
+
+// Wait blocks until the prediction is resolved or canceled and returns its
+// final state.
+func (lp *LivePrediction) Wait(ctx context.Context) (Prediction, error) {
+	return lp.state.wait(ctx)
+}
+
+// Lock stops accepting predictions.
+func (lp *LivePrediction) Lock(ctx context.Context) error {
+	return lp.end(ctx, PredictionStatusLocked, "")
+}
+
+// Resolve resolves the prediction with the outcome whose title matches
+// outcomeTitle (case-insensitive), paying out the viewers who chose it.
+func (lp *LivePrediction) Resolve(ctx context.Context, outcomeTitle string) error {
+	snapshot := lp.Snapshot()
+	for _, o := range snapshot.Outcomes {
+		if strings.EqualFold(o.Title, outcomeTitle) {
+			return lp.end(ctx, PredictionStatusResolved, o.ID)
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrPredictionOutcomeNotFound, outcomeTitle)
+}
+
+// Cancel cancels the prediction, refunding all points.
+func (lp *LivePrediction) Cancel(ctx context.Context) error {
+	return lp.end(ctx, PredictionStatusCanceled, "")
+}
+
+func (lp *LivePrediction) end(ctx context.Context, status, winningOutcomeID string) error {
+	prediction, err := lp.manager.client.EndPrediction(ctx, &EndPredictionParams{
+		BroadcasterID:    lp.Snapshot().BroadcasterID,
+		ID:               lp.ID(),
+		Status:           status,
+		WinningOutcomeID: winningOutcomeID,
+	})
+	if err != nil {
+		return err
+	}
+	if prediction != nil {
+		lp.manager.updatePrediction(lp, func(p *Prediction) { *p = *prediction })
+	}
+	return nil
+}
+
+// liveState holds the latest snapshot of a poll or prediction and publishes
+// changes to it.
+type liveState[T any] struct {
+	id      string
+	clone   func(T) T
+	updates chan T
+	done    chan struct{}
+
+	mu    sync.Mutex
+	value T
+	ended bool
+}
+
+func newLiveState[T any](id string, value T, clone func(T) T) *liveState[T] {
+	return &liveState[T]{
+		id:      id,
+		clone:   clone,
+		updates: make(chan T, 1),
+		done:    make(chan struct{}),
+		value:   value,
+	}
+}
+
+// get returns a copy of the latest snapshot.
+func (s *liveState[T]) get() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clone(s.value)
+}
+
+// update applies fn to the snapshot and publishes the result. fn reports
+// whether the poll or prediction has ended; update returns true if this
+// update ended it. Updates after the end are ignored.
+func (s *liveState[T]) update(fn func(*T) bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return false
+	}
+	ended := fn(&s.value)
+
+	// Replace a snapshot the receiver hasn't picked up yet
+	select {
+	case <-s.updates:
+	default:
+	}
+	s.updates <- s.clone(s.value)
+
+	if ended {
+		s.ended = true
+		close(s.updates)
+		close(s.done)
+	}
+	return ended
+}
+
+// wait blocks until the state ends and returns the final snapshot.
+func (s *liveState[T]) wait(ctx context.Context) (T, error) {
+	select {
+	case <-s.done:
+		return s.get(), nil
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// clonePoll copies a poll so snapshots don't share choices.
+func clonePoll(p Poll) Poll {
+	p.Choices = slices.Clone(p.Choices)
+	return p
+}
+
+// clonePrediction copies a prediction so snapshots don't share outcomes.
+func clonePrediction(p Prediction) Prediction {
+	p.Outcomes = slices.Clone(p.Outcomes)
+	for i := range p.Outcomes {
+		p.Outcomes[i].TopPredictors = slices.Clone(p.Outcomes[i].TopPredictors)
+	}
+	return p
+}
+
+// pollChoicesFromEvent converts EventSub poll choices to Helix poll choices.
+func pollChoicesFromEvent(choices []EventSubChoice) []PollChoice {
+	out := make([]PollChoice, len(choices))
+	for i, c := range choices {
+		out[i] = PollChoice{
+			ID:                 c.ID,
+			Title:              c.Title,
+			Votes:              c.Votes,
+			ChannelPointsVotes: c.ChannelPointsVotes,
+			BitsVotes:          c.BitsVotes,
+		}
+	}
+	return out
+}
+
+// predictionOutcomesFromEvent converts EventSub prediction outcomes to Helix
+// prediction outcomes.
+func predictionOutcomesFromEvent(outcomes []EventSubOutcome) []PredictionOutcome {
+	out := make([]PredictionOutcome, len(outcomes))
+	for i, o := range outcomes {
+		out[i] = PredictionOutcome{
+			ID:            o.ID,
+			Title:         o.Title,
+			Users:         o.Users,
+			ChannelPoints: o.ChannelPoints,
+			Color:         strings.ToUpper(o.Color),
+		}
+		for _, p := range o.TopPredictors {
+			predictor := PredictionPredictor{
+				UserID:            p.UserID,
+				UserLogin:         p.UserLogin,
+				UserName:          p.UserName,
+				ChannelPointsUsed: p.ChannelPointsUsed,
+			}
+			if p.ChannelPointsWon != nil {
+				predictor.ChannelPointsWon = *p.ChannelPointsWon
+			}
+			out[i].TopPredictors = append(out[i].TopPredictors, predictor)
+		}
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func pollPredictionHandler(t *testing.T, ends *[]string) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/polls" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(Response[Poll]{Data: []Poll{{
+				ID: "p1", BroadcasterID: "1234", Title: "Best map?", Status: PollStatusActive,
+				Choices: []PollChoice{{ID: "c1", Title: "Dust"}, {ID: "c2", Title: "Mirage"}},
+			}}})
+		case r.URL.Path == "/predictions" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(Response[Prediction]{Data: []Prediction{{
+				ID: "pr1", BroadcasterID: "1234", Title: "Win?", Status: PredictionStatusActive,
+				Outcomes: []PredictionOutcome{{ID: "o1", Title: "Yes"}, {ID: "o2", Title: "No"}},
+			}}})
+		case r.Method == http.MethodPatch:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			*ends = append(*ends, body["id"]+":"+body["status"]+":"+body["winning_outcome_id"])
+			mu.Unlock()
+			if r.URL.Path == "/polls" {
+				_ = json.NewEncoder(w).Encode(Response[Poll]{Data: []Poll{{ID: body["id"], BroadcasterID: "1234", Status: body["status"]}}})
+			} else {
+				_ = json.NewEncoder(w).Encode(Response[Prediction]{Data: []Prediction{{
+					ID: body["id"], BroadcasterID: "1234", Status: body["status"], WinningOutcomeID: body["winning_outcome_id"],
+					Outcomes: []PredictionOutcome{{ID: "o1", Title: "Yes"}, {ID: "o2", Title: "No"}},
+				}}})
+			}
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}
+}
+
+func TestPollPredictionManager_PollEvents(t *testing.T) {
+	var ends []string
+	client, server := newTestClient(pollPredictionHandler(t, &ends))
+	defer server.Close()
+
+	m := NewPollPredictionManager(client, "1234")
+	ctx := context.Background()
+	poll, err := m.StartPoll(ctx, &CreatePollParams{Title: "Best map?", Duration: 60,
+		Choices: []CreatePollChoice{{Title: "Dust"}, {Title: "Mirage"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress := `{"id":"p1","broadcaster_user_id":"1234","title":"Best map?","choices":[{"id":"c1","title":"Dust","votes":3},{"id":"c2","title":"Mirage","votes":5}]}`
+	if err := m.HandleEvent(ctx, EventSubTypeChannelPollProgress, json.RawMessage(progress)); err != nil {
+		t.Fatal(err)
+	}
+	update := <-poll.Updates()
+	if update.Choices[1].Votes != 5 || poll.Snapshot().Choices[0].Votes != 3 {
+		t.Errorf("update = %+v", update)
+	}
+
+	end := `{"id":"p1","broadcaster_user_id":"1234","title":"Best map?","choices":[{"id":"c1","title":"Dust","votes":4},{"id":"c2","title":"Mirage","votes":9}],"status":"completed","ended_at":"2026-01-01T00:01:00Z"}`
+	if err := m.HandleEvent(ctx, EventSubTypeChannelPollEnd, json.RawMessage(end)); err != nil {
+		t.Fatal(err)
+	}
+	final, err := poll.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Status != PollStatusCompleted || final.Choices[1].Votes != 9 || !final.EndedAt.Valid {
+		t.Errorf("final = %+v", final)
+	}
+	if last := <-poll.Updates(); last.Status != PollStatusCompleted {
+		t.Errorf("last update = %+v", last)
+	}
+	if _, ok := <-poll.Updates(); ok {
+		t.Error("Updates() not closed after end")
+	}
+	if len(m.Polls()) != 0 {
+		t.Error("ended poll still tracked")
+	}
+
+	// Nothing left to clean up
+	if err := m.Close(ctx); err != nil || len(ends) != 0 {
+		t.Errorf("Close() = %v, ends = %v", err, ends)
+	}
+}
+
+func TestPollPredictionManager_PredictionLifecycle(t *testing.T) {
+	var ends []string
+	client, server := newTestClient(pollPredictionHandler(t, &ends))
+	defer server.Close()
+
+	m := NewPollPredictionManager(client, "1234")
+	ctx := context.Background()
+	prediction, err := m.StartPrediction(ctx, &CreatePredictionParams{Title: "Win?", PredictionWindow: 120,
+		Outcomes: []CreatePredictionOutcome{{Title: "Yes"}, {Title: "No"}}}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Auto-locked after the window
+	for prediction.Snapshot().Status != PredictionStatusLocked {
+		select {
+		case <-prediction.Updates():
+		case <-time.After(time.Second):
+			t.Fatal("prediction not locked")
+		}
+	}
+
+	if err := prediction.Resolve(ctx, "maybe"); !errors.Is(err, ErrPredictionOutcomeNotFound) {
+		t.Errorf("Resolve(maybe) error = %v", err)
+	}
+	if err := prediction.Resolve(ctx, "no"); err != nil {
+		t.Fatal(err)
+	}
+	final, _ := prediction.Wait(ctx)
+	if final.Status != PredictionStatusResolved || final.WinningOutcomeID != "o2" {
+		t.Errorf("final = %+v", final)
+	}
+	if len(ends) != 2 || ends[0] != "pr1:LOCKED:" || ends[1] != "pr1:RESOLVED:o2" {
+		t.Errorf("ends = %v", ends)
+	}
+}
+
+func TestPollPredictionManager_Close(t *testing.T) {
+	var ends []string
+	client, server := newTestClient(pollPredictionHandler(t, &ends))
+	defer server.Close()
+
+	m := NewPollPredictionManager(client, "1234")
+	ctx := context.Background()
+	if _, err := m.StartPoll(ctx, &CreatePollParams{Title: "Best map?"}); err != nil {
+		t.Fatal(err)
+	}
+	prediction, err := m.StartPrediction(ctx, &CreatePredictionParams{Title: "Win?"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := `{"id":"pr1","broadcaster_user_id":"1234","title":"Win?","outcomes":[{"id":"o1","title":"Yes","color":"blue","users":2,"channel_points":500}]}`
+	if err := m.HandleEvent(ctx, EventSubTypeChannelPredictionLock, json.RawMessage(lock)); err != nil {
+		t.Fatal(err)
+	}
+	if s := prediction.Snapshot(); s.Status != PredictionStatusLocked || s.Outcomes[0].Color != "BLUE" || s.Outcomes[0].ChannelPoints != 500 {
+		t.Errorf("snapshot = %+v", s)
+	}
+
+	if err := m.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(ends) != 2 || ends[0] != "p1:TERMINATED:" || ends[1] != "pr1:CANCELED:" {
+		t.Errorf("ends = %v", ends)
+	}
+	if len(m.Polls()) != 0 || len(m.Predictions()) != 0 {
+		t.Error("manager still tracking after Close")
+	}
+}
+
+func TestPollPredictionManager_CloseWithoutEventSub(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/polls" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(Response[Poll]{Data: []Poll{{ID: "p1", BroadcasterID: "1234", Status: PollStatusActive}}})
+		case r.URL.Path == "/predictions" && r.Method == http.MethodPost:
+			_ = json.NewEncoder(w).Encode(Response[Prediction]{Data: []Prediction{{ID: "pr1", BroadcasterID: "1234", Status: PredictionStatusActive}}})
+		case r.Method == http.MethodPatch:
+			// Both ended on Twitch without the manager seeing an event
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"Bad Request","status":400,"message":"already ended"}`))
+		case r.URL.Path == "/polls":
+			if r.URL.Query().Get("id") != "p1" {
+				t.Errorf("query = %v", r.URL.Query())
+			}
+			_ = json.NewEncoder(w).Encode(Response[Poll]{Data: []Poll{{ID: "p1", BroadcasterID: "1234", Status: PollStatusCompleted}}})
+		case r.URL.Path == "/predictions":
+			_ = json.NewEncoder(w).Encode(Response[Prediction]{Data: []Prediction{{ID: "pr1", BroadcasterID: "1234", Status: PredictionStatusResolved}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	m := NewPollPredictionManager(client, "1234")
+	ctx := context.Background()
+	poll, err := m.StartPoll(ctx, &CreatePollParams{Title: "Best map?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.StartPrediction(ctx, &CreatePredictionParams{Title: "Win?"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if poll.Snapshot().Status != PollStatusCompleted {
+		t.Errorf("poll status = %s", poll.Snapshot().Status)
+	}
+	if len(m.Polls()) != 0 || len(m.Predictions()) != 0 {
+		t.Error("manager still tracking after Close")
+	}
+}
@@ -0,0 +1,629 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default RaidShield settings
+const (
+	defaultRaidShieldWindow        = time.Minute
+	defaultRaidShieldFirstMessages = 10
+	defaultRaidShieldNewAccounts   = 5
+	defaultRaidShieldAccountAge    = 7 * 24 * time.Hour
+	defaultRaidShieldCooldown      = 10 * time.Minute
+	defaultRaidShieldFollowersOnly = 10 // minutes
+	defaultRaidShieldRaidWatch     = 5 * time.Minute
+	raidShieldActionTimeout        = 30 * time.Second
+)
+
+// RaidShieldReason is why a RaidShield activated.
+type RaidShieldReason string
+
+// RaidShield activation reasons
+const (
+	RaidShieldReasonRaid          RaidShieldReason = "raid"           // Incoming raid at or above the viewer threshold
+	RaidShieldReasonFirstMessages RaidShieldReason = "first_messages" // Burst of first-time chatters
+	RaidShieldReasonNewAccounts   RaidShieldReason = "new_accounts"   // Burst of recently created accounts
+	RaidShieldReasonManual        RaidShieldReason = "manual"         // Trigger was called
+)
+
+// RaidShieldTrigger describes an activation of a RaidShield.
+type RaidShieldTrigger struct {
+	Reason        RaidShieldReason
+	FirstMessages int               // First messages in the window when triggered
+	NewAccounts   int               // New-account chatters in the window when triggered
+	Raid          *ChannelRaidEvent // Most recent raid within the raid watch period, if any
+	At            time.Time
+}
+
+// RaidShield watches a channel for hate-raid patterns and locks chat down when
+// they appear. It counts first-time chatters and chatters with recently
+// created accounts over a sliding window; when a threshold trips (or a large
+// enough raid arrives) it enables Shield Mode, followers-only and slow mode,
+// and optionally bans the new accounts. After a cool-down with no further
+// trips, it restores the previous chat settings and Shield Mode state.
+//
+// Events come from an EventSubWebSocket via Start, or can be fed directly
+// with HandleEvent, HandleRaid and HandleChatMessage, e.g. to replay recorded
+// events in tests.
+type RaidShield struct {
+	client        *Client
+	broadcasterID string
+	moderatorID   string
+
+	// Configuration
+	window         time.Duration
+	firstMessages  int
+	newAccounts    int
+	accountAge     time.Duration
+	raidViewers    int
+	raidWatch      time.Duration
+	cooldown       time.Duration
+	shieldMode     bool
+	followersOnly  int // minutes, -1 = leave unchanged
+	slowMode       int // seconds, 0 = leave unchanged
+	banNewAccounts bool
+	banReason      string
+	onTrigger      func(RaidShieldTrigger)
+	onRevert       func()
+	onError        func(error)
+
+	mu          sync.Mutex
+	active      bool
+	saved       *ChatSettings // Settings before activation
+	shieldOn    bool          // Shield Mode was turned on by the shield
+	revertTimer *time.Timer
+	recent      []raidShieldChatter
+	accounts    map[string]time.Time // user ID -> account creation date
+	banned      map[string]bool
+	lastRaid    *ChannelRaidEvent
+	lastRaidAt  time.Time
+}
+
+// raidShieldChatter is a message counted in the sliding window.
+type raidShieldChatter struct {
+	at           time.Time
+	userID       string
+	firstMessage bool
+	newAccount   bool
+}
+
+// RaidShieldOption configures a RaidShield.
+type RaidShieldOption func(*RaidShield)
+
+// WithRaidShieldWindow sets the sliding window over which chatters are counted (default 1m).
+func WithRaidShieldWindow(d time.Duration) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.window = d
+	}
+}
+
+// WithRaidShieldFirstMessageThreshold sets how many first-time chatters within
+// the window trip the shield (default 10, 0 disables).
+func WithRaidShieldFirstMessageThreshold(n int) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.firstMessages = n
+	}
+}
+
+// WithRaidShieldNewAccountThreshold sets how many chatters with accounts
+// younger than maxAge within the window trip the shield (default 5 accounts
+// younger than 7 days). A count of 0 disables account age checks.
+func WithRaidShieldNewAccountThreshold(n int, maxAge time.Duration) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.newAccounts = n
+		s.accountAge = maxAge
+	}
+}
+
+// WithRaidShieldRaidThreshold makes an incoming raid with at least the given
+// number of viewers trip the shield on its own (default 0, disabled).
+func WithRaidShieldRaidThreshold(viewers int) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.raidViewers = viewers
+	}
+}
+
+// WithRaidShieldCooldown sets how long after the last trip the shield stays
+// active before reverting (default 10m).
+func WithRaidShieldCooldown(d time.Duration) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.cooldown = d
+	}
+}
+
+// WithRaidShieldShieldMode sets whether Shield Mode is enabled on activation (default true).
+func WithRaidShieldShieldMode(enabled bool) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.shieldMode = enabled
+	}
+}
+
+// WithRaidShieldFollowersOnly sets the followers-only duration in minutes
+// applied on activation (default 10, -1 leaves followers-only unchanged).
+func WithRaidShieldFollowersOnly(minutes int) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.followersOnly = minutes
+	}
+}
+
+// WithRaidShieldSlowMode sets the slow mode wait time in seconds applied on
+// activation (default 0, leaves slow mode unchanged).
+func WithRaidShieldSlowMode(seconds int) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.slowMode = seconds
+	}
+}
+
+// WithRaidShieldBanNewAccounts bans chatters with new accounts on activation,
+// and any that chat while the shield is active, with the given reason.
+func WithRaidShieldBanNewAccounts(reason string) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.banNewAccounts = true
+		s.banReason = reason
+	}
+}
+
+// WithRaidShieldTriggerHandler sets the handler called when the shield activates.
+func WithRaidShieldTriggerHandler(fn func(RaidShieldTrigger)) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.onTrigger = fn
+	}
+}
+
+// WithRaidShieldRevertHandler sets the handler called when the shield reverts.
+func WithRaidShieldRevertHandler(fn func()) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.onRevert = fn
+	}
+}
+
+// WithRaidShieldErrorHandler sets the handler for errors from event parsing,
+// account lookups and moderation actions.
+func WithRaidShieldErrorHandler(fn func(error)) RaidShieldOption {
+	return func(s *RaidShield) {
+		s.onError = fn
+	}
+}
+
+// NewRaidShield creates a RaidShield for a channel. The moderator's token must
+// have the moderator:manage:shield_mode, moderator:manage:chat_settings and,
+// when banning, moderator:manage:banned_users scopes.
+func NewRaidShield(client *Client, broadcasterID, moderatorID string, opts ...RaidShieldOption) *RaidShield {
+	s := &RaidShield{
+		client:        client,
+		broadcasterID: broadcasterID,
+		moderatorID:   moderatorID,
+		window:        defaultRaidShieldWindow,
+		firstMessages: defaultRaidShieldFirstMessages,
+		newAccounts:   defaultRaidShieldNewAccounts,
+		accountAge:    defaultRaidShieldAccountAge,
+		raidWatch:     defaultRaidShieldRaidWatch,
+		cooldown:      defaultRaidShieldCooldown,
+		shieldMode:    true,
+		followersOnly: defaultRaidShieldFollowersOnly,
+		accounts:      make(map[string]time.Time),
+		banned:        make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start subscribes to channel.raid and channel.chat.message on a connected
+// EventSubWebSocket and feeds the events to the shield. The chat subscription
+// is created for the moderator, whose token must have the user:read:chat scope.
+func (s *RaidShield) Start(ctx context.Context, ws *EventSubWebSocket) error {
+	if err := ws.Subscribe(ctx, EventSubTypeChannelRaid, GetEventSubVersion(EventSubTypeChannelRaid),
+		FromToBroadcasterCondition("", s.broadcasterID), s.eventHandler(EventSubTypeChannelRaid)); err != nil {
+		return fmt.Errorf("subscribing to %s: %w", EventSubTypeChannelRaid, err)
+	}
+	if err := ws.Subscribe(ctx, EventSubTypeChannelChatMessage, GetEventSubVersion(EventSubTypeChannelChatMessage),
+		BroadcasterUserCondition(s.broadcasterID, s.moderatorID), s.eventHandler(EventSubTypeChannelChatMessage)); err != nil {
+		return fmt.Errorf("subscribing to %s: %w", EventSubTypeChannelChatMessage, err)
+	}
+	return nil
+}
+
+// eventHandler returns the notification handler for an event type.
+func (s *RaidShield) eventHandler(eventType string) func(json.RawMessage) {
+	return func(data json.RawMessage) {
+		if err := s.HandleEvent(context.Background(), eventType, data); err != nil {
+			s.handleError(err)
+		}
+	}
+}
+
+// HandleEvent processes a raw channel.raid or channel.chat.message event.
+// Other event types are ignored.
+func (s *RaidShield) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	switch eventType {
+	case EventSubTypeChannelRaid:
+		event, err := ParseWSEvent[ChannelRaidEvent](data)
+		if err != nil {
+			return err
+		}
+		return s.HandleRaid(ctx, event)
+	case EventSubTypeChannelChatMessage:
+		event, err := ParseWSEvent[ChannelChatMessageEvent](data)
+		if err != nil {
+			return err
+		}
+		return s.HandleChatMessage(ctx, chatMessageFromEvent(event, string(data), time.Now()))
+	}
+	return nil
+}
+
+// HandleRaid processes an incoming raid. Raids to other channels are ignored.
+func (s *RaidShield) HandleRaid(ctx context.Context, event *ChannelRaidEvent) error {
+	if event.ToBroadcasterUserID != "" && event.ToBroadcasterUserID != s.broadcasterID {
+		return nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.lastRaid = event
+	s.lastRaidAt = now
+	trip := s.raidViewers > 0 && event.Viewers >= s.raidViewers
+	s.mu.Unlock()
+
+	if !trip {
+		return nil
+	}
+	return s.trip(ctx, RaidShieldReasonRaid, now)
+}
+
+// HandleChatMessage processes a chat message from an IRC or EventSub chat
+// client. Messages from other channels, and from the broadcaster, moderators
+// and VIPs, are ignored.
+func (s *RaidShield) HandleChatMessage(ctx context.Context, msg *ChatMessage) error {
+	if (msg.RoomID != "" && msg.RoomID != s.broadcasterID) || msg.UserID == "" {
+		return nil
+	}
+	if msg.IsBroadcaster || msg.IsMod || msg.IsVIP {
+		return nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	raiding := s.lastRaid != nil && now.Sub(s.lastRaidAt) < s.raidWatch
+	s.mu.Unlock()
+
+	// Account ages are only looked up for first-time chatters and for
+	// chatters right after a raid, to keep API usage down
+	var errs []error
+	newAccount := false
+	if s.newAccounts > 0 && s.accountAge > 0 && (msg.FirstMessage || raiding) {
+		created, err := s.accountCreated(ctx, msg.UserID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("looking up account age of %s: %w", msg.UserID, err))
+		} else {
+			newAccount = now.Sub(created) < s.accountAge
+		}
+	}
+
+	s.mu.Lock()
+	s.recent = append(s.recent, raidShieldChatter{
+		at:           now,
+		userID:       msg.UserID,
+		firstMessage: msg.FirstMessage,
+		newAccount:   newAccount,
+	})
+	s.pruneLocked(now)
+	firstMessages, newAccounts := s.countsLocked()
+	active := s.active
+	s.mu.Unlock()
+
+	switch {
+	case s.firstMessages > 0 && firstMessages >= s.firstMessages:
+		errs = append(errs, s.trip(ctx, RaidShieldReasonFirstMessages, now))
+	case s.newAccounts > 0 && newAccounts >= s.newAccounts:
+		errs = append(errs, s.trip(ctx, RaidShieldReasonNewAccounts, now))
+	}
+	// On activation the new accounts in the window are banned by trip
+	if active && newAccount && s.banNewAccounts {
+		errs = append(errs, s.banUsers(ctx, []string{msg.UserID}))
+	}
+	return errors.Join(errs...)
+}
+
+// Trigger activates the shield manually. If it is already active, the
+// cool-down is restarted.
+func (s *RaidShield) Trigger(ctx context.Context) error {
+	return s.trip(ctx, RaidShieldReasonManual, time.Now())
+}
+
+// Active reports whether the shield is currently active.
+func (s *RaidShield) Active() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active
+}
+
+// Revert deactivates the shield immediately, restoring the chat settings
+// saved on activation and disabling Shield Mode if the shield enabled it.
+// It does nothing if the shield is not active.
+func (s *RaidShield) Revert(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.active {
+		s.mu.Unlock()
+		return nil
+	}
+	s.active = false
+	saved, shieldOn := s.saved, s.shieldOn
+	s.saved, s.shieldOn = nil, false
+	s.recent = nil
+	if s.revertTimer != nil {
+		s.revertTimer.Stop()
+		s.revertTimer = nil
+	}
+	s.mu.Unlock()
+
+	var errs []error
+	if shieldOn {
+		if _, err := s.client.UpdateShieldModeStatus(ctx, &UpdateShieldModeStatusParams{
+			BroadcasterID: s.broadcasterID,
+			ModeratorID:   s.moderatorID,
+			IsActive:      false,
+		}); err != nil {
+			errs = append(errs, fmt.Errorf("disabling shield mode: %w", err))
+		}
+	}
+	if saved != nil {
+		if params := s.restoreParams(saved); params != nil {
+			if _, err := s.client.UpdateChatSettings(ctx, params); err != nil {
+				errs = append(errs, fmt.Errorf("restoring chat settings: %w", err))
+			}
+		}
+	}
+
+	if s.onRevert != nil {
+		s.onRevert()
+	}
+	return errors.Join(errs...)
+}
+
+// Close stops the cool-down timer without reverting. Call Revert first to
+// restore the channel's settings.
+func (s *RaidShield) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revertTimer != nil {
+		s.revertTimer.Stop()
+		s.revertTimer = nil
+	}
+}
+
+// trip activates the shield, or restarts the cool-down if already active.
+func (s *RaidShield) trip(ctx context.Context, reason RaidShieldReason, now time.Time) error {
+	s.mu.Lock()
+	s.scheduleRevertLocked()
+	if s.active {
+		s.mu.Unlock()
+		return nil
+	}
+	s.active = true
+	firstMessages, newAccounts := s.countsLocked()
+	trigger := RaidShieldTrigger{
+		Reason:        reason,
+		FirstMessages: firstMessages,
+		NewAccounts:   newAccounts,
+		At:            now,
+	}
+	if s.lastRaid != nil && now.Sub(s.lastRaidAt) < s.raidWatch {
+		trigger.Raid = s.lastRaid
+	}
+	var suspects []string
+	if s.banNewAccounts {
+		for _, c := range s.recent {
+			if c.newAccount {
+				suspects = append(suspects, c.userID)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	if s.onTrigger != nil {
+		s.onTrigger(trigger)
+	}
+
+	var errs []error
+	if s.shieldMode {
+		// Shield Mode already on, e.g. enabled by a moderator, stays on after
+		// Revert. So does Shield Mode whose state couldn't be read.
+		status, err := s.client.GetShieldModeStatus(ctx, s.broadcasterID, s.moderatorID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("getting shield mode status: %w", err))
+		}
+		if status == nil || !status.IsActive {
+			if _, err := s.client.UpdateShieldModeStatus(ctx, &UpdateShieldModeStatusParams{
+				BroadcasterID: s.broadcasterID,
+				ModeratorID:   s.moderatorID,
+				IsActive:      true,
+			}); err != nil {
+				errs = append(errs, fmt.Errorf("enabling shield mode: %w", err))
+			} else if status != nil {
+				s.mu.Lock()
+				s.shieldOn = true
+				s.mu.Unlock()
+			}
+		}
+	}
+
+	// Chat settings are only changed if they can be restored
+	if params := s.lockdownParams(); params != nil {
+		settings, err := s.client.GetChatSettings(ctx, s.broadcasterID, s.moderatorID)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("getting chat settings, leaving them unchanged: %w", err))
+		case len(settings.Data) == 0:
+			errs = append(errs, errors.New("getting chat settings, leaving them unchanged: no settings returned"))
+		default:
+			s.mu.Lock()
+			s.saved = &settings.Data[0]
+			s.mu.Unlock()
+			if _, err := s.client.UpdateChatSettings(ctx, params); err != nil {
+				errs = append(errs, fmt.Errorf("updating chat settings: %w", err))
+			}
+		}
+	}
+
+	if len(suspects) > 0 {
+		errs = append(errs, s.banUsers(ctx, suspects))
+	}
+
+	return errors.Join(errs...)
+}
+
+// scheduleRevertLocked (re)starts the cool-down timer. s.mu must be held.
+func (s *RaidShield) scheduleRevertLocked() {
+	if s.revertTimer != nil {
+		s.revertTimer.Stop()
+	}
+	s.revertTimer = time.AfterFunc(s.cooldown, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), raidShieldActionTimeout)
+		defer cancel()
+		if err := s.Revert(ctx); err != nil {
+			s.handleError(err)
+		}
+	})
+}
+
+// lockdownParams returns the chat settings applied on activation, or nil if
+// none are configured.
+func (s *RaidShield) lockdownParams() *UpdateChatSettingsParams {
+	if s.followersOnly < 0 && s.slowMode <= 0 {
+		return nil
+	}
+	params := &UpdateChatSettingsParams{
+		BroadcasterID: s.broadcasterID,
+		ModeratorID:   s.moderatorID,
+	}
+	if s.followersOnly >= 0 {
+		enabled, minutes := true, s.followersOnly
+		params.FollowerMode = &enabled
+		params.FollowerModeDuration = &minutes
+	}
+	if s.slowMode > 0 {
+		enabled, seconds := true, s.slowMode
+		params.SlowMode = &enabled
+		params.SlowModeWaitTime = &seconds
+	}
+	return params
+}
+
+// restoreParams returns the chat settings that undo lockdownParams, or nil if
+// nothing was changed.
+func (s *RaidShield) restoreParams(saved *ChatSettings) *UpdateChatSettingsParams {
+	if s.followersOnly < 0 && s.slowMode <= 0 {
+		return nil
+	}
+	params := &UpdateChatSettingsParams{
+		BroadcasterID: s.broadcasterID,
+		ModeratorID:   s.moderatorID,
+	}
+	if s.followersOnly >= 0 {
+		enabled := saved.FollowerMode
+		params.FollowerMode = &enabled
+		if enabled {
+			minutes := saved.FollowerModeDuration
+			params.FollowerModeDuration = &minutes
+		}
+	}
+	if s.slowMode > 0 {
+		enabled := saved.SlowMode
+		params.SlowMode = &enabled
+		if enabled {
+			seconds := saved.SlowModeWaitTime
+			params.SlowModeWaitTime = &seconds
+		}
+	}
+	return params
+}
+
+// banUsers bans users not already banned by this shield.
+func (s *RaidShield) banUsers(ctx context.Context, userIDs []string) error {
+	s.mu.Lock()
+	var users []BanUserData
+	for _, id := range userIDs {
+		if !s.banned[id] {
+			s.banned[id] = true
+			users = append(users, BanUserData{UserID: id, Reason: s.banReason})
+		}
+	}
+	s.mu.Unlock()
+
+	if len(users) == 0 {
+		return nil
+	}
+	return s.client.BanUsers(ctx, s.broadcasterID, s.moderatorID, users, nil).Err()
+}
+
+// accountCreated returns a user's account creation date, cached per user.
+func (s *RaidShield) accountCreated(ctx context.Context, userID string) (time.Time, error) {
+	s.mu.Lock()
+	created, ok := s.accounts[userID]
+	s.mu.Unlock()
+	if ok {
+		return created, nil
+	}
+
+	created, err := s.lookupAccountCreated(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.mu.Lock()
+	s.accounts[userID] = created
+	s.mu.Unlock()
+	return created, nil
+}
+
+// lookupAccountCreated fetches a user's creation date with Get Users.
+func (s *RaidShield) lookupAccountCreated(ctx context.Context, userID string) (time.Time, error) {
+	resp, err := s.client.GetUsers(ctx, &GetUsersParams{IDs: []string{userID}})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(resp.Data) == 0 {
+		return time.Time{}, fmt.Errorf("user %s not found", userID)
+	}
+	return resp.Data[0].CreatedAt, nil
+}
+
+// pruneLocked drops chatters outside the window. s.mu must be held.
+func (s *RaidShield) pruneLocked(now time.Time) {
+	cutoff := now.Add(-s.window)
+	i := 0
+	for i < len(s.recent) && !s.recent[i].at.After(cutoff) {
+		i++
+	}
+	s.recent = s.recent[i:]
+}
+
+// countsLocked returns the distinct first-time and new-account chatters in
+// the window. s.mu must be held.
+func (s *RaidShield) countsLocked() (firstMessages, newAccounts int) {
+	first := make(map[string]bool)
+	fresh := make(map[string]bool)
+	for _, c := range s.recent {
+		if c.firstMessage {
+			first[c.userID] = true
+		}
+		if c.newAccount {
+			fresh[c.userID] = true
+		}
+	}
+	return len(first), len(fresh)
+}
+
+func (s *RaidShield) handleError(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
@@ -0,0 +1,252 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// raidShieldServer is a fake Helix API that records the moderation calls made
+// by a RaidShield.
+type raidShieldServer struct {
+	mu       sync.Mutex
+	calls    []string
+	banned   []string
+	settings []UpdateChatSettingsParams
+
+	shieldActive bool // Shield Mode is on before the shield trips
+	settingsDown bool // Get Chat Settings fails
+}
+
+func (f *raidShieldServer) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/users":
+			// Users named "new*" were created an hour ago, everyone else years ago
+			id := r.URL.Query().Get("id")
+			created := time.Now().AddDate(-5, 0, 0)
+			if strings.HasPrefix(id, "new") {
+				created = time.Now().Add(-time.Hour)
+			}
+			_ = json.NewEncoder(w).Encode(Response[User]{Data: []User{{ID: id, CreatedAt: created}}})
+			return
+		case r.URL.Path == "/chat/settings" && r.Method == http.MethodGet:
+			f.calls = append(f.calls, "get settings")
+			if f.settingsDown {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Response[ChatSettings]{Data: []ChatSettings{{SlowMode: true, SlowModeWaitTime: 5}}})
+			return
+		case r.URL.Path == "/chat/settings":
+			var params UpdateChatSettingsParams
+			_ = json.Unmarshal(body, &params)
+			f.settings = append(f.settings, params)
+			f.calls = append(f.calls, "update settings")
+		case r.URL.Path == "/moderation/shield_mode" && r.Method == http.MethodGet:
+			f.calls = append(f.calls, "get shield")
+			_ = json.NewEncoder(w).Encode(Response[ShieldModeStatus]{Data: []ShieldModeStatus{{IsActive: f.shieldActive}}})
+			return
+		case r.URL.Path == "/moderation/shield_mode":
+			var params UpdateShieldModeStatusParams
+			_ = json.Unmarshal(body, &params)
+			f.calls = append(f.calls, fmt.Sprintf("shield %v", params.IsActive))
+		case r.URL.Path == "/moderation/bans":
+			var params struct {
+				Data BanUserData `json:"data"`
+			}
+			_ = json.Unmarshal(body, &params)
+			f.banned = append(f.banned, params.Data.UserID)
+			f.calls = append(f.calls, "ban")
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"data":[{}]}`))
+	}
+}
+
+func (f *raidShieldServer) snapshot() (calls, banned []string, settings []UpdateChatSettingsParams) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...), append([]string(nil), f.banned...), append([]UpdateChatSettingsParams(nil), f.settings...)
+}
+
+func TestRaidShield_NewAccountBurst(t *testing.T) {
+	fake := &raidShieldServer{}
+	client, server := newTestClient(fake.handler(t))
+	defer server.Close()
+
+	var triggers []RaidShieldTrigger
+	reverted := make(chan struct{})
+	shield := NewRaidShield(client, "1234", "5678",
+		WithRaidShieldFirstMessageThreshold(0),
+		WithRaidShieldNewAccountThreshold(3, 24*time.Hour),
+		WithRaidShieldSlowMode(30),
+		WithRaidShieldBanNewAccounts("hate raid"),
+		WithRaidShieldCooldown(100*time.Millisecond),
+		WithRaidShieldTriggerHandler(func(tr RaidShieldTrigger) { triggers = append(triggers, tr) }),
+		WithRaidShieldRevertHandler(func() { close(reverted) }),
+	)
+	defer shield.Close()
+	ctx := context.Background()
+
+	// Replay recorded chat events: an established chatter, then a burst of new accounts
+	for _, user := range []string{"old1", "new1", "new2", "new3", "new4"} {
+		data := fmt.Sprintf(`{"broadcaster_user_id":"1234","broadcaster_user_login":"chan","chatter_user_id":%q,"chatter_user_login":%q,"message_id":"m-%s","message":{"text":"hi","fragments":[]},"message_type":"user_intro"}`, user, user, user)
+		if err := shield.HandleEvent(ctx, EventSubTypeChannelChatMessage, json.RawMessage(data)); err != nil {
+			t.Fatalf("HandleEvent(%s) error = %v", user, err)
+		}
+	}
+
+	if !shield.Active() {
+		t.Fatal("shield not active after burst")
+	}
+	if len(triggers) != 1 || triggers[0].Reason != RaidShieldReasonNewAccounts || triggers[0].NewAccounts != 3 {
+		t.Fatalf("triggers = %+v", triggers)
+	}
+
+	calls, banned, settings := fake.snapshot()
+	slices.Sort(banned)
+	if strings.Join(banned, ",") != "new1,new2,new3,new4" {
+		t.Errorf("banned = %v", banned)
+	}
+	if len(settings) != 1 || settings[0].FollowerModeDuration == nil || *settings[0].FollowerModeDuration != 10 ||
+		settings[0].SlowModeWaitTime == nil || *settings[0].SlowModeWaitTime != 30 {
+		t.Errorf("lockdown settings = %+v", settings)
+	}
+	if strings.Join(calls[:3], ",") != "get shield,shield true,get settings" {
+		t.Errorf("calls = %v", calls)
+	}
+
+	select {
+	case <-reverted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("shield did not revert after cool-down")
+	}
+	if shield.Active() {
+		t.Error("shield still active after revert")
+	}
+
+	calls, _, settings = fake.snapshot()
+	if calls[len(calls)-2] != "shield false" {
+		t.Errorf("calls = %v", calls)
+	}
+	restore := settings[len(settings)-1]
+	if restore.FollowerMode == nil || *restore.FollowerMode || restore.SlowModeWaitTime == nil || *restore.SlowModeWaitTime != 5 {
+		t.Errorf("restore settings = %+v", restore)
+	}
+}
+
+func TestRaidShield_RaidThreshold(t *testing.T) {
+	fake := &raidShieldServer{}
+	client, server := newTestClient(fake.handler(t))
+	defer server.Close()
+
+	var trigger RaidShieldTrigger
+	shield := NewRaidShield(client, "1234", "5678",
+		WithRaidShieldRaidThreshold(100),
+		WithRaidShieldFollowersOnly(-1),
+		WithRaidShieldTriggerHandler(func(tr RaidShieldTrigger) { trigger = tr }),
+	)
+	defer shield.Close()
+	ctx := context.Background()
+
+	small := `{"from_broadcaster_user_id":"1","from_broadcaster_user_login":"friend","to_broadcaster_user_id":"1234","viewers":20}`
+	if err := shield.HandleEvent(ctx, EventSubTypeChannelRaid, json.RawMessage(small)); err != nil {
+		t.Fatal(err)
+	}
+	if shield.Active() {
+		t.Fatal("small raid activated the shield")
+	}
+
+	large := `{"from_broadcaster_user_id":"2","from_broadcaster_user_login":"raider","to_broadcaster_user_id":"1234","viewers":500}`
+	if err := shield.HandleEvent(ctx, EventSubTypeChannelRaid, json.RawMessage(large)); err != nil {
+		t.Fatal(err)
+	}
+	if !shield.Active() || trigger.Reason != RaidShieldReasonRaid || trigger.Raid == nil || trigger.Raid.FromBroadcasterUserLogin != "raider" {
+		t.Fatalf("trigger = %+v", trigger)
+	}
+
+	_, _, settings := fake.snapshot()
+	if len(settings) != 0 {
+		t.Errorf("settings updated with followers-only disabled: %+v", settings)
+	}
+
+	if err := shield.Revert(ctx); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+	calls, _, _ := fake.snapshot()
+	if strings.Join(calls, ",") != "get shield,shield true,shield false" {
+		t.Errorf("calls = %v", calls)
+	}
+}
+
+func TestRaidShield_FirstMessages(t *testing.T) {
+	fake := &raidShieldServer{}
+	client, server := newTestClient(fake.handler(t))
+	defer server.Close()
+
+	shield := NewRaidShield(client, "1234", "5678",
+		WithRaidShieldFirstMessageThreshold(3),
+		WithRaidShieldNewAccountThreshold(0, 0),
+	)
+	defer shield.Close()
+	ctx := context.Background()
+
+	msgs := []*ChatMessage{
+		{RoomID: "1234", UserID: "a", FirstMessage: true},
+		{RoomID: "1234", UserID: "a", FirstMessage: true}, // same chatter counted once
+		{RoomID: "1234", UserID: "mod", FirstMessage: true, IsMod: true},
+		{RoomID: "9999", UserID: "b", FirstMessage: true},
+		{RoomID: "1234", UserID: "c", FirstMessage: true},
+	}
+	for _, msg := range msgs {
+		if err := shield.HandleChatMessage(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if shield.Active() {
+		t.Fatal("shield active below threshold")
+	}
+
+	if err := shield.HandleChatMessage(ctx, &ChatMessage{RoomID: "1234", UserID: "d", FirstMessage: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !shield.Active() {
+		t.Fatal("shield not active at threshold")
+	}
+}
+
+func TestRaidShield_RestoresPriorState(t *testing.T) {
+	// A moderator already turned Shield Mode on, and chat settings can't be read
+	fake := &raidShieldServer{shieldActive: true, settingsDown: true}
+	client, server := newTestClient(fake.handler(t))
+	defer server.Close()
+
+	shield := NewRaidShield(client, "1234", "5678", WithRaidShieldSlowMode(30))
+	defer shield.Close()
+	ctx := context.Background()
+
+	if err := shield.Trigger(ctx); err == nil || !strings.Contains(err.Error(), "leaving them unchanged") {
+		t.Errorf("Trigger() error = %v", err)
+	}
+	if err := shield.Revert(ctx); err != nil {
+		t.Fatalf("Revert() error = %v", err)
+	}
+
+	calls, _, settings := fake.snapshot()
+	if strings.Join(calls, ",") != "get shield,get settings" || len(settings) != 0 {
+		t.Errorf("calls = %v, settings = %+v", calls, settings)
+	}
+}
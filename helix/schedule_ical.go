@@ -0,0 +1,700 @@
+package helix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iCalendar date and date-time layouts
+const (
+	icalDateTimeLayout = "20060102T150405"
+	icalDateLayout     = "20060102"
+)
+
+// maxRecurrencePeriods bounds recurrence expansion of rules that never match.
+const maxRecurrencePeriods = 100000
+
+// ICalendar is a parsed iCalendar (RFC 5545) feed, such as the one returned
+// by GetChannelICalendar.
+type ICalendar struct {
+	Name   string // NAME or X-WR-CALNAME
+	Events []ICalEvent
+}
+
+// ICalEvent is a VEVENT. Times carry the event's time zone, so recurring
+// events keep their local time across DST changes.
+type ICalEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool            // DTSTART is a date
+	RRule       *ICalRecurrence // Nil for one-off events
+	ExDates     []time.Time     // Excluded occurrence starts
+	Canceled    bool            // STATUS:CANCELLED
+}
+
+// ICalRecurrence is an RRULE. Only FREQ, INTERVAL, COUNT, UNTIL, and BYDAY
+// (with DAILY or WEEKLY) are supported, which covers the weekly rules Twitch
+// uses. Rules with other parts, such as BYMONTHDAY or BYSETPOS, are rejected
+// rather than expanded wrongly.
+type ICalRecurrence struct {
+	Freq     string // DAILY, WEEKLY, MONTHLY, or YEARLY
+	Interval int    // 0 means 1
+	Count    int    // 0 means unlimited
+	Until    time.Time
+	ByDay    []time.Weekday
+}
+
+// ICalOccurrence is one concrete occurrence of an event.
+type ICalOccurrence struct {
+	Event *ICalEvent
+	Start time.Time
+	End   time.Time
+}
+
+// icalWeekdays maps RRULE day codes to weekdays.
+var icalWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// icalWeekdayCode returns the RRULE day code for wd.
+func icalWeekdayCode(wd time.Weekday) string {
+	for code, d := range icalWeekdays {
+		if d == wd {
+			return code
+		}
+	}
+	return ""
+}
+
+// icalProperty is a content line: NAME;PARAM=VALUE:value.
+type icalProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// GetChannelCalendar gets a channel's stream schedule as iCalendar and
+// parses it.
+func (c *Client) GetChannelCalendar(ctx context.Context, broadcasterID string) (*ICalendar, error) {
+	feed, err := c.GetChannelICalendar(ctx, broadcasterID)
+	if err != nil {
+		return nil, err
+	}
+	return ParseICalendar(strings.NewReader(feed))
+}
+
+// ParseICalendar parses an iCalendar feed. Times with a TZID are loaded from
+// the IANA time zone database, ignoring any VTIMEZONE definitions. Floating
+// times are read as UTC.
+func ParseICalendar(r io.Reader) (*ICalendar, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ICalendar{}
+	var event *ICalEvent
+	var rrule, duration string // Parsed once DTSTART is known
+	var depth []string
+	for n, line := range lines {
+		prop, err := parseICalProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("ical line %d: %w", n+1, err)
+		}
+		switch prop.name {
+		case "BEGIN":
+			depth = append(depth, strings.ToUpper(prop.value))
+			if strings.EqualFold(prop.value, "VEVENT") {
+				event = &ICalEvent{}
+				rrule, duration = "", ""
+			}
+			continue
+		case "END":
+			if len(depth) == 0 || !strings.EqualFold(depth[len(depth)-1], prop.value) {
+				return nil, fmt.Errorf("ical line %d: unexpected END:%s", n+1, prop.value)
+			}
+			depth = depth[:len(depth)-1]
+			if strings.EqualFold(prop.value, "VEVENT") {
+				if event.Start.IsZero() {
+					return nil, fmt.Errorf("ical event %q: missing DTSTART", event.UID)
+				}
+				if duration != "" && event.End.IsZero() {
+					d, err := parseICalDuration(duration)
+					if err != nil {
+						return nil, fmt.Errorf("ical event %q: %w", event.UID, err)
+					}
+					event.End = event.Start.Add(d)
+				}
+				if rrule != "" {
+					if event.RRule, err = parseICalRecurrence(rrule, event.Start.Location()); err != nil {
+						return nil, fmt.Errorf("ical event %q: %w", event.UID, err)
+					}
+				}
+				if event.End.IsZero() {
+					event.End = event.Start
+				}
+				cal.Events = append(cal.Events, *event)
+				event = nil
+			}
+			continue
+		}
+
+		switch {
+		case event != nil && depth[len(depth)-1] == "VEVENT" && prop.name == "RRULE":
+			rrule = prop.value
+		case event != nil && depth[len(depth)-1] == "VEVENT" && prop.name == "DURATION":
+			duration = prop.value
+		case event != nil && depth[len(depth)-1] == "VEVENT":
+			if err := event.setProperty(prop); err != nil {
+				return nil, fmt.Errorf("ical line %d: %w", n+1, err)
+			}
+		case len(depth) == 1 && (prop.name == "NAME" || prop.name == "X-WR-CALNAME"):
+			cal.Name = unescapeICalText(prop.value)
+		}
+	}
+	if len(depth) > 0 {
+		return nil, fmt.Errorf("ical: unterminated %s", depth[len(depth)-1])
+	}
+	return cal, nil
+}
+
+// unfoldICalLines reads content lines, joining folded continuation lines.
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// parseICalProperty splits a content line into name, parameters, and value.
+func parseICalProperty(line string) (icalProperty, error) {
+	inQuote := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icalProperty{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := icalProperty{name: strings.ToUpper(parts[0]), value: line[colon+1:]}
+	for _, p := range parts[1:] {
+		if k, v, ok := strings.Cut(p, "="); ok {
+			if prop.params == nil {
+				prop.params = make(map[string]string)
+			}
+			prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return prop, nil
+}
+
+// setProperty applies a VEVENT property.
+func (e *ICalEvent) setProperty(prop icalProperty) error {
+	var err error
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescapeICalText(prop.value)
+	case "DESCRIPTION":
+		e.Description = unescapeICalText(prop.value)
+	case "CATEGORIES":
+		for c := range strings.SplitSeq(prop.value, ",") {
+			if c = unescapeICalText(strings.TrimSpace(c)); c != "" {
+				e.Categories = append(e.Categories, c)
+			}
+		}
+	case "STATUS":
+		e.Canceled = strings.EqualFold(prop.value, "CANCELLED")
+	case "DTSTART":
+		e.Start, e.AllDay, err = parseICalTime(prop.value, prop.params)
+	case "DTEND":
+		e.End, _, err = parseICalTime(prop.value, prop.params)
+	case "EXDATE":
+		for v := range strings.SplitSeq(prop.value, ",") {
+			t, _, err := parseICalTime(v, prop.params)
+			if err != nil {
+				return err
+			}
+			e.ExDates = append(e.ExDates, t)
+		}
+	}
+	return err
+}
+
+// parseICalTime parses a DATE or DATE-TIME value, in UTC, its TZID, or UTC
+// for floating times. It reports whether the value is a date.
+func parseICalTime(value string, params map[string]string) (time.Time, bool, error) {
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" {
+		var err error
+		if loc, err = loadICalLocation(tzid); err != nil {
+			return time.Time{}, false, err
+		}
+	}
+	if params["VALUE"] == "DATE" || len(value) == len(icalDateLayout) {
+		t, err := time.ParseInLocation(icalDateLayout, value, loc)
+		return t, true, err
+	}
+	if v, ok := strings.CutSuffix(value, "Z"); ok {
+		t, err := time.ParseInLocation(icalDateTimeLayout, v, time.UTC)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation(icalDateTimeLayout, value, loc)
+	return t, false, err
+}
+
+// loadICalLocation loads a TZID. Twitch writes TZIDs with a leading slash
+// and some calendars prefix them with a vendor path, so the longest suffix
+// that is an IANA name is used.
+func loadICalLocation(tzid string) (*time.Location, error) {
+	parts := strings.Split(strings.Trim(tzid, "/"), "/")
+	for i := range parts {
+		if loc, err := time.LoadLocation(strings.Join(parts[i:], "/")); err == nil {
+			return loc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown time zone %q", tzid)
+}
+
+// parseICalDuration parses a DURATION value such as PT1H30M or P1D.
+func parseICalDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(value, "+")
+	sign := time.Duration(1)
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		s, sign = rest, -1
+	}
+	s, ok := strings.CutPrefix(s, "P")
+	if !ok {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			num += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		num = ""
+		switch {
+		case r == 'W' && !inTime:
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	}
+	if num != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return sign * d, nil
+}
+
+// parseICalRecurrence parses an RRULE value. Floating UNTIL times are read in
+// loc, and an UNTIL date includes the whole day.
+func parseICalRecurrence(value string, loc *time.Location) (*ICalRecurrence, error) {
+	rule := &ICalRecurrence{}
+	for part := range strings.SplitSeq(value, ";") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(v)
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(v)
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(v)
+		case "UNTIL":
+			var allDay bool
+			if rule.Until, allDay, err = parseICalTime(v, nil); err == nil && !strings.HasSuffix(v, "Z") {
+				t := rule.Until
+				rule.Until = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+				if allDay {
+					rule.Until = rule.Until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+				}
+			}
+		case "BYDAY":
+			for day := range strings.SplitSeq(v, ",") {
+				wd, ok := icalWeekdays[strings.ToUpper(day)]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "WKST":
+			// Weeks are expanded from Monday, the default
+			if !strings.EqualFold(v, "MO") {
+				return nil, fmt.Errorf("unsupported RRULE WKST %q", v)
+			}
+		case "":
+			// Trailing semicolon
+		default:
+			return nil, fmt.Errorf("unsupported RRULE part %s", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid RRULE %s: %w", k, err)
+		}
+	}
+	switch rule.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported RRULE FREQ %q", rule.Freq)
+	}
+	if len(rule.ByDay) > 0 && (rule.Freq == "MONTHLY" || rule.Freq == "YEARLY") {
+		return nil, fmt.Errorf("unsupported RRULE BYDAY with FREQ %s", rule.Freq)
+	}
+	return rule, nil
+}
+
+// unescapeICalText reverses TEXT escaping.
+func unescapeICalText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteByte(s[i])
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// escapeICalText escapes TEXT values.
+func escapeICalText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(s)
+}
+
+// Occurrences returns the occurrences of the event that overlap [from, to),
+// in start order. Excluded dates and canceled events produce none.
+func (e *ICalEvent) Occurrences(from, to time.Time) []ICalOccurrence {
+	if e.Canceled {
+		return nil
+	}
+	dur := e.End.Sub(e.Start)
+	var occurrences []ICalOccurrence
+	add := func(start time.Time) {
+		end := start.Add(dur)
+		if start.Before(to) && (end.After(from) || (dur == 0 && !start.Before(from))) &&
+			!slices.ContainsFunc(e.ExDates, start.Equal) {
+			occurrences = append(occurrences, ICalOccurrence{Event: e, Start: start, End: end})
+		}
+	}
+	if e.RRule == nil {
+		add(e.Start)
+		return occurrences
+	}
+
+	n := 0
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		for _, start := range e.RRule.periodStarts(e.Start, period) {
+			if start.Before(e.Start) {
+				continue
+			}
+			if (!e.RRule.Until.IsZero() && start.After(e.RRule.Until)) ||
+				(e.RRule.Count > 0 && n >= e.RRule.Count) || !start.Before(to) {
+				return occurrences
+			}
+			n++
+			add(start)
+		}
+	}
+	return occurrences
+}
+
+// periodStarts returns the candidate starts in the nth period of the rule,
+// in order. Dates are stepped in the start's time zone, keeping its local
+// time across DST changes.
+func (r *ICalRecurrence) periodStarts(start time.Time, n int) []time.Time {
+	step := n * max(r.Interval, 1)
+	switch r.Freq {
+	case "DAILY":
+		t := start.AddDate(0, 0, step)
+		if len(r.ByDay) > 0 && !slices.Contains(r.ByDay, t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+	case "WEEKLY":
+		if len(r.ByDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*step)}
+		}
+		// Weeks start on Monday (WKST=MO)
+		monday := start.AddDate(0, 0, -mondayOffset(start.Weekday())+7*step)
+		days := slices.SortedFunc(slices.Values(r.ByDay), func(a, b time.Weekday) int {
+			return mondayOffset(a) - mondayOffset(b)
+		})
+		starts := make([]time.Time, 0, len(days))
+		for _, d := range slices.Compact(days) {
+			starts = append(starts, monday.AddDate(0, 0, mondayOffset(d)))
+		}
+		return starts
+	case "MONTHLY":
+		// Months without the start's day are skipped
+		if t := start.AddDate(0, step, 0); t.Day() == start.Day() {
+			return []time.Time{t}
+		}
+	case "YEARLY":
+		if t := start.AddDate(step, 0, 0); t.Day() == start.Day() {
+			return []time.Time{t}
+		}
+	}
+	return nil
+}
+
+// mondayOffset returns the number of days from Monday to d.
+func mondayOffset(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
+
+// Occurrences returns the occurrences of all events that overlap
+// [from, to), in start order.
+func (c *ICalendar) Occurrences(from, to time.Time) []ICalOccurrence {
+	var occurrences []ICalOccurrence
+	for i := range c.Events {
+		occurrences = append(occurrences, c.Events[i].Occurrences(from, to)...)
+	}
+	slices.SortStableFunc(occurrences, func(a, b ICalOccurrence) int {
+		return a.Start.Compare(b.Start)
+	})
+	return occurrences
+}
+
+// Segments returns the occurrences in [from, to) as schedule segments.
+func (c *ICalendar) Segments(from, to time.Time) []ScheduleSegment {
+	occurrences := c.Occurrences(from, to)
+	segments := make([]ScheduleSegment, len(occurrences))
+	for i, o := range occurrences {
+		segments[i] = o.Segment()
+	}
+	return segments
+}
+
+// Segment converts the occurrence to a schedule segment. The category has
+// only a name, from the first CATEGORIES value.
+//
+// Twitch segments can only repeat every week on one day, without end, so
+// only a WEEKLY rule with INTERVAL 1 and no COUNT or UNTIL makes a recurring
+// segment. The segment ID is the event UID, with "/" and the day code
+// appended when the rule has more than one BYDAY, so each weekday is its own
+// series. Occurrences of any other rule are single segments whose ID is the
+// UID, "/" and the UTC start time.
+func (o ICalOccurrence) Segment() ScheduleSegment {
+	s := ScheduleSegment{
+		ID:        o.Event.UID,
+		StartTime: o.Start,
+		EndTime:   o.End,
+		Title:     o.Event.Summary,
+	}
+	if r := o.Event.RRule; r != nil {
+		switch {
+		case r.Freq != "WEEKLY" || r.Interval > 1 || r.Count > 0 || !r.Until.IsZero():
+			s.ID += "/" + o.Start.UTC().Format(icalDateTimeLayout) + "Z"
+		case len(r.ByDay) > 1:
+			s.ID += "/" + icalWeekdayCode(o.Start.In(o.Event.Start.Location()).Weekday())
+			s.IsRecurring = true
+		default:
+			s.IsRecurring = true
+		}
+	}
+	if len(o.Event.Categories) > 0 {
+		s.Category = &Category{Name: o.Event.Categories[0]}
+	}
+	return s
+}
+
+// ICalEventFromSegment converts a schedule segment to an event in loc.
+// Recurring segments become weekly events, so their local time in loc is
+// kept across DST changes, and a canceled occurrence becomes an EXDATE.
+// time.Local has no IANA name to write, so it is replaced by UTC.
+func ICalEventFromSegment(s ScheduleSegment, loc *time.Location) ICalEvent {
+	if loc.String() == "Local" {
+		loc = time.UTC
+	}
+	e := ICalEvent{
+		UID:     scheduleSeriesID(s.ID),
+		Summary: s.Title,
+		Start:   s.StartTime.In(loc),
+		End:     s.EndTime.In(loc),
+	}
+	if s.Category != nil && s.Category.Name != "" {
+		e.Categories = []string{s.Category.Name}
+	}
+	if s.IsRecurring {
+		e.RRule = &ICalRecurrence{Freq: "WEEKLY", ByDay: []time.Weekday{e.Start.Weekday()}}
+	}
+	if s.CanceledUntil != nil {
+		e.ExDates = []time.Time{e.Start}
+	}
+	return e
+}
+
+// ICalendarFromSegments converts schedule segments to a calendar in loc.
+// Recurring segments that Get Channel Stream Schedule returns once per week
+// become a single recurring event.
+func ICalendarFromSegments(name string, segments []ScheduleSegment, loc *time.Location) *ICalendar {
+	if loc.String() == "Local" {
+		loc = time.UTC
+	}
+	cal := &ICalendar{Name: name}
+	series := make(map[string]int)
+	for _, s := range segments {
+		uid := scheduleSeriesID(s.ID)
+		if i, ok := series[uid]; ok && s.IsRecurring {
+			if s.CanceledUntil != nil {
+				cal.Events[i].ExDates = append(cal.Events[i].ExDates, s.StartTime.In(loc))
+			}
+			continue
+		}
+		series[uid] = len(cal.Events)
+		cal.Events = append(cal.Events, ICalEventFromSegment(s, loc))
+	}
+	return cal
+}
+
+// WriteICalendar writes a calendar as an iCalendar feed.
+func WriteICalendar(w io.Writer, cal *ICalendar) error {
+	var b bytes.Buffer
+	line := func(s string) {
+		// Fold lines longer than 75 octets, without splitting UTF-8 sequences
+		for len(s) > 75 {
+			cut := 75
+			for cut > 0 && s[cut]&0xC0 == 0x80 {
+				cut--
+			}
+			b.WriteString(s[:cut] + "\r\n")
+			s = " " + s[cut:]
+		}
+		b.WriteString(s + "\r\n")
+	}
+	stamp := time.Now().UTC().Format(icalDateTimeLayout) + "Z"
+
+	line("BEGIN:VCALENDAR")
+	line("PRODID:-//kappopher//helix//EN")
+	line("VERSION:2.0")
+	line("CALSCALE:GREGORIAN")
+	if cal.Name != "" {
+		line("NAME:" + escapeICalText(cal.Name))
+	}
+	for _, e := range cal.Events {
+		line("BEGIN:VEVENT")
+		line("UID:" + e.UID)
+		line("DTSTAMP:" + stamp)
+		line("DTSTART" + formatICalTime(e.Start, e.AllDay))
+		line("DTEND" + formatICalTime(e.End, e.AllDay))
+		if e.Summary != "" {
+			line("SUMMARY:" + escapeICalText(e.Summary))
+		}
+		if e.Description != "" {
+			line("DESCRIPTION:" + escapeICalText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			cats := make([]string, len(e.Categories))
+			for i, c := range e.Categories {
+				cats[i] = escapeICalText(c)
+			}
+			line("CATEGORIES:" + strings.Join(cats, ","))
+		}
+		if e.RRule != nil {
+			line("RRULE:" + e.RRule.String())
+		}
+		for _, ex := range e.ExDates {
+			line("EXDATE" + formatICalTime(ex, e.AllDay))
+		}
+		if e.Canceled {
+			line("STATUS:CANCELLED")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// formatICalTime formats a DTSTART-style property's parameters and value,
+// starting with the ; or : that follows the property name. Times in
+// time.Local, whose name isn't an IANA zone, are written in UTC.
+func formatICalTime(t time.Time, allDay bool) string {
+	if allDay {
+		return ";VALUE=DATE:" + t.Format(icalDateLayout)
+	}
+	if t.Location() == time.UTC || t.Location().String() == "Local" {
+		return ":" + t.UTC().Format(icalDateTimeLayout) + "Z"
+	}
+	return ";TZID=" + t.Location().String() + ":" + t.Format(icalDateTimeLayout)
+}
+
+// String formats the rule as an RRULE value.
+func (r *ICalRecurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(icalDateTimeLayout)+"Z")
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = strings.ToUpper(d.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
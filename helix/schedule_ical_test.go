@@ -0,0 +1,188 @@
+package helix
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICalendar_Twitch(t *testing.T) {
+	cal, err := ParseICalendar(strings.NewReader(twitchScheduleICalendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cal.Name != "TwitchDev" || len(cal.Events) != 1 {
+		t.Fatalf("cal = %+v", cal)
+	}
+	e := cal.Events[0]
+	if !e.Start.Equal(twitchScheduleStartTime) || !e.End.Equal(twitchScheduleEndTime) || e.Start.Location().String() != twitchScheduleTimezone {
+		t.Errorf("start = %v, end = %v", e.Start, e.End)
+	}
+
+	segments := cal.Segments(twitchScheduleStartTime.Add(-time.Hour), twitchScheduleEndTime)
+	if len(segments) != 1 {
+		t.Fatalf("segments = %+v", segments)
+	}
+	s := segments[0]
+	if s.ID != "e4acc724-371f-402c-81ca-23ada79759d4" || s.Title != twitchScheduleTitle || s.Category.Name != twitchScheduleCategoryName || s.IsRecurring {
+		t.Errorf("segment = %+v", s)
+	}
+	if got := cal.Segments(twitchScheduleEndTime, twitchScheduleEndTime.Add(time.Hour)); len(got) != 0 {
+		t.Errorf("segment after end = %+v", got)
+	}
+}
+
+func TestICalEvent_RecurrenceAcrossDST(t *testing.T) {
+	feed := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:America/New_York\r\nEND:VTIMEZONE\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:weekly\r\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=SU,WE;COUNT=5\r\n" +
+		"DTSTART;TZID=America/New_York:20261025T200000\r\n" +
+		"DURATION:PT1H30M\r\n" +
+		"SUMMARY:Chess\\, Go\\; and\r\n  more\r\n" +
+		"EXDATE;TZID=America/New_York:20261028T200000\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	cal, err := ParseICalendar(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &cal.Events[0]
+	if e.Summary != "Chess, Go; and more" || e.End.Sub(e.Start) != 90*time.Minute {
+		t.Errorf("event = %+v", e)
+	}
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	occurrences := e.Occurrences(from, from.AddDate(0, 2, 0))
+	// Oct 25, (Oct 28 excluded), Nov 1, Nov 4, Nov 8; COUNT includes the exclusion
+	want := []string{
+		"2026-10-26T00:00:00Z",
+		"2026-11-02T01:00:00Z", // EST after DST ends on Nov 1
+		"2026-11-05T01:00:00Z",
+		"2026-11-09T01:00:00Z",
+	}
+	if len(occurrences) != len(want) {
+		t.Fatalf("occurrences = %v", occurrences)
+	}
+	for i, o := range occurrences {
+		if got := o.Start.UTC().Format(time.RFC3339); got != want[i] || o.Start.Hour() != 20 {
+			t.Errorf("occurrence %d = %s (%s local)", i, got, o.Start.Format("15:04"))
+		}
+	}
+
+	// Occurrences overlapping the window start are included
+	if got := e.Occurrences(occurrences[0].Start.Add(time.Hour), occurrences[1].Start); len(got) != 1 {
+		t.Errorf("overlapping occurrences = %v", got)
+	}
+}
+
+func TestParseICalendar_Errors(t *testing.T) {
+	for name, feed := range map[string]string{
+		"missing end":   "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260101T000000Z\nEND:VCALENDAR\n",
+		"missing start": "BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:x\nEND:VEVENT\nEND:VCALENDAR\n",
+		"bad tzid":      "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART;TZID=Nowhere/City:20260101T000000\nEND:VEVENT\nEND:VCALENDAR\n",
+		"bad rrule":     "BEGIN:VCALENDAR\nBEGIN:VEVENT\nDTSTART:20260101T000000Z\nRRULE:FREQ=HOURLY\nEND:VEVENT\nEND:VCALENDAR\n",
+		"bad line":      "BEGIN:VCALENDAR\nnonsense\nEND:VCALENDAR\n",
+	} {
+		if _, err := ParseICalendar(strings.NewReader(feed)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestParseICalRecurrence_Unsupported(t *testing.T) {
+	for _, rule := range []string{
+		"FREQ=MONTHLY;BYMONTHDAY=15",
+		"FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+		"FREQ=YEARLY;BYMONTH=3",
+		"FREQ=WEEKLY;WKST=SU;BYDAY=SA,SU;INTERVAL=2",
+		"FREQ=MONTHLY;BYDAY=FR",
+		"FREQ=YEARLY;BYDAY=MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+	} {
+		if _, err := parseICalRecurrence(rule, time.UTC); err == nil || !strings.Contains(err.Error(), "unsupported") {
+			t.Errorf("%s: err = %v", rule, err)
+		}
+	}
+	rule, err := parseICalRecurrence("FREQ=WEEKLY;WKST=MO;BYDAY=TH;", time.UTC)
+	if err != nil || rule.Freq != "WEEKLY" || len(rule.ByDay) != 1 {
+		t.Errorf("rule = %+v, err = %v", rule, err)
+	}
+}
+
+func TestICalendarFromSegments_RoundTrip(t *testing.T) {
+	ny, err := time.LoadLocation(twitchScheduleTimezone)
+	if err != nil {
+		t.Skip(err)
+	}
+	canceled := twitchScheduleEndTime.AddDate(0, 0, 7)
+	segments := []ScheduleSegment{
+		{ID: twitchScheduleSegmentID1, StartTime: twitchScheduleStartTime, EndTime: twitchScheduleEndTime, Title: "Weekly, live", IsRecurring: true, Category: &Category{Name: twitchScheduleCategoryName}},
+		{ID: "eyJzZWdtZW50SUQiOiJlNGFjYzcyNC0zNzFmLTQwMmMtODFjYS0yM2FkYTc5NzU5ZDQiLCJpc29ZZWFyIjoyMDIxLCJpc29XZWVrIjoyN30=", StartTime: twitchScheduleStartTime.AddDate(0, 0, 7), EndTime: canceled, IsRecurring: true, CanceledUntil: &canceled},
+	}
+	cal := ICalendarFromSegments("TwitchDev", segments, ny)
+	if len(cal.Events) != 1 || cal.Events[0].UID != "e4acc724-371f-402c-81ca-23ada79759d4" || len(cal.Events[0].ExDates) != 1 {
+		t.Fatalf("events = %+v", cal.Events)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, cal); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "RRULE:FREQ=WEEKLY;BYDAY=TH\r\n") || !strings.Contains(buf.String(), "DTSTART;TZID=America/New_York:20210701T140000\r\n") {
+		t.Errorf("feed =\n%s", buf.String())
+	}
+	parsed, err := ParseICalendar(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parsed.Segments(twitchScheduleStartTime, twitchScheduleStartTime.AddDate(0, 0, 21))
+	if len(got) != 2 || !got[1].StartTime.Equal(twitchScheduleStartTime.AddDate(0, 0, 14)) || got[0].Title != "Weekly, live" {
+		t.Errorf("segments = %+v", got)
+	}
+}
+
+func TestICalOccurrence_Segment(t *testing.T) {
+	start := time.Date(2026, 10, 25, 20, 0, 0, 0, time.UTC) // Sunday
+	event := func(rule *ICalRecurrence) *ICalEvent {
+		return &ICalEvent{UID: "uid", Start: start, End: start.Add(time.Hour), RRule: rule}
+	}
+	tests := []struct {
+		name      string
+		event     *ICalEvent
+		at        time.Time
+		id        string
+		recurring bool
+	}{
+		{"single", event(nil), start, "uid", false},
+		{"weekly", event(&ICalRecurrence{Freq: "WEEKLY"}), start, "uid", true},
+		{"weekly by days", event(&ICalRecurrence{Freq: "WEEKLY", ByDay: []time.Weekday{time.Sunday, time.Wednesday}}), start.AddDate(0, 0, 3), "uid/WE", true},
+		{"biweekly", event(&ICalRecurrence{Freq: "WEEKLY", Interval: 2}), start.AddDate(0, 0, 14), "uid/20261108T200000Z", false},
+		{"daily", event(&ICalRecurrence{Freq: "DAILY"}), start.AddDate(0, 0, 1), "uid/20261026T200000Z", false},
+		{"weekly count", event(&ICalRecurrence{Freq: "WEEKLY", ByDay: []time.Weekday{time.Sunday, time.Wednesday}, Count: 5}), start.AddDate(0, 0, 3), "uid/20261028T200000Z", false},
+		{"weekly until", event(&ICalRecurrence{Freq: "WEEKLY", Until: start.AddDate(0, 1, 0)}), start.AddDate(0, 0, 7), "uid/20261101T200000Z", false},
+	}
+	for _, tt := range tests {
+		s := ICalOccurrence{Event: tt.event, Start: tt.at, End: tt.at.Add(time.Hour)}.Segment()
+		if s.ID != tt.id || s.IsRecurring != tt.recurring {
+			t.Errorf("%s: id = %q, recurring = %v", tt.name, s.ID, s.IsRecurring)
+		}
+	}
+}
+
+func TestWriteICalendar_Local(t *testing.T) {
+	// Named like time.Local when TZ is unset; Monday 5am is Sunday in UTC
+	local := time.FixedZone("Local", 10*60*60)
+	start := time.Date(2026, 1, 5, 5, 0, 0, 0, local)
+	segments := []ScheduleSegment{{ID: "weekly", StartTime: start, EndTime: start.Add(time.Hour), IsRecurring: true}}
+	var buf bytes.Buffer
+	if err := WriteICalendar(&buf, ICalendarFromSegments("Local", segments, local)); err != nil {
+		t.Fatal(err)
+	}
+	feed := buf.String()
+	if strings.Contains(feed, "TZID=Local") || !strings.Contains(feed, "DTSTART:20260104T190000Z\r\n") || !strings.Contains(feed, "BYDAY=SU\r\n") {
+		t.Errorf("feed =\n%s", feed)
+	}
+}
@@ -0,0 +1,447 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrScheduleOccurrenceNotFound is returned when an occurrence to cancel
+// doesn't match a schedule segment.
+var ErrScheduleOccurrenceNotFound = errors.New("no schedule segment starts at that time")
+
+// Twitch limits on schedule segments
+const (
+	minScheduleSegmentMinutes = 30
+	maxScheduleSegmentMinutes = 1380
+	maxScheduleTitleLength    = 140
+)
+
+// ScheduleSlot is a weekly recurring slot of a schedule template.
+type ScheduleSlot struct {
+	Days       []string `json:"days"`                  // e.g. "mon", "Wednesday"
+	Start      string   `json:"start"`                 // Local time, "19:30"
+	Duration   int      `json:"duration"`              // Minutes (30-1380)
+	Title      string   `json:"title,omitempty"`       // Up to 140 characters
+	CategoryID string   `json:"category_id,omitempty"` // Takes precedence over Category
+	Category   string   `json:"category,omitempty"`    // Category name, looked up with GetGames
+}
+
+// ScheduleTemplate is a weekly stream schedule in a broadcaster's local time.
+// SchedulePlanner.UseTemplate expands each slot into recurring segments.
+type ScheduleTemplate struct {
+	Timezone string         `json:"timezone"` // IANA time zone, e.g. "America/New_York"
+	Slots    []ScheduleSlot `json:"slots"`
+}
+
+// ParseScheduleTemplate decodes a JSON schedule template and validates it.
+// Misspelled keys such as "duraton" fail to decode instead of leaving a slot
+// without a duration.
+func ParseScheduleTemplate(r io.Reader) (*ScheduleTemplate, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var t ScheduleTemplate
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("decoding schedule template: %w", err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate checks the template's time zone, days, start times, durations,
+// and titles.
+func (t *ScheduleTemplate) Validate() error {
+	if _, err := time.LoadLocation(t.Timezone); err != nil || t.Timezone == "" || t.Timezone == "Local" {
+		return fmt.Errorf("schedule template: invalid timezone %q", t.Timezone)
+	}
+	for i, s := range t.Slots {
+		if len(s.Days) == 0 {
+			return fmt.Errorf("schedule slot %d: days are required", i)
+		}
+		for _, d := range s.Days {
+			if _, err := parseWeekday(d); err != nil {
+				return fmt.Errorf("schedule slot %d: %w", i, err)
+			}
+		}
+		if _, _, err := parseClock(s.Start); err != nil {
+			return fmt.Errorf("schedule slot %d: %w", i, err)
+		}
+		if s.Duration < minScheduleSegmentMinutes || s.Duration > maxScheduleSegmentMinutes {
+			return fmt.Errorf("schedule slot %d: duration must be %d to %d minutes", i, minScheduleSegmentMinutes, maxScheduleSegmentMinutes)
+		}
+		if len([]rune(s.Title)) > maxScheduleTitleLength {
+			return fmt.Errorf("schedule slot %d: title is longer than %d characters", i, maxScheduleTitleLength)
+		}
+	}
+	return nil
+}
+
+// Segments materializes the template into recurring segments: the first
+// occurrence of each slot and day that starts in the week from from. Start
+// times are in the template's time zone, so Twitch keeps them at the same
+// local time across DST changes. A local time skipped by a DST change is
+// moved forward by the length of the gap.
+func (t *ScheduleTemplate) Segments(from time.Time) ([]ScheduleSegment, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	loc, _ := time.LoadLocation(t.Timezone)
+	local := from.In(loc)
+
+	var segments []ScheduleSegment
+	for _, slot := range t.Slots {
+		hour, minute, _ := parseClock(slot.Start)
+		for _, d := range slot.Days {
+			wd, _ := parseWeekday(d)
+			offset := (int(wd) - int(local.Weekday()) + 7) % 7
+			start := time.Date(local.Year(), local.Month(), local.Day()+offset, hour, minute, 0, 0, loc)
+			if start.Before(from) {
+				start = time.Date(local.Year(), local.Month(), local.Day()+offset+7, hour, minute, 0, 0, loc)
+			}
+			s := ScheduleSegment{
+				StartTime:   start,
+				EndTime:     start.Add(time.Duration(slot.Duration) * time.Minute),
+				Title:       slot.Title,
+				IsRecurring: true,
+			}
+			if slot.CategoryID != "" || slot.Category != "" {
+				s.Category = &Category{ID: slot.CategoryID, Name: slot.Category}
+			}
+			segments = append(segments, s)
+		}
+	}
+	slices.SortStableFunc(segments, func(a, b ScheduleSegment) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	return segments, nil
+}
+
+// parseWeekday parses a day name or its first three letters.
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day %q", s)
+}
+
+// parseClock parses a "15:04" local time.
+func parseClock(s string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid start time %q", s)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// VacationChange is the vacation step of a schedule plan.
+type VacationChange struct {
+	Enable  bool      // False clears the vacation
+	Start   time.Time // Set when enabling
+	End     time.Time
+	Current *Vacation // Vacation before the change, nil if none
+	Err     error
+}
+
+// OccurrenceCancel is one canceled occurrence in a schedule plan.
+type OccurrenceCancel struct {
+	Start   time.Time
+	Segment *ScheduleSegment // The occurrence, nil if it is created by the plan
+	Err     error
+}
+
+// SchedulePlan lists the changes a SchedulePlanner makes.
+type SchedulePlan struct {
+	BroadcasterID string
+	Segments      *ScheduleSyncPlan // Nil without a template
+	Cancels       []OccurrenceCancel
+	Vacation      *VacationChange // Nil when the vacation is unchanged
+	Applied       bool
+}
+
+// HasChanges reports whether the plan changes anything.
+func (p *SchedulePlan) HasChanges() bool {
+	return (p.Segments != nil && p.Segments.HasChanges()) || len(p.Cancels) > 0 || p.Vacation != nil
+}
+
+// Err returns the errors from planning or applying, or nil.
+func (p *SchedulePlan) Err() error {
+	var errs []error
+	if p.Segments != nil {
+		errs = append(errs, p.Segments.Err())
+	}
+	for _, c := range p.Cancels {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("cancel %s: %w", c.Start.Format(time.RFC3339), c.Err))
+		}
+	}
+	if p.Vacation != nil && p.Vacation.Err != nil {
+		errs = append(errs, fmt.Errorf("vacation: %w", p.Vacation.Err))
+	}
+	return errors.Join(errs...)
+}
+
+// String formats the plan for display, one line per change.
+func (p *SchedulePlan) String() string {
+	var b bytes.Buffer
+	if p.Segments != nil && p.Segments.HasChanges() {
+		b.WriteString(p.Segments.String())
+	}
+	for _, c := range p.Cancels {
+		title := ""
+		if c.Segment != nil {
+			title = fmt.Sprintf(" %q", c.Segment.Title)
+		}
+		fmt.Fprintf(&b, "x cancel%s %s\n", title, c.Start.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	if v := p.Vacation; v != nil {
+		if v.Enable {
+			fmt.Fprintf(&b, "+ vacation %s to %s\n", v.Start.Format("2006-01-02 15:04 MST"), v.End.Format("2006-01-02 15:04 MST"))
+		} else {
+			fmt.Fprintf(&b, "- vacation %s to %s\n", v.Current.StartTime.Format("2006-01-02 15:04 MST"), v.Current.EndTime.Format("2006-01-02 15:04 MST"))
+		}
+	}
+	if b.Len() == 0 {
+		return "no changes\n"
+	}
+	return b.String()
+}
+
+// SchedulePlanner plans and applies changes to a channel's schedule: a
+// weekly template, vacation windows, and canceled occurrences. Describe the
+// changes, then call Run with dryRun set to see the plan, or unset to apply
+// it.
+type SchedulePlanner struct {
+	client        *Client
+	broadcasterID string
+
+	template      *ScheduleTemplate
+	deleteUnknown bool
+	vacation      *Vacation
+	clearVacation bool
+	cancels       []time.Time
+	now           func() time.Time
+}
+
+// NewSchedulePlanner creates a schedule planner for a channel.
+func NewSchedulePlanner(client *Client, broadcasterID string) *SchedulePlanner {
+	return &SchedulePlanner{client: client, broadcasterID: broadcasterID, now: time.Now}
+}
+
+// UseTemplate makes the weekly template the channel's recurring schedule.
+// With deleteUnknown, segments in the coming week that aren't in the
+// template are deleted.
+func (p *SchedulePlanner) UseTemplate(t *ScheduleTemplate, deleteUnknown bool) *SchedulePlanner {
+	p.template = t
+	p.deleteUnknown = deleteUnknown
+	return p
+}
+
+// SetVacation sets a vacation window, during which scheduled segments are
+// hidden.
+func (p *SchedulePlanner) SetVacation(start, end time.Time) *SchedulePlanner {
+	p.vacation = &Vacation{StartTime: start, EndTime: end}
+	p.clearVacation = false
+	return p
+}
+
+// ClearVacation ends the channel's vacation.
+func (p *SchedulePlanner) ClearVacation() *SchedulePlanner {
+	p.vacation = nil
+	p.clearVacation = true
+	return p
+}
+
+// CancelOccurrence cancels the occurrence of a segment that starts at start,
+// leaving the rest of a recurring series in place.
+func (p *SchedulePlanner) CancelOccurrence(start time.Time) *SchedulePlanner {
+	p.cancels = append(p.cancels, start)
+	return p
+}
+
+// Run plans the changes and, unless dryRun is set, applies them: segments
+// first, then cancellations, then the vacation. A plan with planning errors is
+// not applied. Once applying has started, the plan is returned with each
+// change's outcome recorded in it.
+// Requires: channel:manage:schedule scope.
+func (p *SchedulePlanner) Run(ctx context.Context, dryRun bool) (*SchedulePlan, error) {
+	plan, err := p.plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return plan, nil
+	}
+	if err := plan.Err(); err != nil {
+		return plan, err
+	}
+
+	plan.Applied = true
+	timezone := ""
+	if p.template != nil {
+		timezone = p.template.Timezone
+	}
+	if plan.Segments != nil {
+		if err := p.client.applySchedulePlan(ctx, plan.Segments, timezone); err != nil {
+			return plan, err
+		}
+	}
+	if err := p.applyCancels(ctx, plan); err != nil {
+		return plan, err
+	}
+	if v := plan.Vacation; v != nil {
+		enable, start, end := v.Enable, v.Start, v.End
+		params := &UpdateChannelStreamScheduleParams{BroadcasterID: p.broadcasterID, IsVacationEnabled: &enable}
+		if enable {
+			params.VacationStartTime = &start
+			params.VacationEndTime = &end
+			params.Timezone = timezone
+			if params.Timezone == "" {
+				params.Timezone = segmentTimezone(&ScheduleSegment{StartTime: v.Start}, "")
+			}
+		}
+		v.Err = p.client.UpdateChannelStreamSchedule(ctx, params)
+	}
+	return plan, plan.Err()
+}
+
+// plan fetches the schedule and computes the changes.
+func (p *SchedulePlanner) plan(ctx context.Context) (*SchedulePlan, error) {
+	from := p.now()
+	to := from.Add(defaultScheduleSyncWindow)
+	for _, start := range p.cancels {
+		if start.Before(from) {
+			return nil, fmt.Errorf("cannot cancel past occurrence at %s", start.Format(time.RFC3339))
+		}
+		to = later(to, start.Add(time.Minute))
+	}
+	if v := p.vacation; v != nil && !v.EndTime.After(v.StartTime) {
+		return nil, errors.New("vacation must end after it starts")
+	}
+
+	current, vacation, err := p.client.scheduleSegments(ctx, p.broadcasterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SchedulePlan{BroadcasterID: p.broadcasterID}
+	var desired []ScheduleSegment
+	if p.template != nil {
+		if desired, err = p.template.Segments(from); err != nil {
+			return nil, err
+		}
+		week := current[:0:0]
+		for _, s := range current {
+			if s.StartTime.Before(from.Add(defaultScheduleSyncWindow)) {
+				week = append(week, s)
+			}
+		}
+		plan.Segments = PlanChannelSchedule(p.broadcasterID, desired, week, p.deleteUnknown)
+	}
+
+	for _, start := range p.cancels {
+		c := OccurrenceCancel{Start: start}
+		i := slices.IndexFunc(current, func(s ScheduleSegment) bool { return s.StartTime.Equal(start) })
+		switch {
+		case i >= 0 && current[i].CanceledUntil != nil:
+			continue // Already canceled
+		case i >= 0 && !p.deletedByPlan(plan, &current[i]):
+			c.Segment = &current[i]
+		case !p.createsOccurrence(plan, start):
+			c.Err = ErrScheduleOccurrenceNotFound
+		}
+		plan.Cancels = append(plan.Cancels, c)
+	}
+
+	switch {
+	case p.vacation != nil && (vacation == nil || !vacation.StartTime.Equal(p.vacation.StartTime) || !vacation.EndTime.Equal(p.vacation.EndTime)):
+		plan.Vacation = &VacationChange{Enable: true, Start: p.vacation.StartTime, End: p.vacation.EndTime, Current: vacation}
+	case p.clearVacation && vacation != nil:
+		plan.Vacation = &VacationChange{Current: vacation}
+	}
+	return plan, nil
+}
+
+// deletedByPlan reports whether the plan deletes a segment's series.
+func (p *SchedulePlanner) deletedByPlan(plan *SchedulePlan, s *ScheduleSegment) bool {
+	if plan.Segments == nil {
+		return false
+	}
+	return slices.ContainsFunc(plan.Segments.Changes, func(c ScheduleChange) bool {
+		return c.Action == ScheduleDelete && scheduleSeriesID(c.Current.ID) == scheduleSeriesID(s.ID)
+	})
+}
+
+// createsOccurrence reports whether a segment created by the plan has an
+// occurrence at start.
+func (p *SchedulePlanner) createsOccurrence(plan *SchedulePlan, start time.Time) bool {
+	if plan.Segments == nil {
+		return false
+	}
+	return slices.ContainsFunc(plan.Segments.Changes, func(c ScheduleChange) bool {
+		if c.Action != ScheduleCreate {
+			return false
+		}
+		first := c.Desired.StartTime
+		if !c.Desired.IsRecurring {
+			return first.Equal(start)
+		}
+		// Weekly, in the segment's time zone
+		for t := first; !t.After(start); t = t.AddDate(0, 0, 7) {
+			if t.Equal(start) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// applyCancels cancels the planned occurrences. Occurrences of segments the
+// plan created are looked up once they exist.
+func (p *SchedulePlanner) applyCancels(ctx context.Context, plan *SchedulePlan) error {
+	for i := range plan.Cancels {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c := &plan.Cancels[i]
+		if c.Segment == nil {
+			segments, _, err := p.client.scheduleSegments(ctx, p.broadcasterID, c.Start, c.Start.Add(time.Minute))
+			if err != nil {
+				c.Err = err
+				continue
+			}
+			j := slices.IndexFunc(segments, func(s ScheduleSegment) bool { return s.StartTime.Equal(c.Start) })
+			if j < 0 {
+				c.Err = ErrScheduleOccurrenceNotFound
+				continue
+			}
+			c.Segment = &segments[j]
+		}
+		canceled := true
+		_, c.Err = p.client.UpdateChannelStreamScheduleSegment(ctx, &UpdateChannelStreamScheduleSegmentParams{
+			BroadcasterID: p.broadcasterID,
+			ID:            c.Segment.ID,
+			IsCanceled:    &canceled,
+		})
+	}
+	return nil
+}
+
+// later returns the later of two times.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,172 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestScheduleTemplate(t *testing.T) {
+	tmpl, err := ParseScheduleTemplate(strings.NewReader(`{
+		"timezone": "America/New_York",
+		"slots": [
+			{"days": ["sun", "Wednesday"], "start": "20:00", "duration": 120, "title": "Evening stream", "category": "Chess"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Friday before DST ends on Sunday, November 1
+	from := time.Date(2026, 10, 30, 12, 0, 0, 0, time.UTC)
+	segments, err := tmpl.Segments(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"2026-11-02T01:00:00Z", "2026-11-05T01:00:00Z"} // 20:00 EST
+	if len(segments) != 2 {
+		t.Fatalf("segments = %+v", segments)
+	}
+	for i, s := range segments {
+		if got := s.StartTime.UTC().Format(time.RFC3339); got != want[i] || !s.IsRecurring || segmentMinutes(&s) != 120 || s.Category.Name != "Chess" {
+			t.Errorf("segment %d = %s %+v", i, got, s)
+		}
+	}
+
+	for name, body := range map[string]string{
+		"timezone": `{"timezone": "Mars/Olympus", "slots": []}`,
+		"day":      `{"timezone": "UTC", "slots": [{"days": ["xyz"], "start": "20:00", "duration": 60}]}`,
+		"start":    `{"timezone": "UTC", "slots": [{"days": ["mon"], "start": "8pm", "duration": 60}]}`,
+		"duration": `{"timezone": "UTC", "slots": [{"days": ["mon"], "start": "20:00", "duration": 10}]}`,
+		"field":    `{"timezone": "UTC", "slot": []}`,
+	} {
+		if _, err := ParseScheduleTemplate(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestSchedulePlanner(t *testing.T) {
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC) // Monday
+	monday := time.Date(2026, 1, 5, 19, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+
+	var mu sync.Mutex
+	current := []ScheduleSegment{
+		{ID: "mon1", StartTime: monday, EndTime: monday.Add(2 * time.Hour), Title: "Old", IsRecurring: true, Category: &Category{ID: "1", Name: "Chess"}},
+		{ID: "mon2", StartTime: monday.Add(week), EndTime: monday.Add(week + 2*time.Hour), Title: "Old", IsRecurring: true, Category: &Category{ID: "1", Name: "Chess"}},
+	}
+	var calls []string
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(r.Body)
+		switch {
+		case r.URL.Path == "/schedule" && r.Method == http.MethodGet:
+			from, _ := time.Parse(time.RFC3339, q.Get("start_time"))
+			var segments []ScheduleSegment
+			for _, s := range current {
+				if !s.StartTime.Before(from) {
+					segments = append(segments, s)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(ScheduleResponse{Data: Schedule{Segments: segments}})
+		case r.URL.Path == "/schedule/segment" && r.Method == http.MethodPost:
+			calls = append(calls, "create "+strings.TrimSpace(body.String()))
+			// Twitch now lists the new recurring segment
+			wed := monday.Add(2*24*time.Hour + time.Hour)
+			for i := range 2 {
+				start := wed.Add(time.Duration(i) * week)
+				current = append(current, ScheduleSegment{ID: "wed" + string(rune('1'+i)), StartTime: start, EndTime: start.Add(time.Hour), IsRecurring: true})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"segments": current[2:3]}})
+		case r.URL.Path == "/schedule/segment" && r.Method == http.MethodPatch:
+			calls = append(calls, "update "+q.Get("id")+" "+strings.TrimSpace(body.String()))
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"segments": current[:1]}})
+		case r.URL.Path == "/schedule/settings":
+			calls = append(calls, "settings "+r.URL.RawQuery)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	tmpl := &ScheduleTemplate{Timezone: "UTC", Slots: []ScheduleSlot{
+		{Days: []string{"mon"}, Start: "19:00", Duration: 120, Title: "Dev stream", CategoryID: "1"},
+		{Days: []string{"wed"}, Start: "20:00", Duration: 60, Title: "Q&A", CategoryID: "1"},
+	}}
+	vacationStart := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	planner := NewSchedulePlanner(client, "1234").
+		UseTemplate(tmpl, false).
+		CancelOccurrence(monday.Add(week)).
+		CancelOccurrence(monday.Add(2*24*time.Hour+time.Hour+week)). // Created by the template
+		SetVacation(vacationStart, vacationStart.Add(week))
+	planner.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	plan, err := planner.Run(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `~ update "Dev stream" Mon 2026-01-05 19:00 UTC (120m, weekly)
+    title: "Old" -> "Dev stream"
++ create "Q&A" Wed 2026-01-07 20:00 UTC (60m, weekly)
+x cancel "Old" Mon 2026-01-12 19:00 UTC
+x cancel Wed 2026-01-14 20:00 UTC
++ vacation 2026-02-01 00:00 UTC to 2026-02-08 00:00 UTC
+`
+	if got := plan.String(); got != want {
+		t.Errorf("plan =\n%s\nwant\n%s", got, want)
+	}
+	if len(calls) != 0 {
+		t.Errorf("dry run made changes: %v", calls)
+	}
+
+	plan, err = planner.Run(ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCalls := []string{
+		`update mon1 {"title":"Dev stream"}`,
+		`create {"start_time":"2026-01-07T20:00:00Z","timezone":"UTC","duration":60,"is_recurring":true,"category_id":"1","title":"Q\u0026A"}`,
+		`update mon2 {"is_canceled":true}`,
+		`update wed2 {"is_canceled":true}`,
+		"settings broadcaster_id=1234&is_vacation_enabled=true&timezone=UTC&vacation_end_time=2026-02-08T00%3A00%3A00Z&vacation_start_time=2026-02-01T00%3A00%3A00Z",
+	}
+	if !plan.Applied || !slices.Equal(calls, wantCalls) {
+		t.Errorf("calls =\n%s", strings.Join(calls, "\n"))
+	}
+}
+
+func TestSchedulePlanner_Errors(t *testing.T) {
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		vacation := &Vacation{StartTime: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC)}
+		_ = json.NewEncoder(w).Encode(ScheduleResponse{Data: Schedule{Vacation: vacation}})
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC)
+	planner := NewSchedulePlanner(client, "1234").CancelOccurrence(now.Add(time.Hour)).ClearVacation()
+	planner.now = func() time.Time { return now }
+
+	plan, err := planner.Run(context.Background(), false)
+	if !errors.Is(err, ErrScheduleOccurrenceNotFound) || plan.Applied {
+		t.Errorf("Run() = %v, applied = %v", err, plan.Applied)
+	}
+	if !strings.Contains(plan.String(), "- vacation 2026-02-01 00:00 UTC to 2026-02-08 00:00 UTC") {
+		t.Errorf("plan = %s", plan)
+	}
+}
@@ -0,0 +1,442 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultScheduleSyncWindow is how far ahead SyncChannelSchedule compares
+// schedules by default. One week covers every weekly recurring segment.
+const defaultScheduleSyncWindow = 7 * 24 * time.Hour
+
+// ScheduleChangeAction is the action a schedule sync takes for one segment.
+type ScheduleChangeAction string
+
+// Schedule change actions
+const (
+	ScheduleCreate    ScheduleChangeAction = "create"
+	ScheduleUpdate    ScheduleChangeAction = "update"
+	ScheduleDelete    ScheduleChangeAction = "delete"
+	ScheduleUnchanged ScheduleChangeAction = "unchanged"
+)
+
+// ScheduleFieldChange is a field that differs between a Twitch segment and
+// the desired segment.
+type ScheduleFieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// ScheduleChange is one step of a schedule sync plan.
+type ScheduleChange struct {
+	Action  ScheduleChangeAction
+	Desired *ScheduleSegment // Nil for deletes
+	Current *ScheduleSegment // Nil for creates
+	Fields  []ScheduleFieldChange
+	Result  *ScheduleSegment // Created or updated segment, once applied
+	Err     error            // Error applying the change
+}
+
+// segment returns the segment the change is about, for display.
+func (c *ScheduleChange) segment() *ScheduleSegment {
+	if c.Desired != nil {
+		return c.Desired
+	}
+	return c.Current
+}
+
+// ScheduleSyncPlan lists the changes needed to bring a channel's Twitch
+// schedule in line with a calendar.
+type ScheduleSyncPlan struct {
+	BroadcasterID string
+	Changes       []ScheduleChange
+	Applied       bool
+}
+
+// HasChanges reports whether the plan creates, updates, or deletes anything.
+func (p *ScheduleSyncPlan) HasChanges() bool {
+	for _, c := range p.Changes {
+		if c.Action != ScheduleUnchanged {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the errors from applying the plan, or nil.
+func (p *ScheduleSyncPlan) Err() error {
+	var errs []error
+	for _, c := range p.Changes {
+		if c.Err != nil {
+			errs = append(errs, fmt.Errorf("%s %q: %w", c.Action, c.segment().Title, c.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String formats the plan for display, one line per change, with unchanged
+// segments omitted.
+func (p *ScheduleSyncPlan) String() string {
+	var b bytes.Buffer
+	for _, c := range p.Changes {
+		s := c.segment()
+		switch c.Action {
+		case ScheduleCreate:
+			fmt.Fprintf(&b, "+ create %q %s\n", s.Title, describeSegment(s))
+		case ScheduleDelete:
+			fmt.Fprintf(&b, "- delete %q %s\n", s.Title, describeSegment(s))
+		case ScheduleUpdate:
+			fmt.Fprintf(&b, "~ update %q %s\n", s.Title, describeSegment(s))
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Field, f.Old, f.New)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "no changes\n"
+	}
+	return b.String()
+}
+
+// describeSegment formats a segment's start, duration, and recurrence.
+func describeSegment(s *ScheduleSegment) string {
+	desc := s.StartTime.Format("Mon 2006-01-02 15:04 MST") + " (" + strconv.Itoa(segmentMinutes(s)) + "m"
+	if s.IsRecurring {
+		desc += ", weekly"
+	}
+	return desc + ")"
+}
+
+// ScheduleSyncOptions configures SyncChannelSchedule.
+type ScheduleSyncOptions struct {
+	// DryRun computes the plan without changing anything.
+	DryRun bool
+	// Delete removes Twitch segments in the window that aren't in the
+	// calendar.
+	Delete bool
+	// From and To bound the compared window (default now to one week later).
+	From time.Time
+	To   time.Time
+	// Timezone is the IANA time zone for created and moved segments. By
+	// default it is the calendar event's time zone, or UTC.
+	Timezone string
+}
+
+// SyncChannelSchedule brings the channel's Twitch schedule in line with a
+// calendar. Both are expanded over the sync window, recurring segments are
+// compared once per series, and segments are then created, updated, and
+// optionally deleted. Updating or deleting a recurring segment changes the
+// whole series, and a segment whose recurring flag differs is recreated.
+//
+// Categories given only by name, as in iCalendar CATEGORIES, are looked up
+// with GetGames. Every change is attempted; a failed segment keeps its error
+// in the change, and the returned error joins them.
+// Requires: channel:manage:schedule scope.
+func (c *Client) SyncChannelSchedule(ctx context.Context, broadcasterID string, cal *ICalendar, opts *ScheduleSyncOptions) (*ScheduleSyncPlan, error) {
+	if opts == nil {
+		opts = &ScheduleSyncOptions{}
+	}
+	from, to := opts.From, opts.To
+	if from.IsZero() {
+		from = time.Now()
+	}
+	if to.IsZero() {
+		to = from.Add(defaultScheduleSyncWindow)
+	}
+
+	current, _, err := c.scheduleSegments(ctx, broadcasterID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	plan := PlanChannelSchedule(broadcasterID, cal.Segments(from, to), current, opts.Delete)
+	if opts.DryRun {
+		return plan, nil
+	}
+	return plan, c.applySchedulePlan(ctx, plan, opts.Timezone)
+}
+
+// applySchedulePlan applies a schedule sync plan. timezone overrides the time
+// zone of created and moved segments.
+func (c *Client) applySchedulePlan(ctx context.Context, plan *ScheduleSyncPlan, timezone string) error {
+	categories, err := c.scheduleCategoryIDs(ctx, plan)
+	if err != nil {
+		return err
+	}
+	plan.Applied = true
+	for i := range plan.Changes {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		change := &plan.Changes[i]
+		switch change.Action {
+		case ScheduleCreate:
+			change.Result, change.Err = c.CreateChannelStreamScheduleSegment(ctx, &CreateChannelStreamScheduleSegmentParams{
+				BroadcasterID: plan.BroadcasterID,
+				StartTime:     change.Desired.StartTime,
+				Timezone:      segmentTimezone(change.Desired, timezone),
+				Duration:      segmentMinutes(change.Desired),
+				IsRecurring:   change.Desired.IsRecurring,
+				CategoryID:    categories[segmentCategoryKey(change.Desired)],
+				Title:         change.Desired.Title,
+			})
+		case ScheduleUpdate:
+			change.Result, change.Err = c.UpdateChannelStreamScheduleSegment(ctx, updateSegmentParams(plan.BroadcasterID, change, categories, timezone))
+		case ScheduleDelete:
+			change.Err = c.DeleteChannelStreamScheduleSegment(ctx, plan.BroadcasterID, change.Current.ID)
+		}
+	}
+	return plan.Err()
+}
+
+// PlanChannelSchedule diffs desired segments against the channel's current
+// segments without calling the API. Recurring segments are compared once per
+// series. Segments are matched by series ID (an iCalendar UID exported by
+// Twitch matches its segment), then by start time. Current segments without
+// a match are deleted when deleteUnknown is set, and otherwise left out of
+// the plan.
+func PlanChannelSchedule(broadcasterID string, desired, current []ScheduleSegment, deleteUnknown bool) *ScheduleSyncPlan {
+	desired = collapseSeries(desired)
+	current = collapseSeries(current)
+	plan := &ScheduleSyncPlan{BroadcasterID: broadcasterID}
+	matched := make([]bool, len(current))
+
+	match := func(d *ScheduleSegment) int {
+		for i := range current {
+			if !matched[i] && scheduleSeriesID(current[i].ID) == d.ID {
+				return i
+			}
+		}
+		for i := range current {
+			if !matched[i] && current[i].StartTime.Equal(d.StartTime) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	var deletes []ScheduleChange
+	for i := range desired {
+		d := &desired[i]
+		j := match(d)
+		if j < 0 {
+			plan.Changes = append(plan.Changes, ScheduleChange{Action: ScheduleCreate, Desired: d})
+			continue
+		}
+		matched[j] = true
+		cur := &current[j]
+		if cur.IsRecurring != d.IsRecurring {
+			// The recurring flag can't be updated
+			deletes = append(deletes, ScheduleChange{Action: ScheduleDelete, Current: cur})
+			plan.Changes = append(plan.Changes, ScheduleChange{Action: ScheduleCreate, Desired: d})
+			continue
+		}
+		change := ScheduleChange{Action: ScheduleUnchanged, Desired: d, Current: cur, Fields: diffSegment(d, cur)}
+		if len(change.Fields) > 0 {
+			change.Action = ScheduleUpdate
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+	if deleteUnknown {
+		for i := range current {
+			if !matched[i] {
+				deletes = append(deletes, ScheduleChange{Action: ScheduleDelete, Current: &current[i]})
+			}
+		}
+	}
+	// Delete first, so recreated segments don't overlap their old series
+	plan.Changes = append(deletes, plan.Changes...)
+	return plan
+}
+
+// collapseSeries keeps the first occurrence of each recurring series.
+// Segments without an ID are kept.
+func collapseSeries(segments []ScheduleSegment) []ScheduleSegment {
+	seen := make(map[string]bool)
+	var out []ScheduleSegment
+	for _, s := range segments {
+		if s.IsRecurring && s.ID != "" {
+			id := scheduleSeriesID(s.ID)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// scheduleSeriesID returns the ID shared by every occurrence of a recurring
+// segment. Get Channel Stream Schedule IDs are base64 JSON holding the
+// segment ID and ISO week; other IDs are returned unchanged.
+func scheduleSeriesID(id string) string {
+	data, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return id
+	}
+	var v struct {
+		SegmentID string `json:"segmentID"`
+	}
+	if json.Unmarshal(data, &v) != nil || v.SegmentID == "" {
+		return id
+	}
+	return v.SegmentID
+}
+
+// segmentMinutes returns a segment's duration in minutes.
+func segmentMinutes(s *ScheduleSegment) int {
+	return int(s.EndTime.Sub(s.StartTime) / time.Minute)
+}
+
+// segmentTimezone returns the time zone to send for a segment.
+func segmentTimezone(s *ScheduleSegment, override string) string {
+	if override != "" {
+		return override
+	}
+	if name := s.StartTime.Location().String(); name != "Local" {
+		return name
+	}
+	return "UTC"
+}
+
+// segmentCategoryKey returns the key a segment's category ID is looked up by.
+func segmentCategoryKey(s *ScheduleSegment) string {
+	if s.Category == nil {
+		return ""
+	}
+	if s.Category.ID != "" {
+		return s.Category.ID
+	}
+	return strings.ToLower(s.Category.Name)
+}
+
+// diffSegment lists the fields of current that differ from desired.
+func diffSegment(desired, current *ScheduleSegment) []ScheduleFieldChange {
+	var fields []ScheduleFieldChange
+	add := func(field, old, new string) {
+		if old != new {
+			fields = append(fields, ScheduleFieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	if !desired.StartTime.Equal(current.StartTime) {
+		add("start_time", current.StartTime.UTC().Format(time.RFC3339), desired.StartTime.UTC().Format(time.RFC3339))
+	}
+	add("duration", strconv.Itoa(segmentMinutes(current)), strconv.Itoa(segmentMinutes(desired)))
+	add("title", strconv.Quote(current.Title), strconv.Quote(desired.Title))
+
+	var oldCategory, newCategory string
+	if current.Category != nil {
+		oldCategory = current.Category.Name
+	}
+	if desired.Category != nil {
+		newCategory = desired.Category.Name
+		if desired.Category.ID != "" && current.Category != nil && desired.Category.ID == current.Category.ID {
+			newCategory = oldCategory
+		}
+	}
+	if !strings.EqualFold(oldCategory, newCategory) {
+		add("category", strconv.Quote(oldCategory), strconv.Quote(newCategory))
+	}
+	return fields
+}
+
+// updateSegmentParams builds the Update Channel Stream Schedule Segment
+// request for a change. Only the changed fields are sent.
+func updateSegmentParams(broadcasterID string, change *ScheduleChange, categories map[string]string, timezone string) *UpdateChannelStreamScheduleSegmentParams {
+	d := change.Desired
+	start, minutes, title := d.StartTime, segmentMinutes(d), d.Title
+	categoryID := categories[segmentCategoryKey(d)]
+	p := &UpdateChannelStreamScheduleSegmentParams{BroadcasterID: broadcasterID, ID: change.Current.ID}
+	for _, f := range change.Fields {
+		switch f.Field {
+		case "start_time":
+			p.StartTime = &start
+			p.Timezone = segmentTimezone(d, timezone)
+		case "duration":
+			p.Duration = &minutes
+		case "title":
+			p.Title = &title
+		case "category":
+			p.CategoryID = &categoryID
+		}
+	}
+	return p
+}
+
+// scheduleCategoryIDs maps the category keys of the plan's creates and
+// category updates to category IDs, looking up names with GetGames.
+func (c *Client) scheduleCategoryIDs(ctx context.Context, plan *ScheduleSyncPlan) (map[string]string, error) {
+	ids := map[string]string{"": ""}
+	var names []string
+	for _, change := range plan.Changes {
+		d := change.Desired
+		categoryChanged := slices.ContainsFunc(change.Fields, func(f ScheduleFieldChange) bool { return f.Field == "category" })
+		if d == nil || d.Category == nil || (change.Action != ScheduleCreate && !categoryChanged) {
+			continue
+		}
+		if d.Category.ID != "" {
+			ids[d.Category.ID] = d.Category.ID
+		} else if key := segmentCategoryKey(d); key != "" {
+			if _, ok := ids[key]; !ok {
+				ids[key] = ""
+				names = append(names, d.Category.Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return ids, nil
+	}
+
+	resp, err := c.GetGames(ctx, &GetGamesParams{Names: names})
+	if err != nil {
+		return nil, fmt.Errorf("looking up schedule categories: %w", err)
+	}
+	for _, g := range resp.Data {
+		ids[strings.ToLower(g.Name)] = g.ID
+	}
+	if len(resp.NotFound) > 0 {
+		return nil, fmt.Errorf("unknown schedule categories: %s", strings.Join(resp.NotFound, ", "))
+	}
+	return ids, nil
+}
+
+// scheduleSegments returns the channel's segments that start in [from, to),
+// and its vacation. A channel without a schedule has no segments.
+func (c *Client) scheduleSegments(ctx context.Context, broadcasterID string, from, to time.Time) ([]ScheduleSegment, *Vacation, error) {
+	var segments []ScheduleSegment
+	var vacation *Vacation
+	params := &GetChannelStreamScheduleParams{
+		BroadcasterID:    broadcasterID,
+		StartTime:        from,
+		PaginationParams: &PaginationParams{First: 25},
+	}
+	for {
+		resp, err := c.GetChannelStreamSchedule(ctx, params)
+		if isNotFoundError(err) {
+			return segments, vacation, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		vacation = resp.Data.Vacation
+		for _, s := range resp.Data.Segments {
+			if !s.StartTime.Before(to) {
+				return segments, vacation, nil
+			}
+			segments = append(segments, s)
+		}
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data.Segments) == 0 {
+			return segments, vacation, nil
+		}
+		params.After = resp.Pagination.Cursor
+	}
+}
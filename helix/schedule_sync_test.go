@@ -0,0 +1,110 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncChannelSchedule(t *testing.T) {
+	start := time.Date(2026, 1, 5, 19, 0, 0, 0, time.UTC) // Monday
+	week := 7 * 24 * time.Hour
+	seriesID := "eyJzZWdtZW50SUQiOiJlNGFjYzcyNC0zNzFmLTQwMmMtODFjYS0yM2FkYTc5NzU5ZDQiLCJpc29ZZWFyIjoyMDIxLCJpc29XZWVrIjoyNn0="
+	current := []ScheduleSegment{
+		// Recurring series, returned once per week
+		{ID: seriesID, StartTime: start, EndTime: start.Add(2 * time.Hour), Title: "Old title", IsRecurring: true, Category: &Category{ID: "509670", Name: "Science & Technology"}},
+		{ID: seriesID, StartTime: start.Add(week), EndTime: start.Add(week + 2*time.Hour), Title: "Old title", IsRecurring: true, Category: &Category{ID: "509670", Name: "Science & Technology"}},
+		// One-off matched by start time
+		{ID: "oneoff", StartTime: start.Add(48 * time.Hour), EndTime: start.Add(49 * time.Hour), Title: "Special", Category: &Category{ID: "1", Name: "Chess"}},
+		// Not in the calendar
+		{ID: "extra", StartTime: start.Add(72 * time.Hour), EndTime: start.Add(73 * time.Hour), Title: "Extra"},
+	}
+
+	var calls []string
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/schedule" && r.Method == http.MethodGet:
+			resp := ScheduleResponse{Data: Schedule{Segments: current[:2]}, Pagination: &Pagination{Cursor: "page2"}}
+			if q.Get("after") == "page2" {
+				resp = ScheduleResponse{Data: Schedule{Segments: current[2:]}}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case r.URL.Path == "/games":
+			if !slices.Equal(q["name"], []string{"Just Chatting"}) {
+				t.Errorf("game lookup = %v", q["name"])
+			}
+			_ = json.NewEncoder(w).Encode(Response[Game]{Data: []Game{{ID: "509658", Name: "Just Chatting"}}})
+		case r.URL.Path == "/schedule/segment":
+			var body bytes.Buffer
+			_, _ = body.ReadFrom(r.Body)
+			calls = append(calls, r.Method+" "+q.Get("id")+" "+strings.TrimSpace(body.String()))
+			_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"segments": []ScheduleSegment{{ID: "new"}}}})
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	feed := "BEGIN:VCALENDAR\n" +
+		"BEGIN:VEVENT\nUID:e4acc724-371f-402c-81ca-23ada79759d4\nDTSTART:20260105T190000Z\nDTEND:20260105T210000Z\n" +
+		"RRULE:FREQ=WEEKLY;BYDAY=MO\nSUMMARY:Weekly dev stream\nCATEGORIES:Science & Technology\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:local-1\nDTSTART:20260107T190000Z\nDURATION:PT1H\nSUMMARY:Special\nCATEGORIES:chess\nEND:VEVENT\n" +
+		"BEGIN:VEVENT\nUID:local-2\nDTSTART;TZID=America/New_York:20260109T180000\nDURATION:PT3H\nSUMMARY:Hangout\nCATEGORIES:Just Chatting\nEND:VEVENT\n" +
+		"END:VCALENDAR\n"
+	cal, err := ParseICalendar(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := &ScheduleSyncOptions{DryRun: true, Delete: true, From: start.Add(-time.Hour), To: start.Add(week + time.Hour)}
+	ctx := context.Background()
+
+	plan, err := client.SyncChannelSchedule(ctx, "1234", cal, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `- delete "Extra" Thu 2026-01-08 19:00 UTC (60m)
+~ update "Weekly dev stream" Mon 2026-01-05 19:00 UTC (120m, weekly)
+    title: "Old title" -> "Weekly dev stream"
++ create "Hangout" Fri 2026-01-09 18:00 EST (180m)
+`
+	if got := plan.String(); got != want {
+		t.Errorf("plan =\n%s\nwant\n%s", got, want)
+	}
+	if len(calls) != 0 {
+		t.Errorf("dry run made changes: %v", calls)
+	}
+
+	opts.DryRun = false
+	plan, err = client.SyncChannelSchedule(ctx, "1234", cal, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCalls := []string{
+		"DELETE extra ",
+		`PATCH ` + seriesID + ` {"title":"Weekly dev stream"}`,
+		`POST  {"start_time":"2026-01-09T18:00:00-05:00","timezone":"America/New_York","duration":180,"category_id":"509658","title":"Hangout"}`,
+	}
+	if !plan.Applied || !slices.Equal(calls, wantCalls) {
+		t.Errorf("calls = %q", calls)
+	}
+}
+
+func TestPlanChannelSchedule_RecurringChange(t *testing.T) {
+	start := time.Date(2026, 1, 5, 19, 0, 0, 0, time.UTC)
+	current := []ScheduleSegment{{ID: "a", StartTime: start, EndTime: start.Add(time.Hour), Title: "Stream"}}
+	desired := []ScheduleSegment{{ID: "b", StartTime: start, EndTime: start.Add(time.Hour), Title: "Stream", IsRecurring: true}}
+
+	plan := PlanChannelSchedule("1234", desired, current, false)
+	if len(plan.Changes) != 2 || plan.Changes[0].Action != ScheduleDelete || plan.Changes[1].Action != ScheduleCreate {
+		t.Errorf("plan = %s", plan)
+	}
+	if plan := PlanChannelSchedule("1234", current, current, true); plan.HasChanges() || plan.String() != "no changes\n" {
+		t.Errorf("identical plan = %s", plan)
+	}
+}
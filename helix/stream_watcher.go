@@ -0,0 +1,383 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Default StreamWatcher settings
+const (
+	defaultStreamWatcherInterval     = time.Minute
+	defaultStreamWatcherOfflineGrace = 3 * time.Minute
+)
+
+// StreamViewerThresholdEvent is emitted by StreamWatcher when a stream's
+// viewer count crosses a threshold. It has no EventSub equivalent.
+type StreamViewerThresholdEvent struct {
+	EventSubBroadcaster
+	Threshold   int
+	ViewerCount int
+	Rising      bool // True when the count rose to or above the threshold
+}
+
+// watchedStream is the state of one watched broadcaster.
+type watchedStream struct {
+	stream      *Stream   // Last seen stream, nil while offline
+	missingFrom time.Time // When the stream was first missing, zero if seen
+}
+
+// StreamWatcher tracks whether broadcasters are live by polling Get Streams,
+// for deployments that can't use EventSub. It checks up to 100 broadcasters
+// per request and reports changes with the same event types as the
+// stream.online, stream.offline, and channel.update EventSub subscriptions,
+// so handlers can later be moved to EventSub unchanged.
+//
+// Streams that disappear are reported offline only once they have been
+// missing for the offline grace period, so brief drops don't produce
+// offline/online pairs. A stream whose ID changed between polls was restarted
+// within the grace period, and is reported offline and then online. Streams
+// that are already live on the first poll are recorded without an online
+// event, matching EventSub.
+//
+// Title and category changes are only seen while a stream is live.
+type StreamWatcher struct {
+	client       *Client
+	interval     time.Duration
+	offlineGrace time.Duration
+	thresholds   []int
+
+	onOnline    func(*StreamOnlineEvent)
+	onOffline   func(*StreamOfflineEvent)
+	onUpdate    func(*ChannelUpdateEvent)
+	onThreshold func(*StreamViewerThresholdEvent)
+	onEvent     func(eventType string, data json.RawMessage)
+	onError     func(error)
+
+	mu      sync.Mutex
+	streams map[string]*watchedStream // user ID -> state
+	primed  map[string]bool           // user IDs polled at least once
+}
+
+// StreamWatcherOption configures a StreamWatcher.
+type StreamWatcherOption func(*StreamWatcher)
+
+// WithStreamWatcherInterval sets how often Run polls (default 1m).
+func WithStreamWatcherInterval(d time.Duration) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.interval = d
+	}
+}
+
+// WithStreamWatcherOfflineGrace sets how long a stream must be missing before
+// it is reported offline (default 3m). Zero reports it on the first miss.
+func WithStreamWatcherOfflineGrace(d time.Duration) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.offlineGrace = d
+	}
+}
+
+// WithStreamWatcherViewerThresholds sets viewer counts that trigger a
+// StreamViewerThresholdEvent when a live stream crosses them.
+func WithStreamWatcherViewerThresholds(thresholds ...int) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.thresholds = slices.Sorted(slices.Values(thresholds))
+	}
+}
+
+// WithStreamOnlineHandler sets the handler for streams going live.
+func WithStreamOnlineHandler(fn func(*StreamOnlineEvent)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onOnline = fn
+	}
+}
+
+// WithStreamOfflineHandler sets the handler for streams going offline.
+func WithStreamOfflineHandler(fn func(*StreamOfflineEvent)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onOffline = fn
+	}
+}
+
+// WithStreamUpdateHandler sets the handler for title and category changes.
+func WithStreamUpdateHandler(fn func(*ChannelUpdateEvent)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onUpdate = fn
+	}
+}
+
+// WithStreamViewerThresholdHandler sets the handler for viewer threshold
+// crossings.
+func WithStreamViewerThresholdHandler(fn func(*StreamViewerThresholdEvent)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onThreshold = fn
+	}
+}
+
+// WithStreamWatcherEventHandler sets a handler that receives the online,
+// offline, and update events as raw EventSub-style notifications, for
+// components with a HandleEvent(ctx, eventType, data) method.
+func WithStreamWatcherEventHandler(fn func(eventType string, data json.RawMessage)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onEvent = fn
+	}
+}
+
+// WithStreamWatcherErrorHandler sets the handler for polling errors in Run.
+func WithStreamWatcherErrorHandler(fn func(error)) StreamWatcherOption {
+	return func(w *StreamWatcher) {
+		w.onError = fn
+	}
+}
+
+// NewStreamWatcher creates a stream watcher. Add broadcasters with Add, then
+// call Run.
+func NewStreamWatcher(client *Client, opts ...StreamWatcherOption) *StreamWatcher {
+	w := &StreamWatcher{
+		client:       client,
+		interval:     defaultStreamWatcherInterval,
+		offlineGrace: defaultStreamWatcherOfflineGrace,
+		streams:      make(map[string]*watchedStream),
+		primed:       make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Add starts watching broadcasters by user ID.
+func (w *StreamWatcher) Add(userIDs ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range userIDs {
+		if _, ok := w.streams[id]; !ok {
+			w.streams[id] = &watchedStream{}
+		}
+	}
+}
+
+// Remove stops watching broadcasters. No offline event is sent.
+func (w *StreamWatcher) Remove(userIDs ...string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, id := range userIDs {
+		delete(w.streams, id)
+		delete(w.primed, id)
+	}
+}
+
+// Stream returns the last seen stream of a watched broadcaster, and whether
+// it is live.
+func (w *StreamWatcher) Stream(userID string) (Stream, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if s, ok := w.streams[userID]; ok && s.stream != nil {
+		return *s.stream, true
+	}
+	return Stream{}, false
+}
+
+// Live returns the streams of all watched broadcasters that are live.
+func (w *StreamWatcher) Live() []Stream {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var live []Stream
+	for _, s := range w.streams {
+		if s.stream != nil {
+			live = append(live, *s.stream)
+		}
+	}
+	return live
+}
+
+// Run polls immediately and then every interval until ctx is canceled.
+// Polling errors are reported to the error handler.
+func (w *StreamWatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil && ctx.Err() == nil && w.onError != nil {
+			w.onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks all watched broadcasters once and emits events for changes.
+// Broadcasters in a batch whose request failed keep their previous state.
+func (w *StreamWatcher) Poll(ctx context.Context) error {
+	return w.poll(ctx, time.Now())
+}
+
+// streamEvent is an event to dispatch after a poll.
+type streamEvent struct {
+	eventType string
+	event     any
+}
+
+func (w *StreamWatcher) poll(ctx context.Context, now time.Time) error {
+	w.mu.Lock()
+	userIDs := slices.Sorted(maps.Keys(w.streams))
+	w.mu.Unlock()
+
+	var errs []error
+	for batch := range slices.Chunk(userIDs, maxIDsPerRequest) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		resp, err := w.client.GetStreams(ctx, &GetStreamsParams{
+			UserIDs:          batch,
+			PaginationParams: &PaginationParams{First: maxIDsPerRequest},
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		live := make(map[string]*Stream, len(resp.Data))
+		for i := range resp.Data {
+			live[resp.Data[i].UserID] = &resp.Data[i]
+		}
+		w.dispatch(w.update(batch, live, now))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d of %d stream requests failed: %w", len(errs), (len(userIDs)+maxIDsPerRequest-1)/maxIDsPerRequest, errs[0])
+	}
+	return nil
+}
+
+// update applies the live streams of a batch and returns the resulting events.
+func (w *StreamWatcher) update(batch []string, live map[string]*Stream, now time.Time) []streamEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []streamEvent
+	for _, userID := range batch {
+		state, ok := w.streams[userID]
+		if !ok {
+			continue // Removed while polling
+		}
+		primed := w.primed[userID]
+		w.primed[userID] = true
+		current := live[userID]
+		prev := state.stream
+
+		switch {
+		case current == nil && prev == nil:
+			// Still offline
+		case current == nil:
+			if state.missingFrom.IsZero() {
+				state.missingFrom = now
+			}
+			if now.Sub(state.missingFrom) >= w.offlineGrace {
+				events = append(events, streamEvent{EventSubTypeStreamOffline, &StreamOfflineEvent{EventSubBroadcaster: streamBroadcaster(prev)}})
+				events = append(events, w.thresholdEvents(prev, prev.ViewerCount, 0)...)
+				state.stream = nil
+				state.missingFrom = time.Time{}
+			}
+		case prev == nil:
+			state.stream = current
+			state.missingFrom = time.Time{}
+			if !primed {
+				continue // Already live when first seen
+			}
+			events = append(events, w.onlineEvents(current)...)
+		case current.ID != prev.ID:
+			// Restarted between polls
+			state.stream = current
+			state.missingFrom = time.Time{}
+			events = append(events, streamEvent{EventSubTypeStreamOffline, &StreamOfflineEvent{EventSubBroadcaster: streamBroadcaster(prev)}})
+			events = append(events, w.thresholdEvents(prev, prev.ViewerCount, 0)...)
+			events = append(events, w.onlineEvents(current)...)
+		default:
+			state.stream = current
+			state.missingFrom = time.Time{}
+			if current.Title != prev.Title || current.GameID != prev.GameID {
+				events = append(events, streamEvent{EventSubTypeChannelUpdate, &ChannelUpdateEvent{
+					EventSubBroadcaster: streamBroadcaster(current),
+					Title:               current.Title,
+					Language:            current.Language,
+					CategoryID:          current.GameID,
+					CategoryName:        current.GameName,
+				}})
+			}
+			events = append(events, w.thresholdEvents(current, prev.ViewerCount, current.ViewerCount)...)
+		}
+	}
+	return events
+}
+
+// onlineEvents returns the events for a stream going live.
+func (w *StreamWatcher) onlineEvents(s *Stream) []streamEvent {
+	events := []streamEvent{{EventSubTypeStreamOnline, &StreamOnlineEvent{
+		ID:                  s.ID,
+		EventSubBroadcaster: streamBroadcaster(s),
+		Type:                s.Type,
+		StartedAt:           s.StartedAt,
+	}}}
+	return append(events, w.thresholdEvents(s, 0, s.ViewerCount)...)
+}
+
+// thresholdEvents returns the threshold crossings between two viewer counts.
+func (w *StreamWatcher) thresholdEvents(s *Stream, from, to int) []streamEvent {
+	var events []streamEvent
+	for _, t := range w.thresholds {
+		rising := from < t && to >= t
+		falling := from >= t && to < t
+		if rising || falling {
+			events = append(events, streamEvent{"", &StreamViewerThresholdEvent{
+				EventSubBroadcaster: streamBroadcaster(s),
+				Threshold:           t,
+				ViewerCount:         to,
+				Rising:              rising,
+			}})
+		}
+	}
+	return events
+}
+
+// dispatch calls the handlers for events.
+func (w *StreamWatcher) dispatch(events []streamEvent) {
+	for _, e := range events {
+		switch ev := e.event.(type) {
+		case *StreamOnlineEvent:
+			if w.onOnline != nil {
+				w.onOnline(ev)
+			}
+		case *StreamOfflineEvent:
+			if w.onOffline != nil {
+				w.onOffline(ev)
+			}
+		case *ChannelUpdateEvent:
+			if w.onUpdate != nil {
+				w.onUpdate(ev)
+			}
+		case *StreamViewerThresholdEvent:
+			if w.onThreshold != nil {
+				w.onThreshold(ev)
+			}
+		}
+		if w.onEvent != nil && e.eventType != "" {
+			if data, err := json.Marshal(e.event); err == nil {
+				w.onEvent(e.eventType, data)
+			}
+		}
+	}
+}
+
+// streamBroadcaster returns the EventSub broadcaster fields for a stream.
+func streamBroadcaster(s *Stream) EventSubBroadcaster {
+	return EventSubBroadcaster{
+		BroadcasterUserID:    s.UserID,
+		BroadcasterUserLogin: s.UserLogin,
+		BroadcasterUserName:  s.UserName,
+	}
+}
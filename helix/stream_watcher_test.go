@@ -0,0 +1,151 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStreamWatcher_Transitions(t *testing.T) {
+	var mu sync.Mutex
+	live := map[string]Stream{}
+	setLive := func(s Stream) {
+		mu.Lock()
+		live[s.UserID] = s
+		mu.Unlock()
+	}
+	setOffline := func(id string) {
+		mu.Lock()
+		delete(live, id)
+		mu.Unlock()
+	}
+
+	var requests int32
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		ids := r.URL.Query()["user_id"]
+		if len(ids) > 100 {
+			t.Errorf("requested %d users", len(ids))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var data []Stream
+		for _, id := range ids {
+			if s, ok := live[id]; ok {
+				data = append(data, s)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(Response[Stream]{Data: data})
+	}))
+	defer server.Close()
+
+	var events []string
+	watcher := NewStreamWatcher(client,
+		WithStreamWatcherOfflineGrace(2*time.Minute),
+		WithStreamWatcherViewerThresholds(100),
+		WithStreamOnlineHandler(func(e *StreamOnlineEvent) { events = append(events, "online:"+e.BroadcasterUserID+":"+e.ID) }),
+		WithStreamOfflineHandler(func(e *StreamOfflineEvent) { events = append(events, "offline:"+e.BroadcasterUserID) }),
+		WithStreamUpdateHandler(func(e *ChannelUpdateEvent) {
+			events = append(events, "update:"+e.BroadcasterUserID+":"+e.Title+":"+e.CategoryName)
+		}),
+		WithStreamViewerThresholdHandler(func(e *StreamViewerThresholdEvent) {
+			events = append(events, fmt.Sprintf("threshold:%s:%d:%v", e.BroadcasterUserID, e.Threshold, e.Rising))
+		}),
+	)
+	for i := range 250 {
+		watcher.Add(fmt.Sprint(i))
+	}
+
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	step := func() {
+		t.Helper()
+		now = now.Add(time.Minute)
+		if err := watcher.poll(ctx, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Already live on the first poll: no event
+	setLive(Stream{ID: "s0", UserID: "0", Title: "hello", GameName: "Chess", GameID: "g1"})
+	step()
+	if requests != 3 || len(events) != 0 {
+		t.Fatalf("requests = %d, events = %v", requests, events)
+	}
+	if s, ok := watcher.Stream("0"); !ok || s.ID != "s0" {
+		t.Errorf("Stream(0) = %+v, %v", s, ok)
+	}
+
+	setLive(Stream{ID: "s1", UserID: "201", Title: "going live", ViewerCount: 5})
+	setLive(Stream{ID: "s0", UserID: "0", Title: "new title", GameName: "Go", GameID: "g2", ViewerCount: 150})
+	step()
+
+	// A brief drop within the grace period produces no events
+	setOffline("201")
+	step()
+	setLive(Stream{ID: "s1", UserID: "201", Title: "going live", ViewerCount: 5})
+	step()
+
+	// A restart within the grace period is a new stream
+	setOffline("201")
+	step()
+	setLive(Stream{ID: "s2", UserID: "201", Title: "back again", ViewerCount: 5})
+	step()
+
+	setOffline("0")
+	step()
+	step()
+	step()
+
+	want := []string{
+		"update:0:new title:Go",
+		"threshold:0:100:true",
+		"online:201:s1",
+		"offline:201",
+		"online:201:s2",
+		"offline:0",
+		"threshold:0:100:false",
+	}
+	if fmt.Sprint(events) != fmt.Sprint(want) {
+		t.Errorf("events = %v\nwant %v", events, want)
+	}
+	if len(watcher.Live()) != 1 {
+		t.Errorf("Live() = %+v", watcher.Live())
+	}
+}
+
+func TestStreamWatcher_FailedBatchKeepsState(t *testing.T) {
+	fail := false
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Response[Stream]{Data: []Stream{{ID: "s", UserID: "1"}}})
+	}))
+	defer server.Close()
+
+	var offline, raw int
+	watcher := NewStreamWatcher(client,
+		WithStreamWatcherOfflineGrace(0),
+		WithStreamOfflineHandler(func(*StreamOfflineEvent) { offline++ }),
+		WithStreamWatcherEventHandler(func(eventType string, data json.RawMessage) { raw++ }),
+	)
+	watcher.Add("1")
+	ctx := context.Background()
+	if err := watcher.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	fail = true
+	if err := watcher.Poll(ctx); err == nil {
+		t.Error("Poll() succeeded on server error")
+	}
+	if _, live := watcher.Stream("1"); !live || offline != 0 || raw != 0 {
+		t.Errorf("live = %v, offline events = %d, raw events = %d", live, offline, raw)
+	}
+}
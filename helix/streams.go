@@ -24,10 +24,15 @@ type Stream struct {
 	IsMature     bool      `json:"is_mature"`
 }
 
+// Thumbnail returns the stream's thumbnail URL at a size.
+func (s *Stream) Thumbnail(width, height int) string {
+	return FormatThumbnailURL(s.ThumbnailURL, width, height)
+}
+
 // GetStreamsParams contains parameters for GetStreams.
 type GetStreamsParams struct {
-	UserIDs    []string // Filter by user IDs (max 100)
-	UserLogins []string // Filter by user logins (max 100)
+	UserIDs    []string // Filter by user IDs
+	UserLogins []string // Filter by user logins
 	GameIDs    []string // Filter by game IDs (max 100)
 	Type       string   // "all" or "live"
 	Language   []string // Filter by language
@@ -35,32 +40,35 @@ type GetStreamsParams struct {
 }
 
 // GetStreams gets active streams.
+// Duplicate user IDs and logins are sent once. More than 100 are split into
+// concurrent requests that each return a full page. The users that aren't
+// live are listed in Response.NotFound, but only when every response fit on
+// one page; set First to 100 to get it for up to 100 users.
 func (c *Client) GetStreams(ctx context.Context, params *GetStreamsParams) (*Response[Stream], error) {
+	if params == nil {
+		params = &GetStreamsParams{}
+	}
 	q := url.Values{}
-	if params != nil {
-		for _, id := range params.UserIDs {
-			q.Add("user_id", id)
-		}
-		for _, login := range params.UserLogins {
-			q.Add("user_login", login)
-		}
-		for _, gameID := range params.GameIDs {
-			q.Add("game_id", gameID)
-		}
-		for _, lang := range params.Language {
-			q.Add("language", lang)
-		}
-		if params.Type != "" {
-			q.Set("type", params.Type)
-		}
-		addPaginationParams(q, params.PaginationParams)
+	for _, gameID := range params.GameIDs {
+		q.Add("game_id", gameID)
 	}
-
-	var resp Response[Stream]
-	if err := c.get(ctx, "/streams", q, &resp); err != nil {
-		return nil, err
+	for _, lang := range params.Language {
+		q.Add("language", lang)
 	}
-	return &resp, nil
+	if params.Type != "" {
+		q.Set("type", params.Type)
+	}
+	addPaginationParams(q, params.PaginationParams)
+
+	return lookup(ctx, c, lookupRequest[Stream]{
+		endpoint: "/streams",
+		query:    q,
+		params: []lookupParam[Stream]{
+			{name: "user_id", values: params.UserIDs, key: func(s *Stream) string { return s.UserID }},
+			{name: "user_login", values: params.UserLogins, key: func(s *Stream) string { return s.UserLogin }, foldCase: true},
+		},
+		paginated: true,
+	})
 }
 
 // GetFollowedStreamsParams contains parameters for GetFollowedStreams.
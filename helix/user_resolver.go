@@ -0,0 +1,465 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default UserResolver settings
+const (
+	defaultUserResolverTTL         = time.Hour
+	defaultUserResolverMissTTL     = time.Minute
+	defaultUserResolverBatchWindow = 10 * time.Millisecond
+	maxLoginLength                 = 25
+)
+
+// userKey is a parsed user reference: a login or a user ID.
+type userKey struct {
+	login bool
+	value string // Lowercased for logins
+}
+
+// parseUserKey parses a login, @mention, #channel, or user ID. Input that
+// is all digits is a user ID unless it has an @ or # prefix.
+func parseUserKey(input string) userKey {
+	s := strings.TrimSpace(input)
+	if rest, ok := strings.CutPrefix(s, "@"); ok {
+		return userKey{login: true, value: strings.ToLower(rest)}
+	}
+	if rest, ok := strings.CutPrefix(s, "#"); ok {
+		return userKey{login: true, value: strings.ToLower(rest)}
+	}
+	if s != "" && strings.Trim(s, "0123456789") == "" {
+		return userKey{value: s}
+	}
+	return userKey{login: true, value: strings.ToLower(s)}
+}
+
+// valid reports whether the key can be sent to Get Users. Twitch logins are 1
+// to 25 lowercase letters, digits and underscores; anything else makes Get
+// Users reject the whole request.
+func (k userKey) valid() bool {
+	if !k.login {
+		return k.value != ""
+	}
+	if k.value == "" || len(k.value) > maxLoginLength {
+		return false
+	}
+	for _, c := range k.value {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// userCacheEntry is a cached user.
+type userCacheEntry struct {
+	user      User
+	fetchedAt time.Time
+}
+
+// userRename is a login change seen by a UserResolver.
+type userRename struct {
+	userID, oldLogin, newLogin string
+}
+
+// userBatch is a set of lookups sent in one GetUsers call.
+type userBatch struct {
+	keys    map[userKey]bool
+	started bool
+	done    chan struct{}
+	found   map[userKey]User // Set before done is closed
+	err     error
+}
+
+// UserResolver converts between logins and user IDs. Lookups from
+// concurrent callers made within a short batch window are combined into a
+// single GetUsers call, and results are cached in both directions.
+//
+// Renames are tracked: when a user's login changes, either through a
+// user.update event or a fresh lookup, the old login is dropped from the
+// cache, and a login that now belongs to a different user replaces the old
+// owner's entry. Unknown users are cached for a shorter time, and input
+// that isn't a valid login or ID resolves to nil without a request. A
+// UserResolver is safe for concurrent use.
+type UserResolver struct {
+	client   *Client
+	ttl      time.Duration
+	missTTL  time.Duration
+	window   time.Duration
+	onRename func(userID, oldLogin, newLogin string)
+	onError  func(error)
+
+	mu      sync.Mutex
+	byID    map[string]*userCacheEntry
+	byLogin map[string]*userCacheEntry
+	misses  map[userKey]time.Time // Unknown users -> looked up at
+	pending *userBatch
+}
+
+// UserResolverOption configures a UserResolver.
+type UserResolverOption func(*UserResolver)
+
+// WithUserResolverTTL sets how long resolved users are cached (default 1
+// hour). A TTL of 0 keeps them until they are invalidated.
+func WithUserResolverTTL(ttl time.Duration) UserResolverOption {
+	return func(r *UserResolver) {
+		r.ttl = ttl
+	}
+}
+
+// WithUserResolverMissTTL sets how long unknown logins and IDs are
+// remembered before they are looked up again (default 1 minute). A TTL of 0
+// looks them up every time.
+func WithUserResolverMissTTL(ttl time.Duration) UserResolverOption {
+	return func(r *UserResolver) {
+		r.missTTL = ttl
+	}
+}
+
+// WithUserResolverBatchWindow sets how long a lookup waits for others to
+// join its GetUsers call (default 10ms).
+func WithUserResolverBatchWindow(d time.Duration) UserResolverOption {
+	return func(r *UserResolver) {
+		r.window = d
+	}
+}
+
+// WithUserRenameHandler sets a handler called when a cached user's login
+// changes.
+func WithUserRenameHandler(fn func(userID, oldLogin, newLogin string)) UserResolverOption {
+	return func(r *UserResolver) {
+		r.onRename = fn
+	}
+}
+
+// WithUserResolverErrorHandler sets the handler for errors from events
+// received through Subscribe.
+func WithUserResolverErrorHandler(fn func(error)) UserResolverOption {
+	return func(r *UserResolver) {
+		r.onError = fn
+	}
+}
+
+// NewUserResolver creates a user resolver.
+func NewUserResolver(client *Client, opts ...UserResolverOption) *UserResolver {
+	r := &UserResolver{
+		client:  client,
+		ttl:     defaultUserResolverTTL,
+		missTTL: defaultUserResolverMissTTL,
+		window:  defaultUserResolverBatchWindow,
+		byID:    make(map[string]*userCacheEntry),
+		byLogin: make(map[string]*userCacheEntry),
+		misses:  make(map[userKey]time.Time),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Resolve returns the user for a login, @mention, #channel, or user ID, or
+// nil if there is no such user.
+func (r *UserResolver) Resolve(ctx context.Context, input string) (*User, error) {
+	users, err := r.ResolveUsers(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return users[0], nil
+}
+
+// ResolveUsers returns the users for logins, @mentions, #channels, and user
+// IDs, in input order. Entries for unknown users are nil.
+func (r *UserResolver) ResolveUsers(ctx context.Context, inputs ...string) ([]*User, error) {
+	keys := make([]userKey, len(inputs))
+	users := make([]*User, len(inputs))
+	var missing []userKey
+
+	r.mu.Lock()
+	now := time.Now()
+	for i, input := range inputs {
+		keys[i] = parseUserKey(input)
+		if !keys[i].valid() {
+			continue
+		}
+		if u, ok := r.cached(keys[i], now); ok {
+			users[i] = &u
+		} else if at, ok := r.misses[keys[i]]; !ok || now.Sub(at) >= r.missTTL {
+			missing = append(missing, keys[i])
+		}
+	}
+	var batch *userBatch
+	if len(missing) > 0 {
+		batch = r.enqueue(ctx, missing)
+	}
+	r.mu.Unlock()
+
+	if batch == nil {
+		return users, nil
+	}
+	select {
+	case <-batch.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if batch.err != nil {
+		return nil, batch.err
+	}
+	for i, key := range keys {
+		if users[i] == nil {
+			if u, ok := batch.found[key]; ok {
+				users[i] = &u
+			}
+		}
+	}
+	return users, nil
+}
+
+// ResolveIDs returns the user IDs for logins, @mentions, #channels, and
+// user IDs, in input order. Entries for unknown users are empty.
+func (r *UserResolver) ResolveIDs(ctx context.Context, inputs ...string) ([]string, error) {
+	users, err := r.ResolveUsers(ctx, inputs...)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(users))
+	for i, u := range users {
+		if u != nil {
+			ids[i] = u.ID
+		}
+	}
+	return ids, nil
+}
+
+// ResolveLogins returns the logins for logins, @mentions, #channels, and
+// user IDs, in input order. Entries for unknown users are empty.
+func (r *UserResolver) ResolveLogins(ctx context.Context, inputs ...string) ([]string, error) {
+	users, err := r.ResolveUsers(ctx, inputs...)
+	if err != nil {
+		return nil, err
+	}
+	logins := make([]string, len(users))
+	for i, u := range users {
+		if u != nil {
+			logins[i] = u.Login
+		}
+	}
+	return logins, nil
+}
+
+// Store adds users to the cache, e.g. from chat messages that carry both a
+// login and an ID.
+func (r *UserResolver) Store(users ...User) {
+	r.mu.Lock()
+	now := time.Now()
+	var renames []userRename
+	for _, u := range users {
+		renames = append(renames, r.store(u, now)...)
+	}
+	r.mu.Unlock()
+	r.notifyRenames(renames)
+}
+
+// Invalidate drops cached users by login, @mention, #channel, or user ID,
+// or every cached user when called without arguments.
+func (r *UserResolver) Invalidate(inputs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(inputs) == 0 {
+		r.byID = make(map[string]*userCacheEntry)
+		r.byLogin = make(map[string]*userCacheEntry)
+		clear(r.misses)
+		return
+	}
+	for _, input := range inputs {
+		key := parseUserKey(input)
+		delete(r.misses, key)
+		entry := r.byID[key.value]
+		if key.login {
+			entry = r.byLogin[key.value]
+		}
+		if entry != nil {
+			r.drop(entry)
+		}
+	}
+}
+
+// Subscribe subscribes to user.update events for users, so renames are seen
+// as they happen.
+func (r *UserResolver) Subscribe(ctx context.Context, ws *EventSubWebSocket, userIDs ...string) error {
+	eventType := EventSubTypeUserUpdate
+	for _, id := range userIDs {
+		err := ws.Subscribe(ctx, eventType, GetEventSubVersion(eventType), UserCondition(id), func(data json.RawMessage) {
+			if err := r.HandleEvent(context.Background(), eventType, data); err != nil && r.onError != nil {
+				r.onError(err)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleEvent processes a raw user.update event, updating the cached user's
+// login, display name, and description. Other event types are ignored.
+func (r *UserResolver) HandleEvent(ctx context.Context, eventType string, data json.RawMessage) error {
+	if eventType != EventSubTypeUserUpdate {
+		return nil
+	}
+	event, err := ParseWSEvent[UserUpdateEvent](data)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	var renames []userRename
+	if entry, ok := r.byID[event.UserID]; ok {
+		u := entry.user
+		u.Login = event.UserLogin
+		u.DisplayName = event.UserName
+		u.Description = event.Description
+		if event.Email != "" {
+			u.Email = event.Email
+		}
+		renames = r.store(u, entry.fetchedAt)
+	} else if entry, ok := r.byLogin[strings.ToLower(event.UserLogin)]; ok {
+		r.drop(entry) // The login now belongs to this user
+	}
+	r.mu.Unlock()
+	r.notifyRenames(renames)
+	return nil
+}
+
+// cached returns a fresh cached user. Callers hold r.mu.
+func (r *UserResolver) cached(key userKey, now time.Time) (User, bool) {
+	entry := r.byID[key.value]
+	if key.login {
+		entry = r.byLogin[key.value]
+	}
+	if entry == nil || (r.ttl > 0 && now.Sub(entry.fetchedAt) >= r.ttl) {
+		return User{}, false
+	}
+	return entry.user, true
+}
+
+// store caches a user, dropping entries made stale by a rename, and returns
+// the renames. Callers hold r.mu.
+func (r *UserResolver) store(u User, fetchedAt time.Time) []userRename {
+	var renames []userRename
+	login := strings.ToLower(u.Login)
+	if prev, ok := r.byID[u.ID]; ok && !strings.EqualFold(prev.user.Login, u.Login) {
+		if r.byLogin[strings.ToLower(prev.user.Login)] == prev {
+			delete(r.byLogin, strings.ToLower(prev.user.Login))
+		}
+		renames = append(renames, userRename{u.ID, prev.user.Login, u.Login})
+	}
+	if prev, ok := r.byLogin[login]; ok && prev.user.ID != u.ID {
+		// The login was taken over; the old owner's login is unknown
+		r.drop(prev)
+	}
+	entry := &userCacheEntry{user: u, fetchedAt: fetchedAt}
+	r.byID[u.ID] = entry
+	r.byLogin[login] = entry
+	delete(r.misses, userKey{value: u.ID})
+	delete(r.misses, userKey{login: true, value: login})
+	return renames
+}
+
+// drop removes a cached user. Callers hold r.mu.
+func (r *UserResolver) drop(entry *userCacheEntry) {
+	if r.byID[entry.user.ID] == entry {
+		delete(r.byID, entry.user.ID)
+	}
+	if login := strings.ToLower(entry.user.Login); r.byLogin[login] == entry {
+		delete(r.byLogin, login)
+	}
+}
+
+func (r *UserResolver) notifyRenames(renames []userRename) {
+	if r.onRename == nil {
+		return
+	}
+	for _, rn := range renames {
+		r.onRename(rn.userID, rn.oldLogin, rn.newLogin)
+	}
+}
+
+// enqueue adds keys to the pending batch, starting one if needed. The batch
+// is sent when the window ends or it holds 100 keys. Callers hold r.mu.
+func (r *UserResolver) enqueue(ctx context.Context, keys []userKey) *userBatch {
+	b := r.pending
+	if b == nil {
+		b = &userBatch{keys: make(map[userKey]bool), done: make(chan struct{})}
+		r.pending = b
+		fetchCtx := context.WithoutCancel(ctx)
+		time.AfterFunc(r.window, func() { r.flush(fetchCtx, b) })
+	}
+	for _, k := range keys {
+		b.keys[k] = true
+	}
+	if len(b.keys) >= maxIDsPerRequest {
+		r.pending = nil
+		go r.flush(context.WithoutCancel(ctx), b)
+	}
+	return b
+}
+
+// flush sends a batch, once.
+func (r *UserResolver) flush(ctx context.Context, b *userBatch) {
+	r.mu.Lock()
+	if r.pending == b {
+		r.pending = nil
+	}
+	if b.started {
+		r.mu.Unlock()
+		return
+	}
+	b.started = true
+	r.mu.Unlock()
+
+	params := &GetUsersParams{}
+	for k := range b.keys {
+		if k.login {
+			params.Logins = append(params.Logins, k.value)
+		} else {
+			params.IDs = append(params.IDs, k.value)
+		}
+	}
+	resp, err := r.client.GetUsers(ctx, params)
+	if err != nil {
+		b.err = err
+		close(b.done)
+		return
+	}
+
+	b.found = make(map[userKey]User, len(resp.Data))
+	r.mu.Lock()
+	now := time.Now()
+	var renames []userRename
+	for _, u := range resp.Data {
+		b.found[userKey{value: u.ID}] = u
+		b.found[userKey{login: true, value: strings.ToLower(u.Login)}] = u
+		renames = append(renames, r.store(u, now)...)
+	}
+	if r.missTTL > 0 {
+		for k, at := range r.misses {
+			if now.Sub(at) >= r.missTTL {
+				delete(r.misses, k)
+			}
+		}
+		for k := range b.keys {
+			if _, ok := b.found[k]; !ok {
+				r.misses[k] = now
+			}
+		}
+	}
+	r.mu.Unlock()
+	close(b.done)
+	r.notifyRenames(renames)
+}
@@ -0,0 +1,158 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// userHandler serves GetUsers from a login -> ID map.
+func userHandler(t *testing.T, users map[string]string, requests *int32) http.HandlerFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		var data []User
+		for login, id := range users {
+			if slices.Contains(q["login"], login) || slices.Contains(q["id"], id) {
+				data = append(data, User{ID: id, Login: login})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: data})
+	}
+}
+
+func TestUserResolver_Batching(t *testing.T) {
+	users := map[string]string{"alice": "1", "bob": "2"}
+	for i := range 20 {
+		users[fmt.Sprintf("user%d", i)] = fmt.Sprint(100 + i)
+	}
+	var requests int32
+	client, server := newTestClient(userHandler(t, users, &requests))
+	defer server.Close()
+
+	r := NewUserResolver(client, WithUserResolverBatchWindow(20*time.Millisecond))
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			u, err := r.Resolve(ctx, fmt.Sprintf("user%d", i))
+			if err != nil || u == nil || u.ID != fmt.Sprint(100+i) {
+				t.Errorf("Resolve(user%d) = %+v, %v", i, u, err)
+			}
+		})
+	}
+	wg.Go(func() {
+		ids, err := r.ResolveIDs(ctx, "@Alice", " #bob ", "2", "nobody")
+		if err != nil || !slices.Equal(ids, []string{"1", "2", "2", ""}) {
+			t.Errorf("ResolveIDs() = %q, %v", ids, err)
+		}
+	})
+	wg.Wait()
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+
+	logins, err := r.ResolveLogins(ctx, "1", "ALICE", "105")
+	if err != nil || !slices.Equal(logins, []string{"alice", "alice", "user5"}) {
+		t.Errorf("ResolveLogins() = %q, %v", logins, err)
+	}
+	if requests != 1 {
+		t.Errorf("cached lookup made a request")
+	}
+}
+
+func TestUserResolver_Renames(t *testing.T) {
+	users := map[string]string{"alice": "1"}
+	var requests int32
+	client, server := newTestClient(userHandler(t, users, &requests))
+	defer server.Close()
+
+	var renames []string
+	r := NewUserResolver(client, WithUserResolverBatchWindow(0), WithUserRenameHandler(func(id, oldLogin, newLogin string) {
+		renames = append(renames, id+":"+oldLogin+"->"+newLogin)
+	}))
+	ctx := context.Background()
+	if _, err := r.Resolve(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	event := `{"user_id":"1","user_login":"alicia","user_name":"Alicia","description":"new"}`
+	if err := r.HandleEvent(ctx, EventSubTypeUserUpdate, json.RawMessage(event)); err != nil {
+		t.Fatal(err)
+	}
+	u, err := r.Resolve(ctx, "1")
+	if err != nil || u.Login != "alicia" || u.Description != "new" {
+		t.Errorf("Resolve(1) = %+v, %v", u, err)
+	}
+	if !slices.Equal(renames, []string{"1:alice->alicia"}) {
+		t.Errorf("renames = %v", renames)
+	}
+
+	// The old login is no longer cached; someone else took it
+	users["alice"] = "2"
+	delete(users, "alicia")
+	if ids, _ := r.ResolveIDs(ctx, "alice"); ids[0] != "2" || requests != 2 {
+		t.Errorf("ResolveIDs(alice) = %q after %d requests", ids, requests)
+	}
+
+	// A login taken over by another user drops the old owner
+	r.Store(User{ID: "3", Login: "alicia"})
+	r.Store(User{ID: "4", Login: "alicia"})
+	r.mu.Lock()
+	_, stale := r.byID["3"]
+	r.mu.Unlock()
+	if stale {
+		t.Error("previous owner of login still cached")
+	}
+}
+
+func TestUserResolver_InvalidAndUnknown(t *testing.T) {
+	var requests int32
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		for _, login := range r.URL.Query()["login"] {
+			if !(userKey{login: true, value: login}).valid() {
+				// Get Users rejects the whole request
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		var data []User
+		if slices.Contains(r.URL.Query()["login"], "alice") {
+			data = append(data, User{ID: "1", Login: "alice"})
+		}
+		_ = json.NewEncoder(w).Encode(Response[User]{Data: data})
+	}))
+	defer server.Close()
+
+	r := NewUserResolver(client, WithUserResolverBatchWindow(0))
+	ctx := context.Background()
+
+	ids, err := r.ResolveIDs(ctx, "@alice", "@bad-name!", "#"+strings.Repeat("x", 26), "@", "nobody")
+	if err != nil || !slices.Equal(ids, []string{"1", "", "", "", ""}) {
+		t.Fatalf("ResolveIDs() = %q, %v", ids, err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+
+	// Unknown users aren't looked up again until the miss TTL passes
+	if u, err := r.Resolve(ctx, "nobody"); u != nil || err != nil || requests != 1 {
+		t.Errorf("Resolve(nobody) = %+v, %v after %d requests", u, err, requests)
+	}
+	r.Invalidate("nobody")
+	if _, err := r.Resolve(ctx, "nobody"); err != nil || requests != 2 {
+		t.Errorf("Resolve(nobody) after Invalidate made %d requests, %v", requests, err)
+	}
+}
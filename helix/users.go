@@ -24,28 +24,26 @@ type User struct {
 
 // GetUsersParams contains parameters for GetUsers.
 type GetUsersParams struct {
-	IDs    []string // User IDs (max 100)
-	Logins []string // User login names (max 100)
+	IDs    []string // User IDs
+	Logins []string // User login names
 }
 
 // GetUsers gets information about one or more Twitch users.
+// Duplicate IDs and logins (case-insensitive) are sent once. More than 100
+// are split into concurrent requests, and the ones that don't exist are
+// listed in Response.NotFound.
 // Requires: No scope for public data, user:read:email for email.
 func (c *Client) GetUsers(ctx context.Context, params *GetUsersParams) (*Response[User], error) {
-	q := url.Values{}
-	if params != nil {
-		for _, id := range params.IDs {
-			q.Add("id", id)
-		}
-		for _, login := range params.Logins {
-			q.Add("login", login)
-		}
-	}
-
-	var resp Response[User]
-	if err := c.get(ctx, "/users", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	if params == nil {
+		params = &GetUsersParams{}
+	}
+	return lookup(ctx, c, lookupRequest[User]{
+		endpoint: "/users",
+		params: []lookupParam[User]{
+			{name: "id", values: params.IDs, key: func(u *User) string { return u.ID }},
+			{name: "login", values: params.Logins, key: func(u *User) string { return u.Login }, foldCase: true},
+		},
+	})
 }
 
 // GetCurrentUser gets information about the authenticated user.
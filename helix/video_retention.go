@@ -0,0 +1,281 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxVideoDeleteIDs is the Delete Videos limit per request.
+const maxVideoDeleteIDs = 5
+
+// Video types
+const (
+	VideoTypeArchive   = "archive"
+	VideoTypeHighlight = "highlight"
+	VideoTypeUpload    = "upload"
+)
+
+// VideoRetentionRule selects videos to delete. A video matches when every
+// set condition holds, so a rule with no conditions matches every video.
+type VideoRetentionRule struct {
+	Name          string   `json:"name,omitempty"`            // Shown in plans and audits
+	Types         []string `json:"types,omitempty"`           // archive, highlight, upload; empty matches all
+	OlderThanDays int      `json:"older_than_days,omitempty"` // 0 matches any age
+	ViewsBelow    int      `json:"views_below,omitempty"`     // 0 matches any view count
+}
+
+// String describes the rule, e.g. "archive, older than 30 days".
+func (r *VideoRetentionRule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	var parts []string
+	if len(r.Types) > 0 {
+		parts = append(parts, strings.Join(r.Types, "/"))
+	}
+	if r.OlderThanDays > 0 {
+		parts = append(parts, fmt.Sprintf("older than %d days", r.OlderThanDays))
+	}
+	if r.ViewsBelow > 0 {
+		parts = append(parts, fmt.Sprintf("fewer than %d views", r.ViewsBelow))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Matches reports whether a video matches the rule at the given time.
+func (r *VideoRetentionRule) Matches(v *Video, now time.Time) bool {
+	switch {
+	case len(r.Types) > 0 && !slices.Contains(r.Types, v.Type):
+		return false
+	case r.OlderThanDays > 0 && !v.CreatedAt.Before(now.AddDate(0, 0, -r.OlderThanDays)):
+		return false
+	case r.ViewsBelow > 0 && v.ViewCount >= r.ViewsBelow:
+		return false
+	}
+	return true
+}
+
+// VideoRetentionPolicy decides which of a user's videos to delete: those
+// matching any rule, except the excluded IDs.
+type VideoRetentionPolicy struct {
+	Rules   []VideoRetentionRule `json:"rules"`
+	Exclude []string             `json:"exclude,omitempty"` // Video IDs never deleted
+}
+
+// ParseVideoRetentionPolicy decodes and validates a JSON retention policy.
+// Since a policy deletes videos, a misspelled condition such as
+// "older_than_day" is an error rather than being dropped, which would widen
+// the rule.
+func ParseVideoRetentionPolicy(r io.Reader) (*VideoRetentionPolicy, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var p VideoRetentionPolicy
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("decoding retention policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks that the policy has rules, that rule types are known, and
+// that every rule limits age or views, so no rule deletes all videos of a
+// type.
+func (p *VideoRetentionPolicy) Validate() error {
+	if len(p.Rules) == 0 {
+		return errors.New("retention policy has no rules")
+	}
+	for i, r := range p.Rules {
+		for _, t := range r.Types {
+			if t != VideoTypeArchive && t != VideoTypeHighlight && t != VideoTypeUpload {
+				return fmt.Errorf("retention rule %d: unknown video type %q", i, t)
+			}
+		}
+		if r.OlderThanDays <= 0 && r.ViewsBelow <= 0 {
+			return fmt.Errorf("retention rule %d: older_than_days or views_below is required", i)
+		}
+	}
+	return nil
+}
+
+// VideoDeletion is one video in a retention plan.
+type VideoDeletion struct {
+	Video   Video
+	Rule    string // The first matching rule
+	Deleted bool   // Set once Delete Videos reports the video deleted
+	Err     error  // Error deleting the video
+}
+
+// VideoRetentionPlan lists the videos a retention policy deletes.
+type VideoRetentionPlan struct {
+	UserID    string
+	Deletions []VideoDeletion // Oldest first
+	Kept      int             // Videos not matched or excluded
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// HasChanges reports whether the plan deletes anything.
+func (p *VideoRetentionPlan) HasChanges() bool {
+	return len(p.Deletions) > 0
+}
+
+// Err returns the errors from applying the plan, or nil.
+func (p *VideoRetentionPlan) Err() error {
+	var errs []error
+	for _, d := range p.Deletions {
+		if d.Err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", d.Video.ID, d.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// String formats the plan for display, one line per deletion.
+func (p *VideoRetentionPlan) String() string {
+	var b bytes.Buffer
+	for _, d := range p.Deletions {
+		v := &d.Video
+		fmt.Fprintf(&b, "- delete %s %s %q %s (%d views): %s\n", v.Type, v.ID, v.Title, v.CreatedAt.Format(time.DateOnly), v.ViewCount, d.Rule)
+	}
+	if b.Len() == 0 {
+		return "no changes\n"
+	}
+	return b.String()
+}
+
+// VideoRetentionAuditEntry is one line of a retention audit.
+type VideoRetentionAuditEntry struct {
+	Time      time.Time `json:"time"` // When the plan was applied, zero for a dry run
+	UserID    string    `json:"user_id"`
+	VideoID   string    `json:"video_id"`
+	Type      string    `json:"type"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+	ViewCount int       `json:"view_count"`
+	Rule      string    `json:"rule"`
+	Deleted   bool      `json:"deleted"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// WriteAudit writes the plan's deletions as JSON Lines, one
+// VideoRetentionAuditEntry per video.
+func (p *VideoRetentionPlan) WriteAudit(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, d := range p.Deletions {
+		entry := VideoRetentionAuditEntry{
+			Time:      p.AppliedAt,
+			UserID:    p.UserID,
+			VideoID:   d.Video.ID,
+			Type:      d.Video.Type,
+			Title:     d.Video.Title,
+			CreatedAt: d.Video.CreatedAt,
+			ViewCount: d.Video.ViewCount,
+			Rule:      d.Rule,
+			Deleted:   d.Deleted,
+		}
+		if d.Err != nil {
+			entry.Error = d.Err.Error()
+		}
+		if err := enc.Encode(&entry); err != nil {
+			return fmt.Errorf("writing retention audit: %w", err)
+		}
+	}
+	return nil
+}
+
+// VideoRetentionOptions configures EnforceVideoRetention.
+type VideoRetentionOptions struct {
+	// DryRun computes the plan without deleting anything.
+	DryRun bool
+}
+
+// EnforceVideoRetention deletes a user's videos that match a retention
+// policy. It pages through all of the user's videos, plans the deletions,
+// and deletes them five at a time, the Delete Videos limit. A failed batch
+// doesn't stop the rest; the plan marks which videos were deleted, and its
+// WriteAudit records the run.
+// Requires: channel:manage:videos scope.
+func (c *Client) EnforceVideoRetention(ctx context.Context, userID string, policy *VideoRetentionPolicy, opts *VideoRetentionOptions) (*VideoRetentionPlan, error) {
+	if opts == nil {
+		opts = &VideoRetentionOptions{}
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	var videos []Video
+	params := &GetVideosParams{UserID: userID, Type: "all", Sort: "time", PaginationParams: &PaginationParams{First: 100}}
+	for {
+		resp, err := c.GetVideos(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("listing videos: %w", err)
+		}
+		videos = append(videos, resp.Data...)
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+			break
+		}
+		params.After = resp.Pagination.Cursor
+	}
+
+	plan := PlanVideoRetention(userID, policy, videos, time.Now())
+	if opts.DryRun {
+		return plan, nil
+	}
+	plan.Applied = true
+	plan.AppliedAt = time.Now().UTC()
+	for batch := range slices.Chunk(plan.Deletions, maxVideoDeleteIDs) {
+		if err := ctx.Err(); err != nil {
+			return plan, err
+		}
+		ids := make([]string, len(batch))
+		for i, d := range batch {
+			ids[i] = d.Video.ID
+		}
+		deleted, err := c.DeleteVideos(ctx, ids)
+		for i := range batch {
+			switch {
+			case err != nil:
+				batch[i].Err = err
+			case slices.Contains(deleted, batch[i].Video.ID):
+				batch[i].Deleted = true
+			default:
+				batch[i].Err = errors.New("not deleted")
+			}
+		}
+	}
+	return plan, plan.Err()
+}
+
+// PlanVideoRetention applies a retention policy to videos without calling
+// the API. Each video is deleted for the first rule it matches.
+func PlanVideoRetention(userID string, policy *VideoRetentionPolicy, videos []Video, now time.Time) *VideoRetentionPlan {
+	plan := &VideoRetentionPlan{UserID: userID}
+	seen := make(map[string]bool, len(videos))
+	for _, v := range videos {
+		if seen[v.ID] {
+			continue
+		}
+		seen[v.ID] = true
+		if slices.Contains(policy.Exclude, v.ID) {
+			plan.Kept++
+			continue
+		}
+		i := slices.IndexFunc(policy.Rules, func(r VideoRetentionRule) bool { return r.Matches(&v, now) })
+		if i < 0 {
+			plan.Kept++
+			continue
+		}
+		plan.Deletions = append(plan.Deletions, VideoDeletion{Video: v, Rule: policy.Rules[i].String()})
+	}
+	slices.SortStableFunc(plan.Deletions, func(a, b VideoDeletion) int { return a.Video.CreatedAt.Compare(b.Video.CreatedAt) })
+	return plan
+}
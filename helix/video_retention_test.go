@@ -0,0 +1,126 @@
+package helix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnforceVideoRetention(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	var videos []Video
+	for i, v := range []struct {
+		typ   string
+		age   time.Duration
+		views int
+	}{
+		{"archive", 40 * day, 500}, // v0: old archive
+		{"archive", 10 * day, 0},   // v1: recent archive
+		{"highlight", 400 * day, 5},
+		{"highlight", 400 * day, 5000}, // v3: popular highlight
+		{"upload", 100 * day, 1},
+		{"archive", 50 * day, 2}, // v5: excluded
+		{"archive", 60 * day, 3},
+		{"archive", 70 * day, 4},
+		{"archive", 80 * day, 5},
+	} {
+		videos = append(videos, Video{ID: "v" + string(rune('0'+i)), Type: v.typ, Title: "Video " + string(rune('0'+i)), CreatedAt: now.Add(-v.age), ViewCount: v.views})
+	}
+
+	var mu sync.Mutex
+	var deletes [][]string
+	client, server := newTestClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		q := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet:
+			if q.Get("user_id") != "1234" || q.Get("type") != "all" {
+				t.Errorf("videos query = %v", q)
+			}
+			resp := Response[Video]{Data: videos[:5], Pagination: &Pagination{Cursor: "page2"}}
+			if q.Get("after") == "page2" {
+				resp = Response[Video]{Data: videos[5:]}
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+		case http.MethodDelete:
+			ids := q["id"]
+			deletes = append(deletes, ids)
+			// v8 isn't reported deleted
+			_ = json.NewEncoder(w).Encode(map[string][]string{"data": slices.DeleteFunc(slices.Clone(ids), func(id string) bool { return id == "v8" })})
+		}
+	}))
+	defer server.Close()
+
+	policy, err := ParseVideoRetentionPolicy(strings.NewReader(`{
+		"rules": [
+			{"types": ["archive"], "older_than_days": 30},
+			{"name": "unpopular", "types": ["highlight", "upload"], "older_than_days": 90, "views_below": 100}
+		],
+		"exclude": ["v5"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	plan, err := client.EnforceVideoRetention(ctx, "1234", policy, &VideoRetentionOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, d := range plan.Deletions {
+		ids = append(ids, d.Video.ID)
+	}
+	if !slices.Equal(ids, []string{"v2", "v4", "v8", "v7", "v6", "v0"}) || plan.Kept != 3 || len(deletes) != 0 {
+		t.Errorf("plan = %v, kept %d, deletes %v", ids, plan.Kept, deletes)
+	}
+	if first := strings.SplitN(plan.String(), "\n", 2)[0]; first != `- delete highlight v2 "Video 2" `+videos[2].CreatedAt.Format(time.DateOnly)+` (5 views): unpopular` {
+		t.Errorf("plan line = %s", first)
+	}
+	if plan.Deletions[5].Rule != "archive, older than 30 days" {
+		t.Errorf("rule = %s", plan.Deletions[5].Rule)
+	}
+
+	plan, err = client.EnforceVideoRetention(ctx, "1234", policy, nil)
+	if err == nil || !strings.Contains(err.Error(), "v8") {
+		t.Errorf("err = %v", err)
+	}
+	if len(deletes) != 2 || len(deletes[0]) != 5 || len(deletes[1]) != 1 {
+		t.Errorf("deletes = %v", deletes)
+	}
+
+	var audit bytes.Buffer
+	if err := plan.WriteAudit(&audit); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	var entry VideoRetentionAuditEntry
+	if err := json.Unmarshal([]byte(lines[2]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 6 || entry.VideoID != "v8" || entry.Deleted || entry.Error != "not deleted" || entry.Time.IsZero() {
+		t.Errorf("audit entry = %+v", entry)
+	}
+}
+
+func TestVideoRetentionPolicy_Validate(t *testing.T) {
+	for name, body := range map[string]string{
+		"no rules":   `{"rules": []}`,
+		"bad type":   `{"rules": [{"types": ["clip"], "older_than_days": 1}]}`,
+		"type only":  `{"rules": [{"types": ["archive"]}]}`,
+		"bad field":  `{"rules": [{"older_than": 1}]}`,
+		"bad syntax": `{"rules": [`,
+	} {
+		if _, err := ParseVideoRetentionPolicy(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
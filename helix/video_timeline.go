@@ -0,0 +1,164 @@
+package helix
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// firstChapterTitle names the chapter added at 0:00 when the first marker
+// comes later; YouTube requires chapters to start at 0:00.
+const firstChapterTitle = "Start"
+
+// VideoChapter is one chapter of a video timeline.
+type VideoChapter struct {
+	Title    string
+	Start    time.Duration
+	End      time.Duration // Start of the next chapter, or the end of the video
+	MarkerID string        // Empty for the added first chapter
+}
+
+// VideoTimeline is a video's chapters, built from its stream markers.
+type VideoTimeline struct {
+	VideoID  string
+	Duration time.Duration // Zero if unknown
+	Chapters []VideoChapter
+}
+
+// NewVideoTimeline builds a video's chapters from its stream markers, in
+// position order. Markers at the same position are merged, markers without a
+// description are named "Chapter N", and a "Start" chapter is added at 0:00
+// when the first marker comes later. Markers past the end of a video of known
+// duration are dropped.
+func NewVideoTimeline(videoID string, duration time.Duration, markers []StreamMarker) *VideoTimeline {
+	sorted := slices.SortedStableFunc(slices.Values(markers), func(a, b StreamMarker) int {
+		return cmp.Compare(a.PositionSeconds, b.PositionSeconds)
+	})
+	t := &VideoTimeline{VideoID: videoID, Duration: duration}
+	for _, m := range sorted {
+		start := time.Duration(m.PositionSeconds) * time.Second
+		if start < 0 || (duration > 0 && start >= duration) {
+			continue
+		}
+		if n := len(t.Chapters); n > 0 && t.Chapters[n-1].Start == start {
+			continue
+		}
+		if len(t.Chapters) == 0 && start > 0 {
+			t.Chapters = append(t.Chapters, VideoChapter{Title: firstChapterTitle})
+		}
+		title := strings.Join(strings.Fields(m.Description), " ")
+		if title == "" {
+			title = fmt.Sprintf("Chapter %d", len(t.Chapters)+1)
+		}
+		t.Chapters = append(t.Chapters, VideoChapter{Title: title, Start: start, MarkerID: m.ID})
+	}
+	for i := range t.Chapters {
+		if i+1 < len(t.Chapters) {
+			t.Chapters[i].End = t.Chapters[i+1].Start
+		} else {
+			t.Chapters[i].End = max(duration, t.Chapters[i].Start)
+		}
+	}
+	return t
+}
+
+// VideoTimelines builds a timeline for each video in a Get Stream Markers
+// response. durations gives video lengths by ID; missing videos get a zero
+// Duration.
+func VideoTimelines(markers []VideoStreamMarkers, durations map[string]time.Duration) []*VideoTimeline {
+	var timelines []*VideoTimeline
+	for _, user := range markers {
+		for _, v := range user.Videos {
+			timelines = append(timelines, NewVideoTimeline(v.VideoID, durations[v.VideoID], v.Markers))
+		}
+	}
+	return timelines
+}
+
+// GetVideoTimeline gets a video and all of its stream markers and builds its
+// chapter timeline.
+// Requires: user:read:broadcast scope.
+func (c *Client) GetVideoTimeline(ctx context.Context, videoID string) (*VideoTimeline, error) {
+	videos, err := c.GetVideos(ctx, &GetVideosParams{IDs: []string{videoID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(videos.Data) == 0 {
+		return nil, fmt.Errorf("video %s not found", videoID)
+	}
+	duration, err := videos.Data[0].ParseDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	var markers []StreamMarker
+	params := &GetStreamMarkersParams{VideoID: videoID, PaginationParams: &PaginationParams{First: 100}}
+	for {
+		resp, err := c.GetStreamMarkers(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range resp.Data {
+			for _, v := range user.Videos {
+				if v.VideoID == videoID {
+					markers = append(markers, v.Markers...)
+				}
+			}
+		}
+		if resp.Pagination == nil || resp.Pagination.Cursor == "" || len(resp.Data) == 0 {
+			break
+		}
+		params.After = resp.Pagination.Cursor
+	}
+	return NewVideoTimeline(videoID, duration, markers), nil
+}
+
+// YouTubeChapters formats the timeline as YouTube chapter lines for a video
+// description, e.g. "0:00 Start" and "1:02:03 Boss fight". Timestamps include
+// hours when the video is an hour or longer.
+//
+// YouTube only shows chapters when there are at least three, each at least
+// ten seconds long.
+func (t *VideoTimeline) YouTubeChapters() string {
+	hours := t.Duration >= time.Hour
+	if n := len(t.Chapters); n > 0 && t.Chapters[n-1].Start >= time.Hour {
+		hours = true
+	}
+	var b strings.Builder
+	for _, c := range t.Chapters {
+		s := int(c.Start / time.Second)
+		if hours {
+			fmt.Fprintf(&b, "%d:%02d:%02d %s\n", s/3600, s/60%60, s%60, c.Title)
+		} else {
+			fmt.Fprintf(&b, "%d:%02d %s\n", s/60, s%60, c.Title)
+		}
+	}
+	return b.String()
+}
+
+// WriteWebVTT writes the timeline as a WebVTT chapters track, one cue per
+// chapter. The last chapter needs the video's duration to have an end time.
+func (t *VideoTimeline) WriteWebVTT(w io.Writer) error {
+	if n := len(t.Chapters); n > 0 && t.Chapters[n-1].End <= t.Chapters[n-1].Start {
+		return errors.New("last chapter has no end time; the video duration is unknown")
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("WEBVTT\n")
+	escape := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	for i, c := range t.Chapters {
+		fmt.Fprintf(bw, "\n%d\n%s --> %s\n%s\n", i+1, formatVTTTime(c.Start), formatVTTTime(c.End), escape.Replace(c.Title))
+	}
+	return bw.Flush()
+}
+
+// formatVTTTime formats a WebVTT timestamp, "hh:mm:ss.ttt".
+func formatVTTTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,87 @@
+package helix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVideoTimeline(t *testing.T) {
+	markers := []StreamMarker{
+		{ID: "m3", PositionSeconds: 3723, Description: "Boss fight"},
+		{ID: "m1", PositionSeconds: 90, Description: "  Just\nChatting "},
+		{ID: "m2", PositionSeconds: 754},
+		{ID: "dup", PositionSeconds: 754, Description: "Duplicate"},
+		{ID: "late", PositionSeconds: 9000, Description: "After the end"},
+	}
+	timeline := NewVideoTimeline("v1", 2*time.Hour, markers)
+
+	wantChapters := `0:00:00 Start
+0:01:30 Just Chatting
+0:12:34 Chapter 3
+1:02:03 Boss fight
+`
+	if got := timeline.YouTubeChapters(); got != wantChapters {
+		t.Errorf("chapters =\n%s\nwant\n%s", got, wantChapters)
+	}
+
+	var b strings.Builder
+	if err := timeline.WriteWebVTT(&b); err != nil {
+		t.Fatal(err)
+	}
+	wantVTT := "WEBVTT\n\n" +
+		"1\n00:00:00.000 --> 00:01:30.000\nStart\n\n" +
+		"2\n00:01:30.000 --> 00:12:34.000\nJust Chatting\n\n" +
+		"3\n00:12:34.000 --> 01:02:03.000\nChapter 3\n\n" +
+		"4\n01:02:03.000 --> 02:00:00.000\nBoss fight\n"
+	if b.String() != wantVTT {
+		t.Errorf("WebVTT =\n%s", b.String())
+	}
+
+	// Short videos use m:ss; an unknown duration can't end the last cue
+	short := NewVideoTimeline("v2", 0, []StreamMarker{{PositionSeconds: 0, Description: "Intro"}, {PositionSeconds: 65, Description: "Q&A <live>"}})
+	if got := short.YouTubeChapters(); got != "0:00 Intro\n1:05 Q&A <live>\n" {
+		t.Errorf("short chapters = %q", got)
+	}
+	if err := short.WriteWebVTT(&b); err == nil {
+		t.Error("WriteWebVTT without duration: no error")
+	}
+}
+
+func TestClient_GetVideoTimeline(t *testing.T) {
+	client, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/videos":
+			_ = json.NewEncoder(w).Encode(Response[Video]{Data: []Video{{ID: "v1", Duration: "1h0m0s"}}})
+		case "/streams/markers":
+			if r.URL.Query().Get("video_id") != "v1" {
+				t.Errorf("markers query = %v", r.URL.Query())
+			}
+			page := `{"data":[{"user_id":"1","videos":[{"video_id":"v1","markers":[{"id":"a","position_seconds":600,"description":"Second"}]}]}],"pagination":{"cursor":"next"}}`
+			switch r.URL.Query().Get("after") {
+			case "next":
+				page = `{"data":[{"user_id":"1","videos":[{"video_id":"v1","markers":[{"id":"b","position_seconds":0,"description":"First"}]}]}],"pagination":{"cursor":"last"}}`
+			case "last":
+				// An empty page with a cursor ends the loop
+				page = `{"data":[],"pagination":{"cursor":"again"}}`
+			case "again":
+				t.Error("paged past an empty page")
+			}
+			_, _ = w.Write([]byte(page))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	})
+	defer server.Close()
+
+	timeline, err := client.GetVideoTimeline(context.Background(), "v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if timeline.Duration != time.Hour || len(timeline.Chapters) != 2 || timeline.Chapters[0].MarkerID != "b" || timeline.Chapters[1].End != time.Hour {
+		t.Errorf("timeline = %+v", timeline)
+	}
+}
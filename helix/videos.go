@@ -1,8 +1,13 @@
 package helix
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -33,9 +38,120 @@ type MutedSegment struct {
 	Offset   int `json:"offset"`
 }
 
+// ParseVideoDuration parses a Twitch video duration such as "3h8m33s":
+// whole hours, minutes, and seconds, in that order, each optional.
+func ParseVideoDuration(s string) (time.Duration, error) {
+	const units = "hms"
+	scale := [...]time.Duration{time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	last := -1
+	for rest := s; rest != ""; {
+		i := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+		if i <= 0 {
+			return 0, fmt.Errorf("invalid video duration %q", s)
+		}
+		n, err := strconv.Atoi(rest[:i])
+		unit := strings.IndexByte(units, rest[i])
+		if err != nil || unit <= last {
+			return 0, fmt.Errorf("invalid video duration %q", s)
+		}
+		d += time.Duration(n) * scale[unit]
+		last = unit
+		rest = rest[i+1:]
+	}
+	if last < 0 {
+		return 0, fmt.Errorf("invalid video duration %q", s)
+	}
+	return d, nil
+}
+
+// ParseDuration parses the video's Duration.
+func (v *Video) ParseDuration() (time.Duration, error) {
+	return ParseVideoDuration(v.Duration)
+}
+
+// FormatThumbnailURL fills in the size of a thumbnail URL template. Both the
+// %{width}x%{height} form of videos and the {width}x{height} form of streams
+// and box art are replaced.
+func FormatThumbnailURL(template string, width, height int) string {
+	w, h := strconv.Itoa(width), strconv.Itoa(height)
+	return strings.NewReplacer("%{width}", w, "%{height}", h, "{width}", w, "{height}", h).Replace(template)
+}
+
+// Thumbnail returns the video's thumbnail URL at a size.
+func (v *Video) Thumbnail(width, height int) string {
+	return FormatThumbnailURL(v.ThumbnailURL, width, height)
+}
+
+// VideoInterval is a span of a video that is either playable or muted.
+type VideoInterval struct {
+	Start time.Duration
+	End   time.Duration
+	Muted bool
+}
+
+// Duration returns the interval's length.
+func (i VideoInterval) Duration() time.Duration {
+	return i.End - i.Start
+}
+
+// VideoIntervals splits a video of the given length into playable and muted
+// intervals, in order. Overlapping and adjacent muted segments are merged,
+// and segments past the end are clipped.
+func VideoIntervals(length time.Duration, muted []MutedSegment) []VideoInterval {
+	segments := slices.SortedFunc(slices.Values(muted), func(a, b MutedSegment) int { return cmp.Compare(a.Offset, b.Offset) })
+	var intervals []VideoInterval
+	pos := time.Duration(0)
+	for _, m := range segments {
+		start := min(time.Duration(m.Offset)*time.Second, length)
+		end := min(start+time.Duration(m.Duration)*time.Second, length)
+		if end <= start {
+			continue
+		}
+		if n := len(intervals); n > 0 && intervals[n-1].Muted && start <= intervals[n-1].End {
+			intervals[n-1].End = max(intervals[n-1].End, end)
+			pos = intervals[n-1].End
+			continue
+		}
+		if start > pos {
+			intervals = append(intervals, VideoInterval{Start: pos, End: start})
+		}
+		intervals = append(intervals, VideoInterval{Start: start, End: end, Muted: true})
+		pos = end
+	}
+	if pos < length {
+		intervals = append(intervals, VideoInterval{Start: pos, End: length})
+	}
+	return intervals
+}
+
+// Intervals returns the video's playable and muted intervals.
+func (v *Video) Intervals() ([]VideoInterval, error) {
+	length, err := v.ParseDuration()
+	if err != nil {
+		return nil, err
+	}
+	return VideoIntervals(length, v.MutedSegments), nil
+}
+
+// MutedDuration returns how much of the video is muted.
+func (v *Video) MutedDuration() (time.Duration, error) {
+	intervals, err := v.Intervals()
+	if err != nil {
+		return 0, err
+	}
+	var muted time.Duration
+	for _, i := range intervals {
+		if i.Muted {
+			muted += i.Duration()
+		}
+	}
+	return muted, nil
+}
+
 // GetVideosParams contains parameters for GetVideos.
 type GetVideosParams struct {
-	IDs      []string // Video IDs (max 100)
+	IDs      []string // Video IDs
 	UserID   string
 	GameID   string
 	Language string
@@ -46,11 +162,10 @@ type GetVideosParams struct {
 }
 
 // GetVideos gets videos.
+// More than 100 video IDs are split into concurrent requests, and the ones
+// that don't exist are listed in Response.NotFound.
 func (c *Client) GetVideos(ctx context.Context, params *GetVideosParams) (*Response[Video], error) {
 	q := url.Values{}
-	for _, id := range params.IDs {
-		q.Add("id", id)
-	}
 	if params.UserID != "" {
 		q.Set("user_id", params.UserID)
 	}
@@ -71,11 +186,13 @@ func (c *Client) GetVideos(ctx context.Context, params *GetVideosParams) (*Respo
 	}
 	addPaginationParams(q, params.PaginationParams)
 
-	var resp Response[Video]
-	if err := c.get(ctx, "/videos", q, &resp); err != nil {
-		return nil, err
-	}
-	return &resp, nil
+	return lookup(ctx, c, lookupRequest[Video]{
+		endpoint: "/videos",
+		query:    q,
+		params: []lookupParam[Video]{
+			{name: "id", values: params.IDs, key: func(v *Video) string { return v.ID }},
+		},
+	})
 }
 
 // DeleteVideosResponse represents the response from DeleteVideos.
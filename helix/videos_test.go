@@ -275,3 +275,69 @@ func TestClient_DeleteVideos_Error(t *testing.T) {
 		t.Fatal("expected error, got nil")
 	}
 }
+
+func TestParseVideoDuration(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"3h8m33s": 3*time.Hour + 8*time.Minute + 33*time.Second,
+		"45s":     45 * time.Second,
+		"1h":      time.Hour,
+	} {
+		if got, err := ParseVideoDuration(in); err != nil || got != want {
+			t.Errorf("ParseVideoDuration(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "1.5h", "-3s", "3 hours", "500ms", "10us", "10µs", "5ns", "3s2m", "1h1h", "12"} {
+		if _, err := ParseVideoDuration(in); err == nil {
+			t.Errorf("ParseVideoDuration(%q): no error", in)
+		}
+	}
+}
+
+func TestFormatThumbnailURL(t *testing.T) {
+	v := &Video{ThumbnailURL: "https://static-cdn.jtvnw.net/cf_vods/d1m7jfoe9zdc1j/thumb/thumb0-%{width}x%{height}.jpg"}
+	if got := v.Thumbnail(320, 180); got != "https://static-cdn.jtvnw.net/cf_vods/d1m7jfoe9zdc1j/thumb/thumb0-320x180.jpg" {
+		t.Errorf("video thumbnail = %s", got)
+	}
+	s := &Stream{ThumbnailURL: "https://static-cdn.jtvnw.net/previews-ttv/live_user_twitchdev-{width}x{height}.jpg"}
+	if got := s.Thumbnail(1920, 1080); got != "https://static-cdn.jtvnw.net/previews-ttv/live_user_twitchdev-1920x1080.jpg" {
+		t.Errorf("stream thumbnail = %s", got)
+	}
+}
+
+func TestVideo_Intervals(t *testing.T) {
+	v := &Video{
+		Duration: "10m",
+		MutedSegments: []MutedSegment{
+			{Offset: 300, Duration: 60},
+			{Offset: 60, Duration: 30},
+			{Offset: 330, Duration: 60}, // Overlaps the previous segment
+			{Offset: 570, Duration: 60}, // Runs past the end
+		},
+	}
+	intervals, err := v.Intervals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VideoInterval{
+		{Start: 0, End: time.Minute},
+		{Start: time.Minute, End: 90 * time.Second, Muted: true},
+		{Start: 90 * time.Second, End: 5 * time.Minute},
+		{Start: 5 * time.Minute, End: 390 * time.Second, Muted: true},
+		{Start: 390 * time.Second, End: 570 * time.Second},
+		{Start: 570 * time.Second, End: 10 * time.Minute, Muted: true},
+	}
+	if len(intervals) != len(want) {
+		t.Fatalf("intervals = %+v", intervals)
+	}
+	for i := range want {
+		if intervals[i] != want[i] {
+			t.Errorf("interval %d = %+v, want %+v", i, intervals[i], want[i])
+		}
+	}
+	if muted, _ := v.MutedDuration(); muted != 150*time.Second {
+		t.Errorf("MutedDuration() = %v", muted)
+	}
+	if got := VideoIntervals(time.Minute, nil); len(got) != 1 || got[0].Muted || got[0].Duration() != time.Minute {
+		t.Errorf("unmuted intervals = %+v", got)
+	}
+}